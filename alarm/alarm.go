@@ -0,0 +1,300 @@
+// Package alarm raises alarms when the SG or the temperature of the
+// current brew strays from a healthy fermentation.
+package alarm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+)
+
+// The rules raising alarms.
+const (
+	// LagRule is raised when the SG hasn't started to fall long after
+	// pitching.
+	LagRule = "lag"
+	// StallRule is raised when the SG stops falling well above the FG.
+	StallRule = "stall"
+	// FastRule is raised when the SG falls too fast.
+	FastRule = "fast"
+	// ExothermRule is raised when the fermenter runs hot above its target.
+	ExothermRule = "exotherm"
+)
+
+const (
+	// evaluatePeriod is how often the rules are evaluated.
+	evaluatePeriod = time.Minute * 10
+	// bucketSeconds is the span of the SG averages the rules look at.
+	bucketSeconds = 3600
+	// stallDrop is how little the SG falls over the stall window, in points.
+	stallDrop = 1.
+	// fastWindow is the span the fall rate of the SG is measured over.
+	fastWindow = time.Hour * 6
+	// exothermTime is how long the fermenter has to run hot to raise the
+	// exotherm alarm, so the controller gets the chance to catch up.
+	exothermTime = time.Minute * 30
+	// expectedAttenuation gives the FG the stall rule compares to until the
+	// fermentation curve predicts it.
+	expectedAttenuation = 0.75
+)
+
+// Rules configures the alarms. SG changes are in gravity points (0.001 SG),
+// a zero Lag, Stall, FastRate or Exotherm disables its rule.
+type Rules struct {
+	// Lag is how long after pitching the SG has to have fallen by LagDrop.
+	Lag     time.Duration
+	LagDrop float64
+	// Stall is how long the SG may stay put while it's more than
+	// StallMargin above the FG.
+	Stall       time.Duration
+	StallMargin float64
+	// FastRate is the fastest the SG may fall, in points a day.
+	FastRate float64
+	// Exotherm is how far in ºC the fermenter may run above its target.
+	Exotherm float64
+}
+
+// DefaultRules suit an ale.
+var DefaultRules = Rules{
+	Lag:         time.Hour * 24,
+	LagDrop:     2,
+	Stall:       time.Hour * 24,
+	StallMargin: 4,
+	FastRate:    20,
+	Exotherm:    2,
+}
+
+type Watcher struct {
+	hub   *hub.Hub
+	rules Rules
+
+	brew    *config.Configuration
+	buckets []bucket
+	// fg is the FG predicted for the brew, NaN until there is one.
+	fg float64
+	// hotSince is when the fermenter went above the exotherm margin, zero
+	// while it's below.
+	hotSince time.Time
+	hot      float64
+}
+
+type bucket struct {
+	sum float64
+	n   int
+}
+
+// sample is the average SG over an hour, t hours after the brew started.
+type sample struct {
+	t  float64
+	sg float64
+}
+
+func New(h *hub.Hub, rules Rules) *Watcher {
+	return &Watcher{hub: h, rules: rules, fg: math.NaN()}
+}
+
+// Run evaluates the rules against the SG history of the current brew and
+// the recorded data points every evaluatePeriod until ctx is done.
+func (w *Watcher) Run(ctx context.Context) {
+	configCh := w.hub.Configuration.JoinContext(ctx)
+	dpCh := w.hub.DataPoints.JoinContext(ctx)
+	fermentationCh := w.hub.Fermentation.JoinContext(ctx)
+	t := time.NewTicker(evaluatePeriod)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case x := <-configCh:
+			w.configure(x)
+		case x := <-dpCh:
+			w.add(x)
+		case x := <-fermentationCh:
+			if w.brew != nil && x.Id == w.brew.Id {
+				w.fg = x.FG
+			}
+		case now := <-t.C:
+			w.evaluate(now)
+		}
+	}
+}
+
+// configure follows the brew, loading the SG history of a brew that is
+// brewing when it's first seen.
+func (w *Watcher) configure(brew *config.Configuration) {
+	fresh := w.brew == nil || w.brew.Id != brew.Id || w.brew.Stage != brew.Stage
+	w.brew = brew
+	if !fresh {
+		return
+	}
+	w.buckets, w.fg, w.hotSince = nil, math.NaN(), time.Time{}
+	if brew.Stage != config.BREWING {
+		return
+	}
+	history, err := w.hub.SessionDataPoints(brew.Id)
+	if err != nil {
+		log.Printf("Can't load the SG history of brew %d: %v\n", brew.Id, err)
+		return
+	}
+	for _, dp := range history {
+		w.addSG(dp)
+	}
+}
+
+func (w *Watcher) add(dp *hub.DataPoint) {
+	if w.brew == nil || dp.Id != w.brew.Id || w.brew.Stage != config.BREWING {
+		return
+	}
+	w.addSG(dp)
+
+	if math.IsNaN(dp.CurrentTemp) || conv.DsToC(int16(dp.CurrentTemp))-dp.TargetTemp <= w.rules.Exotherm {
+		w.hotSince = time.Time{}
+		return
+	}
+	if w.hotSince.IsZero() {
+		w.hotSince = w.brew.BrewingStartTime.Add(time.Duration(dp.Step) * time.Second)
+	}
+	w.hot = conv.DsToC(int16(dp.CurrentTemp)) - dp.TargetTemp
+}
+
+func (w *Watcher) addSG(dp *hub.DataPoint) {
+	if math.IsNaN(dp.SG) {
+		return
+	}
+	i := dp.Step / bucketSeconds
+	for len(w.buckets) <= i {
+		w.buckets = append(w.buckets, bucket{})
+	}
+	w.buckets[i].sum += dp.SG
+	w.buckets[i].n++
+}
+
+func (w *Watcher) samples() []sample {
+	var samples []sample
+	for i, b := range w.buckets {
+		if b.n > 0 {
+			samples = append(samples, sample{float64(i) + 0.5, b.sum / float64(b.n)})
+		}
+	}
+	return samples
+}
+
+// evaluate raises the alarms whose rules are broken and clears the others.
+func (w *Watcher) evaluate(now time.Time) {
+	if w.brew == nil || w.brew.Stage != config.BREWING {
+		for _, rule := range []string{LagRule, StallRule, FastRule, ExothermRule} {
+			w.hub.Clear(rule)
+		}
+		return
+	}
+	samples := w.samples()
+	w.apply(LagRule, w.lag(now, samples))
+	w.apply(StallRule, w.stall(samples))
+	w.apply(FastRule, w.fast(samples))
+	w.apply(ExothermRule, w.exotherm(now))
+}
+
+func (w *Watcher) apply(rule, message string) {
+	if message == "" {
+		w.hub.Clear(rule)
+	} else {
+		w.hub.Raise(rule, message)
+	}
+}
+
+// og is the OG of the brew, or the highest SG without one.
+func (w *Watcher) og(samples []sample) float64 {
+	if w.brew.OG > 1 {
+		return w.brew.OG
+	}
+	og := math.NaN()
+	for _, s := range samples {
+		if math.IsNaN(og) || s.sg > og {
+			og = s.sg
+		}
+	}
+	return og
+}
+
+func (w *Watcher) lag(now time.Time, samples []sample) string {
+	pitch := w.brew.PitchTime
+	if pitch.IsZero() {
+		pitch = w.brew.BrewingStartTime
+	}
+	if w.rules.Lag <= 0 || now.Sub(pitch) < w.rules.Lag || len(samples) == 0 {
+		return ""
+	}
+	drop := (w.og(samples) - samples[len(samples)-1].sg) * 1000
+	if drop >= w.rules.LagDrop {
+		return ""
+	}
+	return fmt.Sprintf("SG fell by %.1f points in %v after pitching", math.Max(drop, 0), w.rules.Lag)
+}
+
+func (w *Watcher) stall(samples []sample) string {
+	if w.rules.Stall <= 0 || len(samples) == 0 {
+		return ""
+	}
+	last := samples[len(samples)-1]
+	window := w.rules.Stall.Hours()
+	if samples[0].t > last.t-window {
+		return ""
+	}
+	og := w.og(samples)
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, s := range samples {
+		if s.t >= last.t-window {
+			low, high = math.Min(low, s.sg), math.Max(high, s.sg)
+		}
+	}
+	// a flat SG before it started falling is the lag rule's business
+	if (high-low)*1000 >= stallDrop || (og-high)*1000 < w.rules.LagDrop {
+		return ""
+	}
+	fg := w.fg
+	if math.IsNaN(fg) {
+		fg = og - (og-1)*expectedAttenuation
+	}
+	above := (last.sg - fg) * 1000
+	if above <= w.rules.StallMargin {
+		return ""
+	}
+	return fmt.Sprintf("SG stuck at %.3f for %v, %.0f points above the FG of %.3f", last.sg, w.rules.Stall, above, fg)
+}
+
+func (w *Watcher) fast(samples []sample) string {
+	if w.rules.FastRate <= 0 || len(samples) == 0 {
+		return ""
+	}
+	last := samples[len(samples)-1]
+	window := fastWindow.Hours()
+	var first *sample
+	for i := range samples {
+		if samples[i].t >= last.t-window {
+			first = &samples[i]
+			break
+		}
+	}
+	if first == nil || last.t-first.t < window/2 {
+		return ""
+	}
+	rate := (first.sg - last.sg) * 1000 / (last.t - first.t) * 24
+	if rate <= w.rules.FastRate {
+		return ""
+	}
+	return fmt.Sprintf("SG falling by %.0f points a day", rate)
+}
+
+func (w *Watcher) exotherm(now time.Time) string {
+	if w.rules.Exotherm <= 0 || w.hotSince.IsZero() || now.Sub(w.hotSince) < exothermTime {
+		return ""
+	}
+	return fmt.Sprintf("Fermenter %.1fºC above its target for %v", w.hot, now.Sub(w.hotSince).Round(time.Minute))
+}
@@ -0,0 +1,139 @@
+package alarm
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/hub"
+)
+
+func brewing(t *testing.T) (*hub.Hub, *config.Configuration) {
+	h, err := hub.Open(filepath.Join(t.TempDir(), "alcobot.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Close() })
+	assert.NoError(t, h.Plan("Ale", 1.050, nil))
+	assert.NoError(t, h.Transition(hub.Prepare))
+	assert.NoError(t, h.Transition(hub.Pitch))
+	conf, err := h.SessionConfig(1)
+	assert.NoError(t, err)
+	return h, conf
+}
+
+// record adds a point a minute from hour from to hour to, with the SG of
+// sg at the hour.
+func record(w *Watcher, from, to int, sg func(hours float64) float64) {
+	for step := from * 3600; step < to*3600; step += 60 {
+		w.add(&hub.DataPoint{Id: w.brew.Id, Step: step, TargetTemp: 19, CurrentTemp: 19 * 16, SG: sg(float64(step) / 3600)})
+	}
+}
+
+func flat(sg float64) func(float64) float64 {
+	return func(float64) float64 { return sg }
+}
+
+func raised(h *hub.Hub) []string {
+	var rules []string
+	for _, a := range h.Alarms() {
+		rules = append(rules, a.Rule)
+	}
+	return rules
+}
+
+func TestLag(t *testing.T) {
+	h, conf := brewing(t)
+	w := New(h, DefaultRules)
+	w.configure(conf)
+
+	record(w, 0, 23, flat(1.050))
+	w.evaluate(conf.PitchTime.Add(23 * time.Hour))
+	assert.Empty(t, raised(h))
+	record(w, 23, 25, flat(1.0495))
+	w.evaluate(conf.PitchTime.Add(25 * time.Hour))
+	assert.Equal(t, []string{LagRule}, raised(h))
+
+	record(w, 25, 27, flat(1.047))
+	w.evaluate(conf.PitchTime.Add(27 * time.Hour))
+	assert.Empty(t, raised(h))
+}
+
+func TestStall(t *testing.T) {
+	h, conf := brewing(t)
+	w := New(h, DefaultRules)
+	w.configure(conf)
+
+	// fermentation starts falling and gets stuck at 1.030, well above
+	// the 1.0125 expected from the OG
+	sg := func(hours float64) float64 { return math.Max(1.050-hours*0.001, 1.030) }
+	record(w, 0, 30, sg)
+	w.evaluate(conf.PitchTime.Add(30 * time.Hour))
+	assert.Empty(t, raised(h))
+	record(w, 30, 50, sg)
+	w.evaluate(conf.PitchTime.Add(50 * time.Hour))
+	assert.Equal(t, []string{StallRule}, raised(h))
+
+	// a predicted FG close to the SG means fermentation is done instead
+	w.fg = 1.028
+	w.evaluate(conf.PitchTime.Add(50 * time.Hour))
+	assert.Empty(t, raised(h))
+}
+
+func TestFast(t *testing.T) {
+	h, conf := brewing(t)
+	w := New(h, DefaultRules)
+	w.configure(conf)
+
+	record(w, 0, 8, func(hours float64) float64 { return 1.050 - hours*0.0005 })
+	w.evaluate(conf.PitchTime.Add(8 * time.Hour))
+	assert.Empty(t, raised(h))
+	record(w, 8, 16, func(hours float64) float64 { return 1.046 - (hours-8)*0.0015 })
+	w.evaluate(conf.PitchTime.Add(16 * time.Hour))
+	assert.Equal(t, []string{FastRule}, raised(h))
+}
+
+func TestExotherm(t *testing.T) {
+	h, conf := brewing(t)
+	w := New(h, DefaultRules)
+	w.configure(conf)
+
+	at := func(minute int, temp float64) {
+		w.add(&hub.DataPoint{Id: conf.Id, Step: minute * 60, TargetTemp: 19, CurrentTemp: temp * 16, SG: math.NaN()})
+	}
+	for m := 0; m < 40; m++ {
+		at(m, 21.5)
+	}
+	w.evaluate(conf.BrewingStartTime.Add(20 * time.Minute))
+	assert.Empty(t, raised(h))
+	w.evaluate(conf.BrewingStartTime.Add(40 * time.Minute))
+	assert.Equal(t, []string{ExothermRule}, raised(h))
+
+	at(41, 20)
+	w.evaluate(conf.BrewingStartTime.Add(41 * time.Minute))
+	assert.Empty(t, raised(h))
+}
+
+func TestHistoryAndStage(t *testing.T) {
+	h, conf := brewing(t)
+	var points []*hub.DataPoint
+	for step := 0; step < 26*3600; step += 60 {
+		points = append(points, &hub.DataPoint{Id: conf.Id, Step: step, SG: 1.050, Span: 1})
+	}
+	assert.NoError(t, h.SaveDataPoints(points))
+
+	w := New(h, DefaultRules)
+	w.configure(conf)
+	w.evaluate(conf.PitchTime.Add(26 * time.Hour))
+	assert.Equal(t, []string{LagRule}, raised(h))
+
+	assert.NoError(t, h.Transition(hub.Finish))
+	conf, err := h.SessionConfig(conf.Id)
+	assert.NoError(t, err)
+	w.configure(conf)
+	w.evaluate(conf.PitchTime.Add(27 * time.Hour))
+	assert.Empty(t, raised(h))
+}
@@ -1,6 +1,7 @@
 package backlight
 
 import (
+	"context"
 	"log"
 	"math"
 	"os"
@@ -16,57 +17,58 @@ type Backlight struct {
 	on       bool
 	offTimer bool
 	conf     *config.Configuration
+	hub      *hub.Hub
 }
 
 func New(h *hub.Hub) *Backlight {
-	backlight := &Backlight{time.NewTimer(time.Hour), 0, false, false, nil}
+	backlight := &Backlight{time.NewTimer(time.Hour), 0, false, false, nil, h}
+	backlight.On()
+	return backlight
+}
 
-	go func() {
-		adcValueCh := hub.JoinInt16Group(h.AdsValueSensor)
-		configCh := hub.JoinConfigGroup(h.Configuration)
+// Run switches the backlight on presence until ctx is done.
+func (b *Backlight) Run(ctx context.Context) {
+	adcValueCh := b.hub.AdsValueSensor.JoinContext(ctx)
+	configCh := b.hub.Configuration.JoinContext(ctx)
 
-		for {
-			select {
-			case x := <-configCh:
-				backlight.conf = x
-			case x := <-adcValueCh:
-				if backlight.conf == nil {
+	for {
+		select {
+		case x := <-configCh:
+			b.conf = x
+		case x := <-adcValueCh:
+			if b.conf == nil {
+				continue
+			}
+			if math.Abs(float64(x.Value-b.conf.PresenceZero)) < b.conf.PresenceCalibration {
+				b.count = 0
+				if !b.on {
 					continue
 				}
-				if math.Abs(float64(x-backlight.conf.PresenceZero)) < backlight.conf.PresenceCalibration {
-					backlight.count = 0
-					if !backlight.on {
-						continue
-					}
-					if !backlight.offTimer {
-						backlight.offTimer = true
-						backlight.timer.Reset(backlight.conf.PresenceTimeout)
+				if !b.offTimer {
+					b.offTimer = true
+					b.timer.Reset(b.conf.PresenceTimeout)
+				}
+			} else {
+				if !b.on {
+					b.count++
+					if b.count > b.conf.PresenceOnTimer {
+						b.On()
 					}
 				} else {
-					if !backlight.on {
-						backlight.count++
-						if backlight.count > backlight.conf.PresenceOnTimer {
-							backlight.On()
-						}
-					} else {
-						backlight.offTimer = false
-						backlight.timer.Stop()
-					}
-				}
-			case <-h.Quit:
-				return
-			case <-backlight.timer.C:
-				if backlight.conf == nil {
-					continue
+					b.offTimer = false
+					b.timer.Stop()
 				}
-				backlight.Off()
-
 			}
-		}
-	}()
+		case <-ctx.Done():
+			return
+		case <-b.timer.C:
+			if b.conf == nil {
+				continue
+			}
+			b.Off()
 
-	backlight.On()
-	return backlight
+		}
+	}
 }
 
 func (b *Backlight) On() {
@@ -0,0 +1,137 @@
+// Package backup keeps rotating backups of the database.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zlowred/alcobot/hub"
+)
+
+const (
+	// DefaultInterval is the time between two scheduled backups.
+	DefaultInterval = time.Hour * 24
+	// DefaultKeep is how many scheduled backups are kept.
+	DefaultKeep = 7
+
+	// prefix and timeFormat name the backups, e.g.
+	// alcobot-20260301-120000.db, so they sort by time.
+	prefix     = "alcobot-"
+	timeFormat = "20060102-150405"
+	suffix     = ".db"
+)
+
+// Config configures the scheduled backups: every Interval a backup is
+// written to Dir, keeping the Keep newest.
+type Config struct {
+	Dir      string
+	Interval time.Duration
+	Keep     int
+}
+
+type Scheduler struct {
+	hub  *hub.Hub
+	conf Config
+}
+
+func New(h *hub.Hub, conf Config) *Scheduler {
+	if conf.Interval <= 0 {
+		conf.Interval = DefaultInterval
+	}
+	if conf.Keep < 1 {
+		conf.Keep = DefaultKeep
+	}
+	return &Scheduler{h, conf}
+}
+
+// Run writes a backup every Interval until ctx is done. The first one is
+// due Interval after the newest backup in Dir, so restarts don't postpone
+// it.
+func (s *Scheduler) Run(ctx context.Context) {
+	if err := os.MkdirAll(s.conf.Dir, 0755); err != nil {
+		log.Printf("Can't create backup directory %v: %v\n", s.conf.Dir, err)
+	}
+	next := time.Now()
+	if files, err := List(s.conf.Dir); err != nil {
+		log.Printf("Can't list backups: %v\n", err)
+	} else if len(files) > 0 {
+		next = files[len(files)-1].Time.Add(s.conf.Interval)
+	}
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-timer.C:
+			if _, err := s.Backup(now); err != nil {
+				log.Printf("Scheduled backup failed: %v\n", err)
+			}
+			timer.Reset(s.conf.Interval)
+		}
+	}
+}
+
+// Backup writes a backup taken at now to Dir and deletes all but the Keep
+// newest. It returns the file of the backup.
+func (s *Scheduler) Backup(now time.Time) (string, error) {
+	file := filepath.Join(s.conf.Dir, prefix+now.Format(timeFormat)+suffix)
+	if err := s.hub.Backup(file); err != nil {
+		return "", err
+	}
+	return file, rotate(s.conf.Dir, s.conf.Keep)
+}
+
+// File is a backup in the backup directory.
+type File struct {
+	Path string
+	Time time.Time
+}
+
+// List lists the backups in dir, oldest first. A missing dir has none.
+func List(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var files []File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		t, err := time.ParseInLocation(timeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix), time.Local)
+		if err != nil {
+			continue
+		}
+		files = append(files, File{filepath.Join(dir, name), t})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Time.Before(files[j].Time)
+	})
+	return files, nil
+}
+
+func rotate(dir string, keep int) error {
+	files, err := List(dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > keep {
+		if err := os.Remove(files[0].Path); err != nil {
+			return fmt.Errorf("can't delete old backup: %w", err)
+		}
+		log.Printf("Deleted old backup %v\n", files[0].Path)
+		files = files[1:]
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/hub"
+)
+
+func TestBackupRotates(t *testing.T) {
+	dir := t.TempDir()
+	h, err := hub.Open(filepath.Join(dir, "alcobot.db"))
+	assert.NoError(t, err)
+	defer h.Close()
+	backups := filepath.Join(dir, "backups")
+	assert.NoError(t, os.Mkdir(backups, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(backups, "notes.txt"), nil, 0644))
+
+	s := New(h, Config{Dir: backups, Keep: 2})
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	for i := 0; i < 3; i++ {
+		file, err := s.Backup(start.Add(time.Duration(i) * DefaultInterval))
+		assert.NoError(t, err)
+		_, err = hub.ValidateBackup(file)
+		assert.NoError(t, err)
+	}
+
+	files, err := List(backups)
+	assert.NoError(t, err)
+	assert.Equal(t, []File{
+		{filepath.Join(backups, "alcobot-20260302-120000.db"), start.Add(DefaultInterval)},
+		{filepath.Join(backups, "alcobot-20260303-120000.db"), start.Add(2 * DefaultInterval)},
+	}, files)
+	assert.FileExists(t, filepath.Join(backups, "notes.txt"))
+}
+
+func TestListMissingDir(t *testing.T) {
+	files, err := List(filepath.Join(t.TempDir(), "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
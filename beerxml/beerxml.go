@@ -0,0 +1,202 @@
+// Package beerxml reads recipes from BeerXML 1.0 files.
+package beerxml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zlowred/alcobot/hub"
+)
+
+// Yeast is a yeast of a recipe with its fermentation temperature range
+// (ºC) and attenuation (%).
+type Yeast struct {
+	Name           string
+	Laboratory     string
+	ProductId      string
+	MinTemperature float64
+	MaxTemperature float64
+	Attenuation    float64
+}
+
+// Recipe is what the bot uses of a BeerXML recipe.
+type Recipe struct {
+	Name   string
+	OG     float64
+	FG     float64
+	Yeasts []Yeast
+	Steps  []hub.ScheduleStep
+}
+
+type xmlYeast struct {
+	Name           string `xml:"NAME"`
+	Laboratory     string `xml:"LABORATORY"`
+	ProductId      string `xml:"PRODUCT_ID"`
+	MinTemperature string `xml:"MIN_TEMPERATURE"`
+	MaxTemperature string `xml:"MAX_TEMPERATURE"`
+	Attenuation    string `xml:"ATTENUATION"`
+}
+
+type xmlRecipe struct {
+	Name               string     `xml:"NAME"`
+	OG                 string     `xml:"OG"`
+	FG                 string     `xml:"FG"`
+	Yeasts             []xmlYeast `xml:"YEASTS>YEAST"`
+	FermentationStages string     `xml:"FERMENTATION_STAGES"`
+	PrimaryAge         string     `xml:"PRIMARY_AGE"`
+	PrimaryTemp        string     `xml:"PRIMARY_TEMP"`
+	SecondaryAge       string     `xml:"SECONDARY_AGE"`
+	SecondaryTemp      string     `xml:"SECONDARY_TEMP"`
+	TertiaryAge        string     `xml:"TERTIARY_AGE"`
+	TertiaryTemp       string     `xml:"TERTIARY_TEMP"`
+	Age                string     `xml:"AGE"`
+	AgeTemp            string     `xml:"AGE_TEMP"`
+}
+
+type xmlRecipes struct {
+	Recipes []xmlRecipe `xml:"RECIPE"`
+}
+
+// Parse reads the recipes of a BeerXML document.
+func Parse(r io.Reader) ([]Recipe, error) {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = charsetReader
+	var doc xmlRecipes
+	if err := d.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid BeerXML: %w", err)
+	}
+	if len(doc.Recipes) == 0 {
+		return nil, fmt.Errorf("no recipe in BeerXML")
+	}
+
+	var recipes []Recipe
+	for _, x := range doc.Recipes {
+		r, err := x.recipe()
+		if err != nil {
+			return nil, fmt.Errorf("recipe %q: %w", x.Name, err)
+		}
+		recipes = append(recipes, r)
+	}
+	return recipes, nil
+}
+
+// Find returns the recipe with name, or the first recipe if name is empty.
+func Find(recipes []Recipe, name string) (Recipe, error) {
+	for _, r := range recipes {
+		if name == "" || strings.EqualFold(r.Name, name) {
+			return r, nil
+		}
+	}
+	return Recipe{}, fmt.Errorf("no recipe %q", name)
+}
+
+// Plan sets up the brew in setup from r, see hub.Plan.
+func (r Recipe) Plan(h *hub.Hub) error {
+	return h.Plan(r.Name, r.OG, r.Steps)
+}
+
+func (x xmlRecipe) recipe() (Recipe, error) {
+	var err error
+	r := Recipe{Name: strings.TrimSpace(x.Name)}
+	if r.OG, err = number(x.OG); err != nil {
+		return r, err
+	}
+	if r.FG, err = number(x.FG); err != nil {
+		return r, err
+	}
+	for _, y := range x.Yeasts {
+		yeast := Yeast{Name: strings.TrimSpace(y.Name), Laboratory: strings.TrimSpace(y.Laboratory), ProductId: strings.TrimSpace(y.ProductId)}
+		if yeast.MinTemperature, err = number(y.MinTemperature); err != nil {
+			return r, err
+		}
+		if yeast.MaxTemperature, err = number(y.MaxTemperature); err != nil {
+			return r, err
+		}
+		if yeast.Attenuation, err = number(y.Attenuation); err != nil {
+			return r, err
+		}
+		r.Yeasts = append(r.Yeasts, yeast)
+	}
+
+	stages := []struct{ name, age, temp string }{
+		{"Primary", x.PrimaryAge, x.PrimaryTemp},
+		{"Secondary", x.SecondaryAge, x.SecondaryTemp},
+		{"Tertiary", x.TertiaryAge, x.TertiaryTemp},
+	}
+	count := len(stages)
+	if strings.TrimSpace(x.FermentationStages) != "" {
+		if count, err = strconv.Atoi(strings.TrimSpace(x.FermentationStages)); err != nil {
+			return r, fmt.Errorf("invalid FERMENTATION_STAGES %q", x.FermentationStages)
+		}
+		if count < 0 || count > len(stages) {
+			return r, fmt.Errorf("invalid FERMENTATION_STAGES %q", x.FermentationStages)
+		}
+	}
+	for _, s := range stages[:count] {
+		if err := r.step(s.name, s.age, s.temp); err != nil {
+			return r, err
+		}
+	}
+	if err := r.step("Conditioning", x.Age, x.AgeTemp); err != nil {
+		return r, err
+	}
+
+	// without fermentation steps, ferment in the middle of the yeast range
+	if len(r.Steps) == 0 && len(r.Yeasts) > 0 && r.Yeasts[0].MaxTemperature > 0 {
+		y := r.Yeasts[0]
+		r.Steps = append(r.Steps, hub.ScheduleStep{Name: "Primary", Temperature: (y.MinTemperature + y.MaxTemperature) / 2})
+	}
+	return r, nil
+}
+
+// step adds a fermentation step of age days at temp ºC; steps without a
+// temperature are skipped.
+func (r *Recipe) step(name, age, temp string) error {
+	if strings.TrimSpace(temp) == "" {
+		return nil
+	}
+	t, err := number(temp)
+	if err != nil {
+		return err
+	}
+	days, err := number(age)
+	if err != nil {
+		return err
+	}
+	r.Steps = append(r.Steps, hub.ScheduleStep{Name: name, Temperature: t, Duration: time.Duration(days * float64(24*time.Hour))})
+	return nil
+}
+
+// number parses a BeerXML number; empty ones are 0.
+func number(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return x, nil
+}
+
+// charsetReader decodes the ISO-8859-1 files some brewing tools write.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	}
+	return nil, fmt.Errorf("unsupported charset %v", charset)
+}
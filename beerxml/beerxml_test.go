@@ -0,0 +1,72 @@
+package beerxml
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/hub"
+)
+
+const day = 24 * time.Hour
+
+func parseFile(t *testing.T, name string) []Recipe {
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	recipes, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return recipes
+}
+
+func TestParse(t *testing.T) {
+	recipes := parseFile(t, "testdata/recipes.xml")
+	assert.Len(t, recipes, 2)
+
+	helles := recipes[0]
+	assert.Equal(t, "Munich Helles", helles.Name)
+	assert.Equal(t, 1.048, helles.OG)
+	assert.Equal(t, 1.010, helles.FG)
+	assert.Equal(t, []Yeast{{"Munich Lager", "Wyeast Labs", "2308", 8.9, 13.3, 75}}, helles.Yeasts)
+	// only two of the three fermentation stages are used
+	assert.Equal(t, []hub.ScheduleStep{
+		{Name: "Primary", Temperature: 10, Duration: 10 * day},
+		{Name: "Secondary", Temperature: 16, Duration: 60 * time.Hour},
+		{Name: "Conditioning", Temperature: 1, Duration: 28 * day},
+	}, helles.Steps)
+
+	marzen := recipes[1]
+	assert.Equal(t, "Märzen", marzen.Name)
+	assert.Equal(t, 0., marzen.FG)
+	assert.Equal(t, []hub.ScheduleStep{{Name: "Primary", Temperature: 10}}, marzen.Steps)
+}
+
+func TestFind(t *testing.T) {
+	recipes := parseFile(t, "testdata/recipes.xml")
+	r, err := Find(recipes, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Munich Helles", r.Name)
+	r, err = Find(recipes, "märzen")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.056, r.OG)
+	_, err = Find(recipes, "Stout")
+	assert.Error(t, err)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, doc := range []string{
+		"",
+		"<RECIPES></RECIPES>",
+		"<RECIPES><RECIPE><NAME>x</NAME><OG>heavy</OG></RECIPE></RECIPES>",
+		"<RECIPES><RECIPE><NAME>x</NAME><PRIMARY_TEMP>18</PRIMARY_TEMP><PRIMARY_AGE>a week</PRIMARY_AGE></RECIPE></RECIPES>",
+	} {
+		_, err := Parse(strings.NewReader(doc))
+		assert.Error(t, err, doc)
+	}
+}
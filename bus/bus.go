@@ -0,0 +1,462 @@
+// Package bus is a typed publish/subscribe layer with named topics.
+//
+// Every topic has one dispatcher goroutine, so Send returns as soon as the
+// dispatcher has taken the message, the same hand-off bcast groups give.
+// The dispatcher then delivers to each subscriber according to the
+// subscriber's buffer size and drop policy.
+package bus
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Policy int
+
+const (
+	// Block makes the dispatcher wait until the subscriber has room, which
+	// holds up delivery to every other subscriber of the topic.
+	Block Policy = iota
+	// DropNewest discards the incoming message when the buffer is full.
+	DropNewest
+	// DropOldest discards the oldest buffered message to make room.
+	DropOldest
+)
+
+func (p Policy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropNewest:
+		return "drop-newest"
+	case DropOldest:
+		return "drop-oldest"
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+func (p Policy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+type options struct {
+	buffer int
+	policy Policy
+	name   string
+}
+
+type Option func(*options)
+
+// Buffer sets how many messages may queue up for the subscriber.
+func Buffer(size int) Option {
+	return func(o *options) {
+		o.buffer = size
+	}
+}
+
+// Drop sets what happens when the subscriber's buffer is full.
+func Drop(policy Policy) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+// Name labels the subscriber in statistics.
+func Name(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// AnyTopic is the untyped view of a topic kept in the bus registry.
+type AnyTopic interface {
+	Name() string
+	Type() string
+	Subscribers() int
+	Stats() TopicStats
+	Close()
+}
+
+type Bus struct {
+	mutex  sync.Mutex
+	topics map[string]AnyTopic
+}
+
+func New() *Bus {
+	return &Bus{topics: make(map[string]AnyTopic)}
+}
+
+// Register creates a topic and adds it to the bus. Registering a name twice
+// returns the existing topic as long as the message type matches; the
+// defaults of the first registration stay.
+func Register[T any](b *Bus, name string, defaults ...Option) (*Topic[T], error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if t, ok := b.topics[name]; ok {
+		if c, ok := t.(*Topic[T]); ok {
+			return c, nil
+		}
+		return nil, fmt.Errorf("bus: topic %q carries %v, not %v", name, t.Type(), typeName[T]())
+	}
+	c := NewTopic[T](name, defaults...)
+	b.topics[name] = c
+	return c, nil
+}
+
+// MustRegister is Register for topics declared at start-up.
+func MustRegister[T any](b *Bus, name string, defaults ...Option) *Topic[T] {
+	c, err := Register[T](b, name, defaults...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Lookup finds a registered topic by name and checks its message type.
+func Lookup[T any](b *Bus, name string) (*Topic[T], error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	t, ok := b.topics[name]
+	if !ok {
+		return nil, fmt.Errorf("bus: no topic %q", name)
+	}
+	c, ok := t.(*Topic[T])
+	if !ok {
+		return nil, fmt.Errorf("bus: topic %q carries %v, not %v", name, t.Type(), typeName[T]())
+	}
+	return c, nil
+}
+
+// Subscribe looks a topic up by name and subscribes to it.
+func Subscribe[T any](b *Bus, name string, opts ...Option) (*Subscription[T], error) {
+	c, err := Lookup[T](b, name)
+	if err != nil {
+		return nil, err
+	}
+	return c.subscribe(append([]Option{Name(caller(2))}, opts...)), nil
+}
+
+// Topics returns the registered topics sorted by name.
+func (b *Bus) Topics() []AnyTopic {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	res := make([]AnyTopic, 0, len(b.topics))
+	for _, t := range b.topics {
+		res = append(res, t)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name() < res[j].Name()
+	})
+	return res
+}
+
+// Close closes every registered topic, which closes all subscriptions.
+func (b *Bus) Close() {
+	for _, t := range b.Topics() {
+		t.Close()
+	}
+}
+
+// Topic carries messages of type T.
+type Topic[T any] struct {
+	name string
+	in   chan T
+	quit chan struct{}
+	once sync.Once
+	// defaults apply to every subscriber before its own options.
+	defaults []Option
+
+	mutex sync.Mutex
+	subs  []*Subscription[T]
+
+	sent        uint64
+	rate        meter
+	sendLatency latency
+}
+
+// NewTopic creates a topic that is not registered on any bus. The defaults
+// apply to every subscriber unless its own options override them.
+func NewTopic[T any](name string, defaults ...Option) *Topic[T] {
+	c := &Topic[T]{name: name, in: make(chan T), quit: make(chan struct{}), defaults: defaults}
+	go c.dispatch()
+	return c
+}
+
+func (c *Topic[T]) Name() string {
+	return c.name
+}
+
+func (c *Topic[T]) Type() string {
+	return typeName[T]()
+}
+
+func (c *Topic[T]) Subscribers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.subs)
+}
+
+// Send publishes v to all current subscribers. Messages sent after Close are
+// discarded.
+func (c *Topic[T]) Send(v T) {
+	start := time.Now()
+	select {
+	case c.in <- v:
+		c.sendLatency.add(time.Since(start))
+		c.rate.mark(start)
+		atomic.AddUint64(&c.sent, 1)
+	case <-c.quit:
+	}
+}
+
+// Subscribe adds a subscriber. Without options or topic defaults the
+// subscriber is unbuffered and blocking, like a bcast group member.
+func (c *Topic[T]) Subscribe(opts ...Option) *Subscription[T] {
+	return c.subscribe(opts)
+}
+
+// Join subscribes for the lifetime of the program and returns the channel.
+func (c *Topic[T]) Join(opts ...Option) <-chan T {
+	return c.subscribe(opts).C
+}
+
+// JoinContext subscribes until ctx is done. The channel is left open when
+// the subscription ends, so a loop that also selects on ctx.Done never reads
+// a zero value from it.
+func (c *Topic[T]) JoinContext(ctx context.Context, opts ...Option) <-chan T {
+	s := c.subscribe(opts)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.detach()
+		case <-s.done:
+		}
+	}()
+	return s.C
+}
+
+func (c *Topic[T]) subscribe(opts []Option) *Subscription[T] {
+	o := options{}
+	for _, opt := range c.defaults {
+		opt(&o)
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.name == "" {
+		o.name = caller(3)
+	}
+	s := &Subscription[T]{topic: c, name: o.name, ch: make(chan T, o.buffer), done: make(chan struct{}), policy: o.policy}
+	s.C = s.ch
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	select {
+	case <-c.quit:
+		s.close()
+	default:
+		c.subs = append(c.subs, s)
+	}
+	return s
+}
+
+func (c *Topic[T]) Stats() TopicStats {
+	res := TopicStats{Name: c.name, Type: c.Type(), Sent: atomic.LoadUint64(&c.sent), Rate: c.rate.rate(time.Now()), SendLatency: c.sendLatency.get()}
+	for _, s := range c.snapshot() {
+		res.Subscribers = append(res.Subscribers, s.Stats())
+	}
+	return res
+}
+
+// Close stops the dispatcher and closes every subscription.
+func (c *Topic[T]) Close() {
+	c.once.Do(func() {
+		close(c.quit)
+	})
+}
+
+func (c *Topic[T]) snapshot() []*Subscription[T] {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]*Subscription[T](nil), c.subs...)
+}
+
+func (c *Topic[T]) remove(s *Subscription[T]) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i, x := range c.subs {
+		if x == s {
+			c.subs = append(c.subs[:i], c.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *Topic[T]) dispatch() {
+	for {
+		select {
+		case v := <-c.in:
+			for _, s := range c.snapshot() {
+				s.deliver(v, c.quit)
+			}
+		case <-c.quit:
+			c.mutex.Lock()
+			subs := c.subs
+			c.subs = nil
+			c.mutex.Unlock()
+			for _, s := range subs {
+				s.close()
+			}
+			return
+		}
+	}
+}
+
+type Subscription[T any] struct {
+	// C receives the messages. It is closed by Close or when the topic closes.
+	C <-chan T
+
+	topic   *Topic[T]
+	name    string
+	ch      chan T
+	policy  Policy
+	done    chan struct{}
+	once    sync.Once
+	mutex   sync.Mutex
+	closed  bool
+	dropped uint64
+
+	delivered    uint64
+	delivery     latency
+	blockedSince int64
+}
+
+func (s *Subscription[T]) deliver(v T, quit <-chan struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	start := time.Now()
+	if s.send(v, quit) {
+		atomic.AddUint64(&s.delivered, 1)
+	}
+	s.delivery.add(time.Since(start))
+}
+
+func (s *Subscription[T]) send(v T, quit <-chan struct{}) bool {
+	switch s.policy {
+	case DropNewest:
+		select {
+		case s.ch <- v:
+			return true
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+			return false
+		}
+	case DropOldest:
+		if cap(s.ch) == 0 {
+			atomic.AddUint64(&s.dropped, 1)
+			return false
+		}
+		for {
+			select {
+			case s.ch <- v:
+				return true
+			default:
+			}
+			select {
+			case <-s.ch:
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+		}
+	default:
+		select {
+		case s.ch <- v:
+			return true
+		default:
+		}
+		atomic.StoreInt64(&s.blockedSince, time.Now().UnixNano())
+		defer atomic.StoreInt64(&s.blockedSince, 0)
+		select {
+		case s.ch <- v:
+			return true
+		case <-s.done:
+		case <-quit:
+		}
+		return false
+	}
+}
+
+// Close unsubscribes and closes C. It does not wait for a message the
+// dispatcher is blocked on delivering.
+func (s *Subscription[T]) Close() {
+	s.close()
+	s.topic.remove(s)
+}
+
+func (s *Subscription[T]) close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.closed = true
+		close(s.ch)
+	})
+}
+
+// detach ends the subscription without closing the channel.
+func (s *Subscription[T]) detach() {
+	s.once.Do(func() {
+		close(s.done)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.closed = true
+	})
+	s.topic.remove(s)
+}
+
+// Dropped returns how many messages the drop policy has discarded.
+func (s *Subscription[T]) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Pending returns how many messages are queued for the subscriber.
+func (s *Subscription[T]) Pending() int {
+	return len(s.ch)
+}
+
+func (s *Subscription[T]) Policy() Policy {
+	return s.policy
+}
+
+func (s *Subscription[T]) Stats() SubscriberStats {
+	res := SubscriberStats{Name: s.name, Policy: s.policy, Buffer: cap(s.ch), Pending: s.Pending(),
+		Delivered: atomic.LoadUint64(&s.delivered), Dropped: s.Dropped(), Delivery: s.delivery.get()}
+	if since := atomic.LoadInt64(&s.blockedSince); since != 0 {
+		res.Blocking = time.Since(time.Unix(0, since))
+	}
+	return res
+}
+
+// caller names the file and line skip frames up, keeping the last directory
+// so "gui/brewingcontroller.go:89" stays readable.
+func caller(skip int) string {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%v:%d", filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file)), line)
+}
+
+func typeName[T any]() string {
+	var v T
+	return fmt.Sprintf("%T", &v)[1:]
+}
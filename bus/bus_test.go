@@ -0,0 +1,257 @@
+package bus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recv(t *testing.T, ch <-chan int) int {
+	select {
+	case x := <-ch:
+		return x
+	case <-time.After(time.Second):
+		t.Fatal("no value received")
+		return 0
+	}
+}
+
+func TestRegisterAndLookup(t *testing.T) {
+	b := New()
+	defer b.Close()
+	a := MustRegister[int](b, "a")
+	again, err := Register[int](b, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, a, again)
+	found, err := Lookup[int](b, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, a, found)
+	assert.Equal(t, "int", found.Type())
+}
+
+func TestRegisterTypeMismatch(t *testing.T) {
+	b := New()
+	defer b.Close()
+	MustRegister[int](b, "a")
+	_, err := Register[string](b, "a")
+	assert.EqualError(t, err, `bus: topic "a" carries int, not string`)
+	_, err = Lookup[float64](b, "a")
+	assert.Error(t, err)
+	_, err = Lookup[int](b, "b")
+	assert.EqualError(t, err, `bus: no topic "b"`)
+}
+
+func TestTopicsSorted(t *testing.T) {
+	b := New()
+	defer b.Close()
+	MustRegister[int](b, "b")
+	MustRegister[string](b, "a")
+	topics := b.Topics()
+	assert.Equal(t, 2, len(topics))
+	assert.Equal(t, "a", topics[0].Name())
+	assert.Equal(t, "b", topics[1].Name())
+}
+
+func TestBroadcast(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	topic := MustRegister[int](bus, "t")
+	a := topic.Join()
+	b, err := Subscribe[int](bus, "t")
+	assert.NoError(t, err)
+	go topic.Send(1)
+	assert.Equal(t, 1, recv(t, a))
+	assert.Equal(t, 1, recv(t, b.C))
+	assert.Equal(t, 2, topic.Subscribers())
+}
+
+func TestSendWithoutSubscribers(t *testing.T) {
+	topic := NewTopic[int]("t")
+	defer topic.Close()
+	topic.Send(1)
+	ch := topic.Join()
+	go topic.Send(2)
+	assert.Equal(t, 2, recv(t, ch))
+}
+
+func TestBlockingSubscriberHoldsOthers(t *testing.T) {
+	topic := NewTopic[int]("t")
+	defer topic.Close()
+	slow := topic.Subscribe()
+	fast := topic.Subscribe()
+	topic.Send(1)
+	go topic.Send(2)
+	select {
+	case <-fast.C:
+		t.Fatal("fast subscriber served while slow one blocks")
+	case <-time.After(time.Millisecond * 20):
+	}
+	slow.Close()
+	assert.Equal(t, 1, recv(t, fast.C))
+	assert.Equal(t, 2, recv(t, fast.C))
+}
+
+func TestDropNewest(t *testing.T) {
+	topic := NewTopic[int]("t")
+	defer topic.Close()
+	s := topic.Subscribe(Buffer(2), Drop(DropNewest))
+	marker := topic.Subscribe(Buffer(10))
+	for i := 1; i <= 5; i++ {
+		topic.Send(i)
+	}
+	for i := 1; i <= 5; i++ {
+		recv(t, marker.C)
+	}
+	assert.Equal(t, 2, s.Pending())
+	assert.Equal(t, uint64(3), s.Dropped())
+	assert.Equal(t, 1, recv(t, s.C))
+	assert.Equal(t, 2, recv(t, s.C))
+}
+
+func TestDropOldest(t *testing.T) {
+	topic := NewTopic[int]("t")
+	defer topic.Close()
+	s := topic.Subscribe(Buffer(2), Drop(DropOldest))
+	marker := topic.Subscribe(Buffer(10))
+	for i := 1; i <= 5; i++ {
+		topic.Send(i)
+	}
+	for i := 1; i <= 5; i++ {
+		recv(t, marker.C)
+	}
+	assert.Equal(t, uint64(3), s.Dropped())
+	assert.Equal(t, 4, recv(t, s.C))
+	assert.Equal(t, 5, recv(t, s.C))
+}
+
+func TestDropOldestUnbuffered(t *testing.T) {
+	topic := NewTopic[int]("t")
+	defer topic.Close()
+	s := topic.Subscribe(Drop(DropOldest))
+	marker := topic.Subscribe()
+	topic.Send(1)
+	recv(t, marker.C)
+	assert.Equal(t, uint64(1), s.Dropped())
+}
+
+func TestUnsubscribe(t *testing.T) {
+	topic := NewTopic[int]("t")
+	defer topic.Close()
+	s := topic.Subscribe()
+	assert.Equal(t, 1, topic.Subscribers())
+	s.Close()
+	s.Close()
+	assert.Equal(t, 0, topic.Subscribers())
+	_, ok := <-s.C
+	assert.False(t, ok)
+	topic.Send(1)
+}
+
+func TestCloseTopic(t *testing.T) {
+	b := New()
+	topic := MustRegister[int](b, "t")
+	s := topic.Subscribe()
+	b.Close()
+	_, ok := <-s.C
+	assert.False(t, ok)
+	topic.Send(1)
+	_, ok = <-topic.Subscribe().C
+	assert.False(t, ok)
+}
+
+func TestStats(t *testing.T) {
+	b := New()
+	defer b.Close()
+	topic := MustRegister[int](b, "t")
+	named := topic.Subscribe(Name("named"), Buffer(5), Drop(DropNewest))
+	anon := topic.Subscribe(Buffer(5))
+	for i := 0; i < 3; i++ {
+		topic.Send(i)
+	}
+	for i := 0; i < 3; i++ {
+		recv(t, anon.C)
+	}
+	stats := b.Snapshot()
+	assert.Equal(t, 1, len(stats))
+	assert.Equal(t, "t", stats[0].Name)
+	assert.Equal(t, "int", stats[0].Type)
+	assert.Equal(t, uint64(3), stats[0].Sent)
+	assert.Equal(t, uint64(3), stats[0].SendLatency.Count)
+	assert.Equal(t, 2, len(stats[0].Subscribers))
+	s := stats[0].Subscribers[0]
+	assert.Equal(t, "named", s.Name)
+	assert.Equal(t, DropNewest, s.Policy)
+	assert.Equal(t, 5, s.Buffer)
+	assert.Equal(t, 3, s.Pending)
+	assert.Equal(t, uint64(3), s.Delivered)
+	assert.Equal(t, uint64(3), s.Delivery.Count)
+	assert.Contains(t, stats[0].Subscribers[1].Name, "bus/bus_test.go:")
+	assert.Equal(t, 3, named.Pending())
+}
+
+func TestStatsBlocking(t *testing.T) {
+	topic := NewTopic[int]("t")
+	defer topic.Close()
+	s := topic.Subscribe()
+	topic.Send(1)
+	time.Sleep(time.Millisecond * 20)
+	stats := topic.Stats()
+	assert.True(t, stats.Subscribers[0].Blocking >= time.Millisecond*20)
+	recv(t, s.C)
+	time.Sleep(time.Millisecond * 5)
+	assert.Equal(t, time.Duration(0), topic.Stats().Subscribers[0].Blocking)
+	assert.True(t, topic.Stats().Subscribers[0].Delivery.Max >= time.Millisecond*20)
+}
+
+func TestMeter(t *testing.T) {
+	m := &meter{}
+	start := time.Unix(1000, 0)
+	for i := 0; i < 50; i++ {
+		m.mark(start.Add(time.Millisecond * 100 * time.Duration(i)))
+	}
+	assert.Equal(t, 0.0, m.rate(start))
+	assert.Equal(t, 5.0, m.rate(start.Add(time.Second*5)))
+	assert.Equal(t, 4.0, m.rate(start.Add(time.Second*11)))
+	assert.Equal(t, 0.0, m.rate(start.Add(time.Second*20)))
+}
+
+func TestJoinContext(t *testing.T) {
+	topic := NewTopic[int]("t")
+	defer topic.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := topic.JoinContext(ctx)
+	go topic.Send(1)
+	assert.Equal(t, 1, recv(t, ch))
+	topic.Send(2)
+	cancel()
+	for topic.Subscribers() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	topic.Send(3)
+	select {
+	case x := <-ch:
+		t.Fatalf("received %v after the context was done", x)
+	case <-time.After(time.Millisecond * 20):
+	}
+}
+
+func TestTopicDefaults(t *testing.T) {
+	topic := NewTopic[int]("t", Buffer(1), Drop(DropOldest))
+	defer topic.Close()
+	s := topic.Subscribe()
+	blocking := topic.Subscribe(Buffer(0), Drop(Block))
+	assert.Equal(t, DropOldest, s.Policy())
+	assert.Equal(t, Block, blocking.Policy())
+	blocking.Close()
+	marker := topic.Subscribe(Buffer(10))
+	for i := 1; i <= 3; i++ {
+		topic.Send(i)
+	}
+	for i := 1; i <= 3; i++ {
+		recv(t, marker.C)
+	}
+	assert.Equal(t, uint64(2), s.Dropped())
+	assert.Equal(t, 3, recv(t, s.C))
+}
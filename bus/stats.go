@@ -0,0 +1,114 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+// RateWindow is the number of whole seconds message rates are averaged over.
+const RateWindow = 10
+
+type TopicStats struct {
+	Name string
+	Type string
+	Sent uint64
+	// Rate is messages per second over the last RateWindow seconds.
+	Rate float64
+	// SendLatency is how long Send waited for the dispatcher to take a
+	// message. It grows when a subscriber is slow to drain its channel.
+	SendLatency Latency
+	Subscribers []SubscriberStats
+}
+
+type SubscriberStats struct {
+	// Name is the subscriber's Name option or the file and line it
+	// subscribed from.
+	Name      string
+	Policy    Policy
+	Buffer    int
+	Pending   int
+	Delivered uint64
+	Dropped   uint64
+	// Delivery is how long the dispatcher spent handing messages to this
+	// subscriber.
+	Delivery Latency
+	// Blocking is how long the message currently being delivered has been
+	// waiting, zero when the dispatcher is not stuck on this subscriber.
+	Blocking time.Duration
+}
+
+type Latency struct {
+	Count uint64
+	Avg   time.Duration
+	Max   time.Duration
+}
+
+type latency struct {
+	mutex sync.Mutex
+	count uint64
+	total time.Duration
+	max   time.Duration
+}
+
+func (l *latency) add(d time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.count++
+	l.total += d
+	if d > l.max {
+		l.max = d
+	}
+}
+
+func (l *latency) get() Latency {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	res := Latency{Count: l.count, Max: l.max}
+	if l.count > 0 {
+		res.Avg = l.total / time.Duration(l.count)
+	}
+	return res
+}
+
+// meter counts events in one-second buckets.
+type meter struct {
+	mutex   sync.Mutex
+	seconds [RateWindow]int64
+	counts  [RateWindow]uint64
+}
+
+func (m *meter) mark(now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s := now.Unix()
+	i := s % RateWindow
+	if m.seconds[i] != s {
+		m.seconds[i] = s
+		m.counts[i] = 0
+	}
+	m.counts[i]++
+}
+
+// rate averages the last RateWindow complete seconds before now.
+func (m *meter) rate(now time.Time) float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s := now.Unix()
+	var total uint64
+	for i := range m.seconds {
+		if m.seconds[i] < s && m.seconds[i] >= s-RateWindow {
+			total += m.counts[i]
+		}
+	}
+	return float64(total) / RateWindow
+}
+
+// Snapshot returns the statistics of every registered topic, sorted by name.
+func (b *Bus) Snapshot() []TopicStats {
+	topics := b.Topics()
+	res := make([]TopicStats, 0, len(topics))
+	for _, t := range topics {
+		res = append(res, t.Stats())
+	}
+	return res
+}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/zlowred/alcobot/beerxml"
+	exporter "github.com/zlowred/alcobot/export"
+	"github.com/zlowred/alcobot/gravity"
+	"github.com/zlowred/alcobot/hub"
+	"github.com/zlowred/alcobot/plant"
+)
+
+// Maintain runs a maintenance command, i.e. any command but Run, writing
+// its output to w.
+func Maintain(o *Options, w io.Writer) error {
+	switch o.Command {
+	case Migrate:
+		return migrate(o, w)
+	case Backup:
+		return backup(o, w)
+	case Restore:
+		return restore(o, w)
+	case Export:
+		return export(o, w)
+	case Import:
+		return importRecipe(o, w)
+	case Calibrate:
+		return calibrate(o, w)
+	case Identify:
+		return identify(o, w)
+	}
+	return fmt.Errorf("%v is not a maintenance command", o.Command)
+}
+
+func migrate(o *Options, w io.Writer) error {
+	// opening the hub applies the pending migrations
+	h, err := hub.Open(o.DBFile())
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+	version, err := h.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%v is at schema version %d\n", o.DBFile(), version)
+	return nil
+}
+
+func backup(o *Options, w io.Writer) error {
+	file := o.Args[0]
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("%v already exists", file)
+	}
+	h, err := hub.Open(o.DBFile())
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+	if err := h.Backup(file); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Backed up %v to %v\n", o.DBFile(), file)
+	return nil
+}
+
+func restore(o *Options, w io.Writer) error {
+	version, err := hub.Restore(o.Args[0], o.DBFile())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Restored %v from %v at schema version %d\n", o.DBFile(), o.Args[0], version)
+	return nil
+}
+
+func export(o *Options, w io.Writer) error {
+	fs := flag.NewFlagSet(Export, flag.ContinueOnError)
+	format := fs.String("format", exporter.CSV, "csv or json")
+	if err := fs.Parse(o.Args); err != nil {
+		return err
+	}
+	var id int
+	if fs.NArg() > 0 {
+		var err error
+		if id, err = strconv.Atoi(fs.Arg(0)); err != nil {
+			return fmt.Errorf("invalid brew id %q", fs.Arg(0))
+		}
+	}
+
+	h, err := hub.Open(o.DBFile())
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+	b, err := exporter.Load(h, id)
+	if err != nil {
+		return err
+	}
+	return b.Write(w, *format)
+}
+
+func importRecipe(o *Options, w io.Writer) error {
+	f, err := os.Open(o.Args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	recipes, err := beerxml.Parse(f)
+	if err != nil {
+		return err
+	}
+	name := ""
+	if len(o.Args) > 1 {
+		name = o.Args[1]
+	}
+	r, err := beerxml.Find(recipes, name)
+	if err != nil {
+		return err
+	}
+
+	h, err := hub.Open(o.DBFile())
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+	if err := r.Plan(h); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Planned %v: OG %.3f, %d schedule steps\n", r.Name, r.OG, len(r.Steps))
+	for _, s := range r.Steps {
+		fmt.Fprintf(w, "  %v: %.1fºC for %v\n", s.Name, s.Temperature, s.Duration)
+	}
+	return nil
+}
+
+func calibrate(o *Options, w io.Writer) error {
+	fs := flag.NewFlagSet(Calibrate, flag.ContinueOnError)
+	degree := fs.Int("fit", 0, "degree of the model to fit, 1 or 2; 0 only shows the calibration")
+	history := fs.Bool("history", false, "correct the recorded SG to the fitted model")
+	if err := fs.Parse(o.Args); err != nil {
+		return err
+	}
+	var id int
+	if fs.NArg() > 0 {
+		var err error
+		if id, err = strconv.Atoi(fs.Arg(0)); err != nil {
+			return fmt.Errorf("invalid brew id %q", fs.Arg(0))
+		}
+	}
+
+	h, err := hub.Open(o.DBFile())
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+	if *degree != 0 {
+		if id == 0 {
+			conf, err := h.Config()
+			if err != nil {
+				return err
+			}
+			id = conf.Id
+		}
+		if _, err := gravity.Refit(h, id, *degree, *history); err != nil {
+			return err
+		}
+	}
+	c, err := gravity.LoadCalibration(h, id)
+	if err != nil {
+		return err
+	}
+
+	if c.Fitted {
+		fmt.Fprintf(w, "Brew %d: SG = %.6g + %.6g·p + %.6g·p²\n", c.Id, c.Model[0], c.Model[1], c.Model[2])
+	} else {
+		fmt.Fprintf(w, "Brew %d: single point calibration\n", c.Id)
+	}
+	for _, p := range c.Points {
+		temp := "---"
+		if p.Temperature != nil {
+			temp = fmt.Sprintf("%.1fºC", *p.Temperature)
+		}
+		fmt.Fprintf(w, "  %v %-10v SG %.4f at %.1f Pa, %v: %+.1f points\n", p.Time.Local().Format("2006-01-02 15:04"), p.Source, p.SG, p.Pressure, temp, p.Residual)
+	}
+	return nil
+}
+
+func identify(o *Options, w io.Writer) error {
+	fs := flag.NewFlagSet(Identify, flag.ContinueOnError)
+	maxDeadTime := fs.Duration("max-dead-time", plant.DefaultMaxDeadTime, "longest dead time tried")
+	if err := fs.Parse(o.Args); err != nil {
+		return err
+	}
+	var id int
+	if fs.NArg() > 0 {
+		var err error
+		if id, err = strconv.Atoi(fs.Arg(0)); err != nil {
+			return fmt.Errorf("invalid brew id %q", fs.Arg(0))
+		}
+	}
+
+	h, err := hub.Open(o.DBFile())
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+	if id == 0 {
+		conf, err := h.Config()
+		if err != nil {
+			return err
+		}
+		id = conf.Id
+	}
+	dps, err := h.SessionDataPoints(id)
+	if err != nil {
+		return err
+	}
+	fit, err := plant.Identify(dps, *maxDeadTime)
+	if err != nil {
+		return fmt.Errorf("can't identify brew %d: %w", id, err)
+	}
+
+	fmt.Fprintf(w, "Brew %d: %d minutes fitted to ±%.3fºC\n", id, fit.Samples, fit.RMSE)
+	fmt.Fprintf(w, "  gain %.4gºC/%%, time constant %v, dead time %v, ambient %.1fºC\n", fit.Gain, fit.TimeConstant.Round(time.Second), fit.DeadTime, fit.Ambient)
+	for _, t := range fit.Tune() {
+		fmt.Fprintf(w, "  %-16v kP %.4g, kI %.4g, kD %.4g\n", t.Rule+":", t.KP, t.KI, t.KD)
+	}
+	fmt.Fprintf(w, "Simulate with -sim-gain %.4g -sim-time-constant %v -sim-dead-time %v -sim-ambient %.1f\n", fit.Gain, fit.TimeConstant.Round(time.Second), fit.DeadTime, fit.Ambient)
+	return nil
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/hub"
+)
+
+func TestMaintenanceCommands(t *testing.T) {
+	dir := t.TempDir()
+	o := &Options{DataDir: dir, DB: "alcobot.db"}
+	var out bytes.Buffer
+
+	o.Command = Migrate
+	assert.NoError(t, Maintain(o, &out))
+	assert.Contains(t, out.String(), "schema version")
+
+	file := filepath.Join(dir, "copy.db")
+	o.Command, o.Args = Backup, []string{file}
+	assert.NoError(t, Maintain(o, &out))
+	h, err := hub.Open(file)
+	assert.NoError(t, err)
+	h.Close()
+	assert.Error(t, Maintain(o, &out), "an existing file is not overwritten")
+
+	out.Reset()
+	o.Command, o.Args = Restore, []string{file}
+	assert.NoError(t, Maintain(o, &out))
+	assert.Contains(t, out.String(), "Restored")
+	assert.FileExists(t, o.DBFile()+".replaced")
+	o.Command, o.Args = Restore, []string{filepath.Join(dir, "missing.db")}
+	assert.Error(t, Maintain(o, &out))
+
+	out.Reset()
+	o.Command, o.Args = Export, []string{"-format", "json", "1"}
+	assert.NoError(t, Maintain(o, &out))
+	assert.True(t, strings.HasPrefix(out.String(), "{"))
+
+	o.Command, o.Args = Export, []string{"first"}
+	assert.Error(t, Maintain(o, &out))
+	o.Command, o.Args = Export, []string{"-format", "xls"}
+	assert.Error(t, Maintain(o, &out))
+
+	o.Command, o.Args = Identify, nil
+	assert.Error(t, Maintain(o, &out), "nothing recorded")
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	o := &Options{DataDir: dir, DB: "alcobot.db", Command: Import, Args: []string{"../beerxml/testdata/recipes.xml", "Märzen"}}
+	var out bytes.Buffer
+	assert.NoError(t, Maintain(o, &out))
+	assert.Contains(t, out.String(), "Planned Märzen: OG 1.056, 1 schedule steps")
+
+	h, err := hub.Open(o.DBFile())
+	assert.NoError(t, err)
+	defer h.Close()
+	sessions, err := h.Sessions()
+	assert.NoError(t, err)
+	assert.Equal(t, "Märzen", sessions[0].Name)
+	assert.Equal(t, 1.056, sessions[0].OG)
+	steps, err := h.Schedule(sessions[0].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 10., steps[0].Temperature)
+
+	o.Args = []string{"../beerxml/testdata/recipes.xml", "Stout"}
+	assert.Error(t, Maintain(o, &out))
+}
@@ -0,0 +1,289 @@
+// Package cli parses the command line of the bot and runs its maintenance
+// commands.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zlowred/alcobot/alarm"
+	backups "github.com/zlowred/alcobot/backup"
+	"github.com/zlowred/alcobot/completion"
+	"github.com/zlowred/alcobot/debuglog"
+	"github.com/zlowred/alcobot/flightrecorder"
+	"github.com/zlowred/alcobot/plant"
+	"github.com/zlowred/alcobot/uploader"
+)
+
+// Commands.
+const (
+	// Run starts the bot; it is the default command.
+	Run = "run"
+	// Sim starts the bot on emulated hardware.
+	Sim = "sim"
+	// Migrate brings the database schema up to date.
+	Migrate = "migrate"
+	// Backup writes a copy of the database to the given file.
+	Backup = "backup"
+	// Restore replaces the database with a backup.
+	Restore = "restore"
+	// Export writes the configuration and data points of a brew as CSV or
+	// JSON.
+	Export = "export"
+	// Import plans the brew in setup from a BeerXML recipe.
+	Import = "import"
+	// Calibrate shows the SG calibration of a brew and refits it.
+	Calibrate = "calibrate"
+	// Identify fits a thermal model of the fermenter to a brew and suggests
+	// PID gains for it.
+	Identify = "identify"
+)
+
+// Hardware abstraction layers.
+const (
+	RealHal = "real"
+	SimHal  = "sim"
+)
+
+// Log levels.
+const (
+	LogDebug = "debug"
+	LogInfo  = "info"
+	LogOff   = "off"
+)
+
+// envPrefix prefixes the environment variable of every flag, e.g.
+// ALCOBOT_DATA_DIR for -data-dir.
+const envPrefix = "ALCOBOT_"
+
+var commands = map[string]string{
+	Run:       "start the bot",
+	Sim:       "start the bot on emulated hardware",
+	Migrate:   "bring the database schema up to date",
+	Backup:    "backup <file>: write a copy of the database to file",
+	Restore:   "restore <file>: replace the database with a backup, keeping the replaced one; stop the bot first",
+	Export:    "export [-format csv|json] [brew id]: write the data of a brew, the current one by default",
+	Import:    "import <file> [recipe]: plan the brew in setup from a BeerXML recipe, the first one by default; stop the bot first",
+	Calibrate: "calibrate [-fit 1|2] [-history] [brew id]: show the SG calibration points of a brew, the current one by default, and fit a model to them, correcting the recorded SG with -history; stop the bot first",
+	Identify:  "identify [-max-dead-time duration] [brew id]: fit a thermal model of the fermenter to the temperature and power of a brew, the current one by default, and suggest PID gains",
+}
+
+// Options is the parsed command line.
+type Options struct {
+	DataDir string
+	DB      string
+	// Listen is the address of the HTTP API. Anyone who can reach it can
+	// control the bot, so it should stay on a trusted network or localhost;
+	// requests that change anything need the X-Alcobot header.
+	Listen   string
+	Hal      string
+	LogLevel string
+	Headless bool
+
+	// FlushInterval is how often the recorded data points are written.
+	FlushInterval time.Duration
+
+	// UploadURL enables the telemetry uploader, posting to a Brewfather
+	// custom stream unless UploadTemplate names a body template file.
+	UploadURL      string
+	UploadName     string
+	UploadTemplate string
+	UploadInterval time.Duration
+
+	// BackupDir keeps a backup every BackupInterval, the BackupKeep newest
+	// ones; an interval of 0 disables them.
+	BackupDir      string
+	BackupInterval time.Duration
+	BackupKeep     int
+
+	// Completion is when fermentation counts as complete and what is done
+	// then.
+	Completion completion.Config
+
+	// Alarms are the rules raising alarms on a fermentation that is slow,
+	// stalled or running away.
+	Alarms alarm.Rules
+
+	// Plant is the fermenter simulated on emulated hardware.
+	Plant plant.Model
+
+	Command string
+	Args    []string
+}
+
+// Parse parses the flags and the command in args. A flag that isn't given
+// is taken from its environment variable, read with getenv, and then from
+// its default.
+func Parse(args []string, getenv func(string) string, output io.Writer) (*Options, error) {
+	o := &Options{}
+	fs := flag.NewFlagSet("alcobot", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintf(output, "Usage: alcobot [flags] [command]\n\nCommands:\n")
+		for _, c := range []string{Run, Sim, Migrate, Backup, Restore, Export, Import, Calibrate, Identify} {
+			fmt.Fprintf(output, "  %-8v %v\n", c, commands[c])
+		}
+		fmt.Fprintf(output, "\nFlags (or %v<FLAG> environment variables):\n", envPrefix)
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&o.DataDir, "data-dir", ".", "directory of the database and its backups")
+	fs.StringVar(&o.DB, "db", "alcobot.db", "database file, relative to the data directory")
+	fs.StringVar(&o.Listen, "listen", ":8080", "HTTP listen address, empty to disable; requests that change the bot need the X-Alcobot header")
+	fs.StringVar(&o.Hal, "hal", RealHal, "hardware: real or sim")
+	fs.StringVar(&o.LogLevel, "log-level", LogInfo, "logging: debug adds routine messages, info or off")
+	fs.BoolVar(&o.Headless, "headless", false, "run without the touch screen interface")
+	fs.DurationVar(&o.FlushInterval, "flush-interval", flightrecorder.DefaultFlushInterval, "how often the recorded data points are written to the database")
+	fs.StringVar(&o.UploadURL, "upload-url", "", "URL to post readings to, e.g. a Brewfather custom stream; empty to disable")
+	fs.StringVar(&o.UploadName, "upload-name", "alcobot", "device name of the uploaded readings")
+	fs.StringVar(&o.UploadTemplate, "upload-template", "", "file of a Go template for the post body instead of the Brewfather format")
+	fs.DurationVar(&o.UploadInterval, "upload-interval", uploader.DefaultInterval, "interval of the uploaded readings")
+	fs.StringVar(&o.BackupDir, "backup-dir", "backups", "directory of the scheduled backups, relative to the data directory")
+	fs.DurationVar(&o.BackupInterval, "backup-interval", backups.DefaultInterval, "interval of the scheduled backups, 0 to disable")
+	fs.IntVar(&o.BackupKeep, "backup-keep", backups.DefaultKeep, "number of scheduled backups kept")
+	fs.Float64Var(&o.Completion.Tolerance, "completion-tolerance", completion.DefaultTolerance, "SG change in points (0.001) within which fermentation is complete")
+	fs.DurationVar(&o.Completion.Window, "completion-window", completion.DefaultWindow, "how long the SG has to stay within the completion tolerance")
+	fs.StringVar(&o.Completion.Action, "completion-action", completion.None, "on completion: none, finish the brew or next-step of the schedule")
+	fs.DurationVar(&o.Alarms.Lag, "alarm-lag", alarm.DefaultRules.Lag, "alarm when the SG hasn't fallen by alarm-lag-drop this long after pitching, 0 to disable")
+	fs.Float64Var(&o.Alarms.LagDrop, "alarm-lag-drop", alarm.DefaultRules.LagDrop, "SG fall in points (0.001) that ends the lag phase")
+	fs.DurationVar(&o.Alarms.Stall, "alarm-stall", alarm.DefaultRules.Stall, "alarm when the SG stays put this long above the FG, 0 to disable")
+	fs.Float64Var(&o.Alarms.StallMargin, "alarm-stall-margin", alarm.DefaultRules.StallMargin, "points above the FG a stalled SG has to be")
+	fs.Float64Var(&o.Alarms.FastRate, "alarm-fast-rate", alarm.DefaultRules.FastRate, "alarm when the SG falls faster in points a day, 0 to disable")
+	fs.Float64Var(&o.Alarms.Exotherm, "alarm-exotherm", alarm.DefaultRules.Exotherm, "alarm when the fermenter runs this many ºC above its target, 0 to disable")
+	fs.Float64Var(&o.Plant.Gain, "sim-gain", plant.DefaultModel.Gain, "simulated fermenter: ºC it settles above ambient per % of power")
+	fs.DurationVar(&o.Plant.TimeConstant, "sim-time-constant", plant.DefaultModel.TimeConstant, "simulated fermenter: time constant of its temperature")
+	fs.DurationVar(&o.Plant.DeadTime, "sim-dead-time", plant.DefaultModel.DeadTime, "simulated fermenter: delay of its temperature after the power")
+	fs.Float64Var(&o.Plant.Ambient, "sim-ambient", plant.DefaultModel.Ambient, "simulated fermenter: ºC of the room")
+	if err := bindEnv(fs, getenv); err != nil {
+		return nil, err
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if o.Hal != RealHal && o.Hal != SimHal {
+		return nil, fmt.Errorf("unknown hal %q", o.Hal)
+	}
+	if o.LogLevel != LogDebug && o.LogLevel != LogInfo && o.LogLevel != LogOff {
+		return nil, fmt.Errorf("unknown log level %q", o.LogLevel)
+	}
+
+	o.Command = Run
+	if fs.NArg() > 0 {
+		o.Command, o.Args = fs.Arg(0), fs.Args()[1:]
+	}
+	if _, ok := commands[o.Command]; !ok {
+		fs.Usage()
+		return nil, fmt.Errorf("unknown command %q", o.Command)
+	}
+	if o.Command == Sim {
+		o.Command, o.Hal = Run, SimHal
+	}
+	if o.Command == Backup && len(o.Args) != 1 {
+		return nil, errors.New("backup needs the file to write")
+	}
+	if a := o.Completion.Action; a != completion.None && a != completion.Finish && a != completion.NextStep {
+		return nil, fmt.Errorf("unknown completion action %q", a)
+	}
+	if o.Completion.Tolerance <= 0 || o.Completion.Window <= 0 {
+		return nil, errors.New("the completion tolerance and window have to be positive")
+	}
+	if r := o.Alarms; r.Lag < 0 || r.LagDrop < 0 || r.Stall < 0 || r.StallMargin < 0 || r.FastRate < 0 || r.Exotherm < 0 {
+		return nil, errors.New("the alarm rules can't be negative")
+	}
+	if o.Plant.TimeConstant <= 0 || o.Plant.DeadTime < 0 {
+		return nil, errors.New("the simulated time constant has to be positive and the dead time can't be negative")
+	}
+	if o.FlushInterval <= 0 {
+		return nil, errors.New("the flush interval has to be positive")
+	}
+	if o.BackupKeep < 1 {
+		return nil, fmt.Errorf("invalid backup-keep %d", o.BackupKeep)
+	}
+	if o.Command == Restore && len(o.Args) != 1 {
+		return nil, errors.New("restore needs the backup file")
+	}
+	if o.Command == Import && (len(o.Args) < 1 || len(o.Args) > 2) {
+		return nil, errors.New("import needs the BeerXML file and optionally the recipe name")
+	}
+	return o, nil
+}
+
+// bindEnv sets the flags of fs from their environment variables, named
+// after the flag in upper case with underscores for dashes, e.g.
+// ALCOBOT_DATA_DIR for -data-dir. The command line is parsed after, so its
+// flags take precedence.
+func bindEnv(fs *flag.FlagSet, getenv func(string) string) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		name := envPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		v := getenv(name)
+		if v == "" || err != nil {
+			return
+		}
+		if e := f.Value.Set(v); e != nil {
+			err = fmt.Errorf("invalid %v: %w", name, e)
+		}
+	})
+	return err
+}
+
+// DBFile is the path of the database.
+func (o *Options) DBFile() string {
+	if filepath.IsAbs(o.DB) {
+		return o.DB
+	}
+	return filepath.Join(o.DataDir, o.DB)
+}
+
+// BackupConfig is the configuration of the scheduled backups.
+func (o *Options) BackupConfig() backups.Config {
+	dir := o.BackupDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(o.DataDir, dir)
+	}
+	return backups.Config{Dir: dir, Interval: o.BackupInterval, Keep: o.BackupKeep}
+}
+
+// UploadConfig is the configuration of the uploader. Brewfather posts are
+// throttled to its minimum interval; the pending readings are kept in the
+// data directory.
+func (o *Options) UploadConfig() (uploader.Config, error) {
+	conf := uploader.Config{URL: o.UploadURL, Name: o.UploadName, Interval: o.UploadInterval,
+		Pending: filepath.Join(o.DataDir, "uploads.json")}
+	if o.UploadTemplate == "" {
+		conf.MinInterval = uploader.BrewfatherMinInterval
+		return conf, nil
+	}
+	t, err := uploader.ParseTemplate(o.UploadTemplate)
+	if err != nil {
+		return conf, fmt.Errorf("invalid upload template: %w", err)
+	}
+	conf.Template = t
+	conf.ContentType = mime.TypeByExtension(filepath.Ext(o.UploadTemplate))
+	return conf, nil
+}
+
+// SetupLog applies the log level to the standard logger. Debug adds the
+// routine chatter of the bot and the source of every message; off discards
+// everything.
+func (o *Options) SetupLog() {
+	debuglog.Enable(o.LogLevel == LogDebug)
+	switch o.LogLevel {
+	case LogDebug:
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	case LogOff:
+		log.SetOutput(io.Discard)
+	default:
+		log.SetOutput(os.Stderr)
+	}
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/alarm"
+	backups "github.com/zlowred/alcobot/backup"
+	"github.com/zlowred/alcobot/completion"
+	"github.com/zlowred/alcobot/plant"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(name string) string {
+		return vars[name]
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	o, err := Parse(nil, env(nil), io.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, Run, o.Command)
+	assert.Equal(t, RealHal, o.Hal)
+	assert.Equal(t, ":8080", o.Listen)
+	assert.Equal(t, LogInfo, o.LogLevel)
+	assert.False(t, o.Headless)
+	assert.Equal(t, 30*time.Second, o.FlushInterval)
+	assert.Equal(t, "alcobot.db", o.DBFile())
+	assert.Empty(t, o.UploadURL)
+	assert.Equal(t, 15*time.Minute, o.UploadInterval)
+	assert.Equal(t, backups.Config{Dir: "backups", Interval: 24 * time.Hour, Keep: 7}, o.BackupConfig())
+	assert.Equal(t, completion.Config{Tolerance: 1, Window: 48 * time.Hour, Action: completion.None}, o.Completion)
+	assert.Equal(t, alarm.DefaultRules, o.Alarms)
+	assert.Equal(t, plant.DefaultModel, o.Plant)
+}
+
+func TestParseFlagsOverrideEnvironment(t *testing.T) {
+	vars := map[string]string{"ALCOBOT_DATA_DIR": "/var/lib/alcobot", "ALCOBOT_LISTEN": ":80", "ALCOBOT_HEADLESS": "true"}
+	o, err := Parse([]string{"-listen", "127.0.0.1:8080", "-log-level", "debug"}, env(vars), io.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8080", o.Listen)
+	assert.Equal(t, LogDebug, o.LogLevel)
+	assert.True(t, o.Headless)
+	assert.Equal(t, filepath.Join("/var/lib/alcobot", "alcobot.db"), o.DBFile())
+
+	o, err = Parse([]string{"-db", "/tmp/test.db"}, env(vars), io.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/test.db", o.DBFile())
+}
+
+func TestParseCommands(t *testing.T) {
+	o, err := Parse([]string{"-headless", "sim"}, env(nil), io.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, Run, o.Command)
+	assert.Equal(t, SimHal, o.Hal)
+	assert.True(t, o.Headless)
+
+	o, err = Parse([]string{"export", "3"}, env(nil), io.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, Export, o.Command)
+	assert.Equal(t, []string{"3"}, o.Args)
+
+	_, err = Parse([]string{"backup"}, env(nil), io.Discard)
+	assert.Error(t, err)
+	o, err = Parse([]string{"-backup-dir", "/mnt/usb", "restore", "old.db"}, env(nil), io.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, Restore, o.Command)
+	assert.Equal(t, "/mnt/usb", o.BackupConfig().Dir)
+	_, err = Parse([]string{"restore"}, env(nil), io.Discard)
+	assert.Error(t, err)
+	_, err = Parse([]string{"-backup-keep", "0"}, env(nil), io.Discard)
+	assert.Error(t, err)
+	o, err = Parse(nil, env(map[string]string{"ALCOBOT_FLUSH_INTERVAL": "5s"}), io.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, o.FlushInterval)
+	_, err = Parse([]string{"-flush-interval", "0"}, env(nil), io.Discard)
+	assert.Error(t, err)
+	o, err = Parse([]string{"-completion-action", "next-step"}, env(map[string]string{"ALCOBOT_COMPLETION_TOLERANCE": "0.5"}), io.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, completion.Config{Tolerance: 0.5, Window: 48 * time.Hour, Action: completion.NextStep}, o.Completion)
+	_, err = Parse([]string{"-completion-action", "dump"}, env(nil), io.Discard)
+	assert.Error(t, err)
+	_, err = Parse([]string{"-completion-window", "0s"}, env(nil), io.Discard)
+	assert.Error(t, err)
+	o, err = Parse([]string{"-alarm-stall", "0s"}, env(map[string]string{"ALCOBOT_ALARM_EXOTHERM": "3.5"}), io.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), o.Alarms.Stall)
+	assert.Equal(t, 3.5, o.Alarms.Exotherm)
+	_, err = Parse([]string{"-alarm-fast-rate", "-1"}, env(nil), io.Discard)
+	assert.Error(t, err)
+	o, err = Parse([]string{"-sim-dead-time", "0s", "sim"}, env(map[string]string{"ALCOBOT_SIM_GAIN": "0.2"}), io.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, plant.Model{Gain: 0.2, TimeConstant: plant.DefaultModel.TimeConstant, Ambient: plant.DefaultModel.Ambient}, o.Plant)
+	_, err = Parse([]string{"-sim-time-constant", "0s"}, env(nil), io.Discard)
+	assert.Error(t, err)
+	_, err = Parse([]string{"frobnicate"}, env(nil), io.Discard)
+	assert.Error(t, err)
+	_, err = Parse([]string{"-hal", "fpga"}, env(nil), io.Discard)
+	assert.Error(t, err)
+	_, err = Parse(nil, env(map[string]string{"ALCOBOT_HEADLESS": "maybe"}), io.Discard)
+	assert.ErrorContains(t, err, "invalid ALCOBOT_HEADLESS")
+}
+
+func TestUploadConfig(t *testing.T) {
+	vars := map[string]string{"ALCOBOT_UPLOAD_URL": "http://log.brewfather.net/stream?id=x", "ALCOBOT_UPLOAD_INTERVAL": "20m"}
+	o, err := Parse([]string{"-data-dir", "/var/lib/alcobot"}, env(vars), io.Discard)
+	assert.NoError(t, err)
+	conf, err := o.UploadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "http://log.brewfather.net/stream?id=x", conf.URL)
+	assert.Equal(t, 20*time.Minute, conf.Interval)
+	assert.Equal(t, 15*time.Minute, conf.MinInterval)
+	assert.Nil(t, conf.Template)
+	assert.Equal(t, filepath.Join("/var/lib/alcobot", "uploads.json"), conf.Pending)
+
+	file := filepath.Join(t.TempDir(), "body.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"sg": {{json .SG}}}`), 0644))
+	o, err = Parse([]string{"-upload-template", file, "-upload-interval", "1m"}, env(vars), io.Discard)
+	assert.NoError(t, err)
+	conf, err = o.UploadConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, conf.Template)
+	assert.Equal(t, time.Duration(0), conf.MinInterval)
+	assert.Equal(t, time.Minute, conf.Interval)
+	assert.Equal(t, "application/json", conf.ContentType)
+
+	_, err = Parse(nil, env(map[string]string{"ALCOBOT_UPLOAD_INTERVAL": "soon"}), io.Discard)
+	assert.Error(t, err)
+}
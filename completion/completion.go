@@ -0,0 +1,166 @@
+// Package completion tells when fermentation is complete from the SG
+// curve of the current brew.
+package completion
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/hub"
+)
+
+const (
+	// DefaultTolerance is how much the SG may change over the window for
+	// fermentation to be complete, in gravity points (0.001 SG).
+	DefaultTolerance = 1.
+	// DefaultWindow is how long the SG has to stay within the tolerance.
+	DefaultWindow = time.Hour * 48
+
+	// analysisPeriod is how often the SG curve is analysed.
+	analysisPeriod = time.Minute * 10
+	// bucketSeconds is the span of the SG averages the curve is fitted to.
+	bucketSeconds = 3600
+	// minSamples is the number of hourly averages needed for a fit.
+	minSamples = 12
+	// minDrop is how far the SG has to fall before it can be stable, so
+	// the lag phase before fermentation starts doesn't count.
+	minDrop = 0.005
+)
+
+// Actions taken when fermentation is complete.
+const (
+	// None only publishes the completion.
+	None = "none"
+	// Finish finishes the brew.
+	Finish = "finish"
+	// NextStep ends the schedule step in progress, e.g. to start a
+	// diacetyl rest or a cold crash.
+	NextStep = "next-step"
+)
+
+// Config configures the detector: fermentation is complete when the SG
+// stays within Tolerance points over Window, which triggers Action.
+type Config struct {
+	Tolerance float64
+	Window    time.Duration
+	Action    string
+}
+
+type Detector struct {
+	hub  *hub.Hub
+	conf Config
+
+	brew    *config.Configuration
+	buckets []bucket
+	// stable is the last completion published, primed whether there was
+	// one for the brew; a brew that is already complete when it's loaded
+	// doesn't trigger the action again.
+	stable bool
+	primed bool
+}
+
+type bucket struct {
+	sum float64
+	n   int
+}
+
+func New(h *hub.Hub, conf Config) *Detector {
+	if conf.Tolerance <= 0 {
+		conf.Tolerance = DefaultTolerance
+	}
+	if conf.Window <= 0 {
+		conf.Window = DefaultWindow
+	}
+	if conf.Action == "" {
+		conf.Action = None
+	}
+	return &Detector{hub: h, conf: conf}
+}
+
+// Run collects the SG of the recorded data points and publishes the
+// analysis of the fermentation of the current brew every analysisPeriod
+// until ctx is done.
+func (d *Detector) Run(ctx context.Context) {
+	configCh := d.hub.Configuration.JoinContext(ctx)
+	dpCh := d.hub.DataPoints.JoinContext(ctx)
+	t := time.NewTicker(analysisPeriod)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case x := <-configCh:
+			if d.brew == nil || d.brew.Id != x.Id {
+				d.buckets, d.stable, d.primed = nil, false, false
+			}
+			d.brew = x
+		case x := <-dpCh:
+			d.add(x)
+		case now := <-t.C:
+			d.analyze(now)
+		}
+	}
+}
+
+func (d *Detector) add(dp *hub.DataPoint) {
+	if d.brew == nil || dp.Id != d.brew.Id || math.IsNaN(dp.SG) {
+		return
+	}
+	i := dp.Step / bucketSeconds
+	for len(d.buckets) <= i {
+		d.buckets = append(d.buckets, bucket{})
+	}
+	d.buckets[i].sum += dp.SG
+	d.buckets[i].n++
+}
+
+func (d *Detector) samples() []sample {
+	var samples []sample
+	for i, b := range d.buckets {
+		if b.n > 0 {
+			samples = append(samples, sample{float64(i) + 0.5, b.sum / float64(b.n)})
+		}
+	}
+	return samples
+}
+
+func (d *Detector) analyze(now time.Time) {
+	if d.brew == nil || d.brew.Stage != config.BREWING {
+		return
+	}
+	samples := d.samples()
+	tol := d.conf.Tolerance / 1000
+	f := hub.Fermentation{Id: d.brew.Id, FG: math.NaN(), Time: now,
+		Stable: stable(samples, d.conf.Window.Hours(), tol)}
+	if c, ok := fit(samples); ok {
+		f.FG = c.FG
+		hours := math.Max(c.done(tol), samples[0].t)
+		f.ETA = d.brew.BrewingStartTime.Add(time.Duration(hours * float64(time.Hour)))
+	}
+	d.hub.Fermentation.Send(f)
+
+	if f.Stable && !d.stable && d.primed {
+		log.Printf("Brew %d: fermentation complete, SG stable within %v points for %v\n", d.brew.Id, d.conf.Tolerance, d.conf.Window)
+		if err := d.act(now); err != nil {
+			log.Printf("Can't %v on completion: %v\n", d.conf.Action, err)
+		}
+	}
+	d.stable, d.primed = f.Stable, true
+}
+
+func (d *Detector) act(now time.Time) error {
+	switch d.conf.Action {
+	case None:
+		return nil
+	case Finish:
+		return d.hub.Transition(hub.Finish)
+	case NextStep:
+		return d.hub.EndScheduleStep(now)
+	}
+	return fmt.Errorf("unknown action %q", d.conf.Action)
+}
@@ -0,0 +1,123 @@
+package completion
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/hub"
+)
+
+var ale = curve{FG: 1.012, A: 0.040, K: 0.08, M: 60}
+
+// noisy samples ale every hour up to hours, with a deterministic noise of
+// up to 0.0004.
+func noisy(hours int) []sample {
+	var samples []sample
+	for i := 0; i < hours; i++ {
+		t := float64(i) + 0.5
+		samples = append(samples, sample{t, ale.at(t) + 0.0004*math.Sin(float64(i)*2.3)})
+	}
+	return samples
+}
+
+func TestFit(t *testing.T) {
+	c, ok := fit(noisy(120))
+	assert.True(t, ok)
+	assert.InDelta(t, ale.FG, c.FG, 0.0005)
+	assert.InDelta(t, ale.done(0.001), c.done(0.001), 8)
+
+	_, ok = fit(noisy(40))
+	assert.False(t, ok, "no fit before the curve turns")
+	_, ok = fit(noisy(minSamples - 1))
+	assert.False(t, ok)
+}
+
+func TestStable(t *testing.T) {
+	assert.False(t, stable(noisy(100), 48, 0.001))
+	assert.True(t, stable(noisy(200), 48, 0.001))
+	assert.False(t, stable(noisy(200), 48, 0.0005))
+
+	// before fermentation starts the SG is flat too
+	lag := []sample{}
+	for i := 0; i < 60; i++ {
+		lag = append(lag, sample{float64(i), 1.052})
+	}
+	assert.False(t, stable(lag, 48, 0.001))
+	assert.False(t, stable(nil, 48, 0.001))
+}
+
+func brewing(t *testing.T) (*hub.Hub, *config.Configuration) {
+	h, err := hub.Open(filepath.Join(t.TempDir(), "alcobot.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Close() })
+	assert.NoError(t, h.Plan("Ale", 1.052, []hub.ScheduleStep{{Name: "Primary", Temperature: 19, Duration: 20 * 24 * time.Hour}, {Name: "Cold crash", Temperature: 2}}))
+	assert.NoError(t, h.Transition(hub.Prepare))
+	assert.NoError(t, h.Transition(hub.Pitch))
+	conf, err := h.SessionConfig(1)
+	assert.NoError(t, err)
+	return h, conf
+}
+
+// record adds the points of ale from hour from to hour to, one a minute.
+func record(d *Detector, id, from, to int) {
+	for step := from * 3600; step < to*3600; step += 60 {
+		d.add(&hub.DataPoint{Id: id, Step: step, SG: ale.at(float64(step) / 3600)})
+	}
+}
+
+func TestDetector(t *testing.T) {
+	h, conf := brewing(t)
+	results := h.Fermentation.Subscribe()
+	defer results.Close()
+	configs := h.Configuration.Subscribe()
+	defer configs.Close()
+
+	d := New(h, Config{Window: 24 * time.Hour, Action: NextStep})
+	d.brew = conf
+	record(d, conf.Id, 0, 100)
+	d.add(&hub.DataPoint{Id: conf.Id + 1, Step: 100 * 3600, SG: 1.1})
+	d.analyze(conf.BrewingStartTime.Add(100 * time.Hour))
+	f := <-results.C
+	assert.Equal(t, conf.Id, f.Id)
+	assert.False(t, f.Stable)
+	assert.InDelta(t, ale.FG, f.FG, 0.0002)
+	eta := conf.BrewingStartTime.Add(time.Duration(ale.done(0.001) * float64(time.Hour)))
+	assert.WithinDuration(t, eta, f.ETA, 2*time.Hour)
+
+	record(d, conf.Id, 100, 200)
+	now := conf.PitchTime.Add(200 * time.Hour)
+	d.analyze(now)
+	f = <-results.C
+	assert.True(t, f.Stable)
+	<-configs.C
+	steps, err := h.Schedule(conf.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hub.CurrentStep(steps, now.Sub(conf.PitchTime)), "the cold crash started")
+
+	// the action is taken once
+	d.analyze(now.Add(time.Hour))
+	<-results.C
+	assert.Equal(t, 0, configs.Pending())
+}
+
+func TestCompleteBrewIsNotActedOnWhenLoaded(t *testing.T) {
+	h, conf := brewing(t)
+	configs := h.Configuration.Subscribe()
+	defer configs.Close()
+
+	d := New(h, Config{Action: Finish})
+	d.brew = conf
+	record(d, conf.Id, 0, 200)
+	d.analyze(conf.PitchTime.Add(200 * time.Hour))
+	assert.True(t, d.stable)
+	assert.Equal(t, 0, configs.Pending())
+	conf, err := h.SessionConfig(conf.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, config.BREWING, conf.Stage)
+}
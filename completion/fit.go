@@ -0,0 +1,125 @@
+package completion
+
+import (
+	"math"
+)
+
+// The grid the rate K (per hour) of the fermentation curve is searched on.
+const (
+	minRate   = 0.002
+	maxRate   = 2.
+	rateSteps = 40
+	// midSteps is the number of inflection times tried over three times
+	// the span of the samples.
+	midSteps = 48
+	// refineSteps is the number of steps each way the best grid point is
+	// refined with.
+	refineSteps = 10
+)
+
+// sample is the average SG over an hour, t hours after the brew started.
+type sample struct {
+	t  float64
+	sg float64
+}
+
+// curve is a logistic fermentation curve: the SG falls by A from FG + A to
+// FG, fastest M hours after the start at a rate of K.
+type curve struct {
+	FG float64
+	A  float64
+	K  float64
+	M  float64
+}
+
+func (c curve) at(t float64) float64 {
+	return c.FG + c.A/(1+math.Exp(c.K*(t-c.M)))
+}
+
+// done is the hour the curve gets within tol of FG.
+func (c curve) done(tol float64) float64 {
+	if c.A <= tol {
+		return math.Inf(-1)
+	}
+	return c.M + math.Log(c.A/tol-1)/c.K
+}
+
+// fit fits a curve to samples by least squares. For a given K and M the
+// curve is linear in FG and A, which are solved for directly, while K and M
+// are searched on a grid that is then refined around the best point. The
+// FG is only known once the curve has turned, so there is no fit before
+// fermentation was past its fastest.
+func fit(samples []sample) (curve, bool) {
+	if len(samples) < minSamples {
+		return curve{}, false
+	}
+	t0, t1 := samples[0].t, samples[len(samples)-1].t
+	span := t1 - t0
+	best, bestErr := curve{}, math.Inf(1)
+	try := func(k, m float64) {
+		var sx, sy, sxx, sxy float64
+		for _, s := range samples {
+			x := 1 / (1 + math.Exp(k*(s.t-m)))
+			sx, sy, sxx, sxy = sx+x, sy+s.sg, sxx+x*x, sxy+x*s.sg
+		}
+		n := float64(len(samples))
+		den := n*sxx - sx*sx
+		if den < 1e-12 {
+			return
+		}
+		c := curve{K: k, M: m}
+		c.A = (n*sxy - sx*sy) / den
+		c.FG = (sy - c.A*sx) / n
+		if c.A <= 0 {
+			return
+		}
+		var e float64
+		for _, s := range samples {
+			d := c.at(s.t) - s.sg
+			e += d * d
+		}
+		if e < bestErr {
+			best, bestErr = c, e
+		}
+	}
+
+	rateStep := math.Pow(maxRate/minRate, 1./(rateSteps-1))
+	midStep := 3 * span / midSteps
+	for i := 0; i < rateSteps; i++ {
+		k := minRate * math.Pow(rateStep, float64(i))
+		for j := 0; j <= midSteps; j++ {
+			try(k, t0-span+midStep*float64(j))
+		}
+	}
+	k, m := best.K, best.M
+	for i := -refineSteps; i <= refineSteps; i++ {
+		for j := -refineSteps; j <= refineSteps; j++ {
+			try(k*math.Pow(rateStep, float64(i)/refineSteps), m+midStep*float64(j)/refineSteps)
+		}
+	}
+
+	if math.IsInf(bestErr, 1) || best.M >= t1 {
+		return curve{}, false
+	}
+	return best, true
+}
+
+// stable reports whether the samples of the last window hours stay within
+// tol of each other, after the SG fell by at least minDrop.
+func stable(samples []sample, window, tol float64) bool {
+	if len(samples) == 0 {
+		return false
+	}
+	last := samples[len(samples)-1].t
+	if samples[0].t > last-window {
+		return false
+	}
+	top, low, high := math.Inf(-1), math.Inf(1), math.Inf(-1)
+	for _, s := range samples {
+		top = math.Max(top, s.sg)
+		if s.t >= last-window {
+			low, high = math.Min(low, s.sg), math.Max(high, s.sg)
+		}
+	}
+	return high-low <= tol && top-low >= minDrop
+}
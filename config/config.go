@@ -18,6 +18,20 @@ const (
 	DONE
 )
 
+func (s Stage) String() string {
+	switch s {
+	case SETUP:
+		return "setup"
+	case PREPARATION:
+		return "preparation"
+	case BREWING:
+		return "brewing"
+	case DONE:
+		return "done"
+	}
+	return "unknown"
+}
+
 type Screen int
 
 const (
@@ -65,4 +79,22 @@ type Configuration struct {
 	OG                  float64
 	BrewingStartTime    time.Time
 	PitchTime           time.Time
+	// Filter specs as accepted by filter.New, e.g. "trimmed:100,20" or
+	// "kalman:0.01,16".
+	NpaTemperatureFilter string
+	NpaPressureFilter    string
+	DsTemperatureFilter  string
+	AdsValueFilter       string
+	Name                 string
+	// NpaCalibrationTemperature is the wort temperature NpaCalibration was
+	// taken at and NpaReferenceTemperature the NPA die temperature then, in
+	// ºC; 0 when unknown. NpaDrift is the drift of the NPA offset in Pa/ºC
+	// of die temperature.
+	NpaCalibrationTemperature float64
+	NpaReferenceTemperature   float64
+	NpaDrift                  float64
+	// SgModel maps the compensated pressure p to SG as SgModel[0] +
+	// SgModel[1]·p + SgModel[2]·p², fitted to calibration points; unused
+	// while SgModel[1] is 0.
+	SgModel [3]float64
 }
@@ -0,0 +1,205 @@
+// Package control measures how well the fermenter temperature is
+// controlled, per stage and over the whole brew, to compare controller
+// settings.
+package control
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+)
+
+const (
+	// Band is how far in ºC the temperature may be from its target to count
+	// as on target.
+	Band = 0.5
+	// setpointStep is the smallest change of the target that counts as a
+	// setpoint change.
+	setpointStep = 0.05
+	// reversalPower is the power in % that counts as heating or cooling.
+	reversalPower = 1.
+	// statsPeriod is how often the stats of the current brew are updated.
+	statsPeriod = time.Minute * 10
+)
+
+// response follows the temperature after a setpoint change to target in
+// direction dir, +1 up and -1 down.
+type response struct {
+	dir       float64
+	start     int
+	target    float64
+	overshoot float64
+	settledAt int
+}
+
+// scope accumulates the stats of the data points of a scope.
+type scope struct {
+	stats     hub.ControlStats
+	sumSquare float64
+	power     float64
+	powerTime int
+	target    float64
+	started   bool
+	sign      float64
+	response  *response
+	responses []hub.SetpointResponse
+}
+
+func (s *scope) add(dp *hub.DataPoint) {
+	if !math.IsNaN(dp.Power) {
+		s.power += math.Abs(dp.Power) * float64(dp.Span)
+		s.powerTime += dp.Span
+		if math.Abs(dp.Power) >= reversalPower {
+			sign := math.Copysign(1, dp.Power)
+			if s.sign != 0 && sign != s.sign {
+				s.stats.Reversals++
+			}
+			s.sign = sign
+		}
+	}
+	if math.IsNaN(dp.CurrentTemp) || math.IsNaN(dp.TargetTemp) {
+		return
+	}
+	dev := dp.CurrentTemp*conv.DsToC(1) - dp.TargetTemp
+	span := time.Duration(dp.Span) * time.Second
+	s.stats.Duration += span
+	s.sumSquare += dev * dev * float64(dp.Span)
+	s.stats.MaxDeviation = math.Max(s.stats.MaxDeviation, math.Abs(dev))
+	if math.Abs(dev) <= Band {
+		s.stats.InBand += span
+	}
+
+	switch {
+	case !s.started:
+		// the start of the scope is a step from wherever the temperature is
+		if math.Abs(dev) > Band {
+			s.begin(-math.Copysign(1, dev), dp.Step-dp.Span, dp.TargetTemp)
+		}
+	case math.Abs(dp.TargetTemp-s.target) >= setpointStep:
+		s.begin(math.Copysign(1, dp.TargetTemp-s.target), dp.Step-dp.Span, dp.TargetTemp)
+	}
+	s.started, s.target = true, dp.TargetTemp
+	r := s.response
+	if r == nil {
+		return
+	}
+	r.overshoot = math.Max(r.overshoot, r.dir*dev)
+	if math.Abs(dev) > Band {
+		r.settledAt = -1
+	} else if r.settledAt < 0 {
+		r.settledAt = dp.Step
+	}
+}
+
+// begin ends the response in progress and starts one to target at step.
+func (s *scope) begin(dir float64, step int, target float64) {
+	s.end()
+	s.response = &response{dir: dir, start: step, target: target, settledAt: -1}
+}
+
+// end records the response in progress.
+func (s *scope) end() {
+	r := s.response
+	if r == nil {
+		return
+	}
+	s.response = nil
+	sr := hub.SetpointResponse{Step: r.start, Target: r.target, Overshoot: r.overshoot, Settled: r.settledAt >= 0}
+	if sr.Settled {
+		sr.SettlingTime = time.Duration(r.settledAt-r.start) * time.Second
+	}
+	s.responses = append(s.responses, sr)
+}
+
+func (s *scope) result() (hub.ControlStats, bool) {
+	s.end()
+	s.stats.SetResponses(s.responses)
+	if s.stats.Duration == 0 {
+		return hub.ControlStats{}, false
+	}
+	s.stats.RMSDeviation = math.Sqrt(s.sumSquare / s.stats.Duration.Seconds())
+	if s.powerTime > 0 {
+		s.stats.AveragePower = s.power / float64(s.powerTime)
+	}
+	return s.stats, true
+}
+
+// Stats measures the control over the data points of the brew conf, per
+// stage and over the whole brew; stages without data points are left out.
+// The points up to the pitch time were recorded in preparation.
+func Stats(conf *config.Configuration, dps []*hub.DataPoint) []hub.ControlStats {
+	pitch := math.MaxInt
+	if conf.PitchTime.Unix() > 0 {
+		pitch = int(conf.PitchTime.Sub(conf.BrewingStartTime) / time.Second)
+	}
+	preparation := &scope{stats: hub.ControlStats{Scope: config.PREPARATION.String()}}
+	brewing := &scope{stats: hub.ControlStats{Scope: config.BREWING.String()}}
+	brew := &scope{stats: hub.ControlStats{Scope: hub.BrewScope}}
+	for _, dp := range dps {
+		if dp.Step <= pitch {
+			preparation.add(dp)
+		} else {
+			brewing.add(dp)
+		}
+		brew.add(dp)
+	}
+
+	var stats []hub.ControlStats
+	for _, s := range []*scope{preparation, brewing, brew} {
+		if st, ok := s.result(); ok {
+			stats = append(stats, st)
+		}
+	}
+	return stats
+}
+
+// Recorder keeps the control stats of the current brew up to date.
+type Recorder struct {
+	hub  *hub.Hub
+	brew *config.Configuration
+}
+
+func New(h *hub.Hub) *Recorder {
+	return &Recorder{hub: h}
+}
+
+// Run updates the control stats of the current brew every statsPeriod
+// while it is recorded, and once more when it is done, until ctx is done.
+func (r *Recorder) Run(ctx context.Context) {
+	configCh := r.hub.Configuration.JoinContext(ctx)
+	t := time.NewTicker(statsPeriod)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case x := <-configCh:
+			done := r.brew != nil && r.brew.Id == x.Id && r.brew.Stage != config.DONE && x.Stage == config.DONE
+			r.brew = x
+			if done {
+				r.update()
+			}
+		case <-t.C:
+			if r.brew != nil && (r.brew.Stage == config.PREPARATION || r.brew.Stage == config.BREWING) {
+				r.update()
+			}
+		}
+	}
+}
+
+func (r *Recorder) update() {
+	dps, err := r.hub.SessionDataPoints(r.brew.Id)
+	if err != nil {
+		log.Printf("Can't load data points of brew %d for control stats: %v\n", r.brew.Id, err)
+		return
+	}
+	if err := r.hub.SaveControlStats(r.brew.Id, Stats(r.brew, dps)); err != nil {
+		log.Printf("Can't save control stats of brew %d: %v\n", r.brew.Id, err)
+	}
+}
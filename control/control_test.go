@@ -0,0 +1,107 @@
+package control
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+)
+
+// point is a second at temp ºC with target and power.
+func point(step int, target, temp, power float64) *hub.DataPoint {
+	return &hub.DataPoint{Id: 1, Step: step, TargetTemp: target, CurrentTemp: float64(conv.CtoDs(temp)), SG: math.NaN(), PID: power, Power: power, Span: 1}
+}
+
+func TestStats(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	conf := &config.Configuration{BrewingStartTime: start, PitchTime: start.Add(100 * time.Second)}
+	var dps []*hub.DataPoint
+	// preparation: cooling from 25ºC down to 20ºC, undershooting to 19ºC
+	for step := 1; step <= 100; step++ {
+		temp := 25 - 0.1*float64(step-1)
+		if step > 60 {
+			temp = 19 + 0.025*float64(step-61)
+		}
+		dps = append(dps, point(step, 20, temp, -50))
+	}
+	// brewing: on target at 20ºC, then up to 21ºC, overshooting by 0.25ºC
+	for step := 101; step <= 200; step++ {
+		target, temp, power := 20., 20., 10.
+		if step > 150 {
+			target, temp, power = 21, 20.5+0.0625*float64(step-151), 30
+		}
+		if step > 160 {
+			temp, power = 21.25, -10
+		}
+		if step > 180 {
+			temp, power = 21, 10
+		}
+		dps = append(dps, point(step, target, temp, power))
+	}
+	// no temperature, only counts for the power
+	dps = append(dps, &hub.DataPoint{Id: 1, Step: 201, TargetTemp: 21, CurrentTemp: math.NaN(), SG: math.NaN(), Power: 10, Span: 1})
+
+	stats := Stats(conf, dps)
+	assert.Len(t, stats, 3)
+
+	prep := stats[0]
+	assert.Equal(t, "preparation", prep.Scope)
+	assert.Equal(t, 100*time.Second, prep.Duration)
+	assert.Equal(t, 5., prep.MaxDeviation)
+	assert.Equal(t, 1, prep.Setpoints)
+	assert.InDelta(t, 1, prep.Overshoot, 1e-9)
+	// back within 0.5ºC for good once the sensor reads 19.5ºC, 80 seconds in
+	assert.Equal(t, 1, prep.Settled)
+	assert.Equal(t, 80*time.Second, prep.SettlingTime)
+	if assert.Len(t, prep.Responses, 1) {
+		assert.Equal(t, 0, prep.Responses[0].Step)
+		assert.Equal(t, 20., prep.Responses[0].Target)
+		assert.True(t, prep.Responses[0].Settled)
+	}
+	assert.Equal(t, 0, prep.Reversals)
+	assert.Equal(t, 50., prep.AveragePower)
+
+	brewing := stats[1]
+	assert.Equal(t, "brewing", brewing.Scope)
+	assert.Equal(t, 100*time.Second, brewing.Duration)
+	assert.Equal(t, 100*time.Second, brewing.InBand)
+	assert.Equal(t, 1, brewing.Setpoints, "already on target when brewing starts")
+	assert.InDelta(t, 0.25, brewing.Overshoot, 1e-9)
+	assert.Equal(t, 1, brewing.Settled)
+	assert.Equal(t, time.Second, brewing.SettlingTime)
+	assert.Equal(t, []hub.SetpointResponse{{Step: 150, Target: 21, Overshoot: 0.25, Settled: true, SettlingTime: time.Second}}, brewing.Responses)
+	assert.Equal(t, 2, brewing.Reversals)
+	assert.InDelta(t, (50*10+10*30+20*10+21*10)/101., brewing.AveragePower, 1e-9)
+	assert.InDelta(t, 0.5, brewing.MaxDeviation, 1e-9)
+
+	brew := stats[2]
+	assert.Equal(t, hub.BrewScope, brew.Scope)
+	assert.Equal(t, 200*time.Second, brew.Duration)
+	assert.Equal(t, 2, brew.Setpoints)
+	assert.Equal(t, 2, brew.Settled)
+	assert.Equal(t, 5., brew.MaxDeviation)
+	assert.Equal(t, 3, brew.Reversals)
+	assert.InDelta(t, 1, brew.Overshoot, 1e-9)
+	assert.Len(t, brew.Responses, 2)
+
+	var sum float64
+	for _, dp := range dps[:200] {
+		dev := dp.CurrentTemp*conv.DsToC(1) - dp.TargetTemp
+		sum += dev * dev
+	}
+	assert.InDelta(t, math.Sqrt(sum/200), brew.RMSDeviation, 1e-9)
+}
+
+func TestStatsBeforePitch(t *testing.T) {
+	conf := &config.Configuration{BrewingStartTime: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	stats := Stats(conf, []*hub.DataPoint{point(1, 20, 20, 0), point(2, 20, 20.25, 0)})
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "preparation", stats[0].Scope)
+	assert.Equal(t, 0, stats[0].Setpoints)
+	assert.Equal(t, 2*time.Second, stats[0].InBand)
+	assert.Empty(t, Stats(conf, nil))
+}
@@ -0,0 +1,31 @@
+package conv
+
+// Plato converts a specific gravity to degrees Plato.
+func Plato(sg float64) float64 {
+	return -616.868 + 1111.14*sg - 630.272*sg*sg + 135.997*sg*sg*sg
+}
+
+// ApparentAttenuation is the share (%) of the gravity points of og that
+// are gone at sg.
+func ApparentAttenuation(og, sg float64) float64 {
+	return (og - sg) / (og - 1) * 100
+}
+
+// RealAttenuation is the share (%) of the original extract that was
+// fermented, correcting sg for the alcohol being lighter than water.
+func RealAttenuation(og, sg float64) float64 {
+	oe := Plato(og)
+	re := 0.1808*oe + 0.8192*Plato(sg)
+	return (oe - re) / oe * 100
+}
+
+// ABV is the alcohol by volume (%) by the common (OG - SG) * 131.25 rule.
+func ABV(og, sg float64) float64 {
+	return (og - sg) * 131.25
+}
+
+// ABVAlternate is the alcohol by volume (%) by the alternate formula, which
+// is closer for strong beers.
+func ABVAlternate(og, sg float64) float64 {
+	return 76.08 * (og - sg) / (1.775 - og) * sg / 0.794
+}
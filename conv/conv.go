@@ -1,5 +1,7 @@
 package conv
 
+import "math"
+
 func NpaToPa(raw int16, offset int16, minValue float64, maxValue float64, minPressure float64, maxPressure float64) float64 {
 	return minPressure + (float64(raw)+float64(offset)-minValue)/(maxValue-minValue)*(maxPressure-minPressure)
 }
@@ -24,6 +26,11 @@ func DsToC(raw int16) float64 {
 	return float64(raw) * 0.0625
 }
 
+// CtoDs is the raw DS18B20 reading of c ºC.
+func CtoDs(c float64) int16 {
+	return int16(math.Round(c / 0.0625))
+}
+
 func DsToF(raw int16) float64 {
 	return float64(raw)*0.1125 + 32.
 }
@@ -31,3 +38,14 @@ func DsToF(raw int16) float64 {
 func PaToSg(pa float64, diff float64) float64 {
 	return pa / (diff * 9.81)
 }
+
+func SgToPa(sg float64, diff float64) float64 {
+	return sg * diff * 9.81
+}
+
+// WaterDensity is the density of air-free water at c ºC in kg/m³, after
+// Kell (1975).
+func WaterDensity(c float64) float64 {
+	return (999.83952 + 16.945176*c - 7.9870401e-3*c*c - 46.170461e-6*c*c*c +
+		105.56302e-9*c*c*c*c - 280.54253e-12*c*c*c*c*c) / (1 + 16.879850e-3*c)
+}
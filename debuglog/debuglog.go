@@ -0,0 +1,35 @@
+// Package debuglog logs the routine chatter of the bot, written to the
+// standard logger only at the debug log level.
+package debuglog
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+)
+
+var enabled atomic.Bool
+
+// Enable turns the debug messages on or off.
+func Enable(on bool) {
+	enabled.Store(on)
+}
+
+// Enabled reports whether the debug messages are written.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Printf logs like log.Printf when the debug messages are on.
+func Printf(format string, v ...any) {
+	if enabled.Load() {
+		log.Output(2, fmt.Sprintf(format, v...))
+	}
+}
+
+// Println logs like log.Println when the debug messages are on.
+func Println(v ...any) {
+	if enabled.Load() {
+		log.Output(2, fmt.Sprintln(v...))
+	}
+}
@@ -0,0 +1,33 @@
+package debuglog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnable(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	log.SetFlags(log.Lshortfile)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+		Enable(false)
+	}()
+
+	Println("hidden")
+	Printf("hidden %d", 1)
+	assert.Empty(t, out.String())
+
+	Enable(true)
+	assert.True(t, Enabled())
+	Printf("shown %d", 2)
+	assert.Regexp(t, `^debuglog_test.go:\d+: shown 2\n$`, out.String())
+	out.Reset()
+	Println("shown", 3)
+	assert.Regexp(t, `^debuglog_test.go:\d+: shown 3\n$`, out.String())
+}
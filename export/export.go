@@ -0,0 +1,183 @@
+// Package export writes the recorded data of a brew as CSV or JSON.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/control"
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+)
+
+// Formats.
+const (
+	CSV  = "csv"
+	JSON = "json"
+)
+
+// Value is a recorded value; a missing one is NaN and exported as empty
+// or null.
+type Value float64
+
+func (v Value) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(v)) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(float64(v))
+}
+
+func (v Value) String() string {
+	if math.IsNaN(float64(v)) {
+		return ""
+	}
+	return strconv.FormatFloat(float64(v), 'f', -1, 64)
+}
+
+// Point is a data point at the time it was recorded. Points rolled up by
+// the retention of the hub stand for the Span seconds up to Time. The
+// temperatures are in ºC and the attenuations and ABVs in %, to 0.01.
+type Point struct {
+	Time                time.Time
+	Step                int
+	Span                int
+	TargetTemp          Value
+	CurrentTemp         Value
+	SG                  Value
+	PID                 Value
+	Power               Value
+	ApparentAttenuation Value
+	RealAttenuation     Value
+	ABV                 Value
+	ABVAlternate        Value
+}
+
+// Brew is the configuration snapshot of a brew, its data points and how
+// well its temperature was controlled.
+type Brew struct {
+	Config  *config.Configuration
+	Points  []Point
+	Control []hub.ControlStats
+}
+
+// Load loads the brew with id, or the current brew if id is 0.
+func Load(h *hub.Hub, id int) (*Brew, error) {
+	if id == 0 {
+		sessions, err := h.Sessions()
+		if err != nil {
+			return nil, err
+		}
+		if len(sessions) == 0 {
+			return nil, fmt.Errorf("no brew")
+		}
+		id = sessions[0].Id
+	}
+	conf, err := h.SessionConfig(id)
+	if err != nil {
+		return nil, fmt.Errorf("can't load brew %d: %w", id, err)
+	}
+	dps, err := h.SessionDataPoints(id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := h.ControlStats(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		// brews recorded before the stats were kept
+		stats = control.Stats(conf, dps)
+	}
+
+	b := &Brew{Config: conf, Points: make([]Point, 0, len(dps)), Control: stats}
+	for _, dp := range dps {
+		b.Points = append(b.Points, Point{
+			Time: conf.BrewingStartTime.Add(time.Duration(dp.Step) * time.Second), Step: dp.Step, Span: dp.Span,
+			TargetTemp: Value(dp.TargetTemp), CurrentTemp: Value(dp.CurrentTemp * conv.DsToC(1)), SG: Value(dp.SG), PID: Value(dp.PID), Power: Value(dp.Power),
+			ApparentAttenuation: percent(dp.ApparentAttenuation), RealAttenuation: percent(dp.RealAttenuation),
+			ABV: percent(dp.ABV), ABVAlternate: percent(dp.ABVAlternate),
+		})
+	}
+	return b, nil
+}
+
+func percent(x float64) Value {
+	return Value(math.Round(x*100) / 100)
+}
+
+// Write writes b to w in format.
+func (b *Brew) Write(w io.Writer, format string) error {
+	switch format {
+	case CSV:
+		return b.WriteCSV(w)
+	case JSON:
+		return b.WriteJSON(w)
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+// WriteJSON writes b as a single JSON object.
+func (b *Brew) WriteJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(b)
+}
+
+// WriteCSV writes the data points of b with a header row. The configuration
+// comes first, one "# Field: value" comment line per field, and then the
+// control stats, one "# Control scope: Field=value ..." line per scope
+// followed by a "# Setpoint scope: ..." line per setpoint change.
+func (b *Brew) WriteCSV(w io.Writer) error {
+	conf := reflect.ValueOf(*b.Config)
+	for i := 0; i < conf.NumField(); i++ {
+		if _, err := fmt.Fprintf(w, "# %v: %v\n", conf.Type().Field(i).Name, conf.Field(i).Interface()); err != nil {
+			return err
+		}
+	}
+	for _, s := range b.Control {
+		stats := reflect.ValueOf(s)
+		var fields []string
+		for i := 0; i < stats.NumField(); i++ {
+			if name := stats.Type().Field(i).Name; name != "Scope" && name != "Responses" {
+				fields = append(fields, fmt.Sprintf("%v=%v", name, stats.Field(i).Interface()))
+			}
+		}
+		if _, err := fmt.Fprintf(w, "# Control %v: %v\n", s.Scope, strings.Join(fields, " ")); err != nil {
+			return err
+		}
+		for _, r := range s.Responses {
+			settling := "never"
+			if r.Settled {
+				settling = r.SettlingTime.String()
+			}
+			if _, err := fmt.Fprintf(w, "# Setpoint %v: Step=%v Target=%v Overshoot=%v SettlingTime=%v\n", s.Scope, r.Step, r.Target, r.Overshoot, settling); err != nil {
+				return err
+			}
+		}
+	}
+
+	out := csv.NewWriter(w)
+	out.Write([]string{"Time", "Step", "Span", "TargetTemp", "CurrentTemp", "SG", "PID", "Power",
+		"ApparentAttenuation", "RealAttenuation", "ABV", "ABVAlternate"})
+	for _, p := range b.Points {
+		out.Write([]string{p.Time.Format(time.RFC3339), strconv.Itoa(p.Step), strconv.Itoa(p.Span),
+			p.TargetTemp.String(), p.CurrentTemp.String(), p.SG.String(), p.PID.String(), p.Power.String(),
+			p.ApparentAttenuation.String(), p.RealAttenuation.String(), p.ABV.String(), p.ABVAlternate.String()})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// FileName is the suggested name of the export of b in format.
+func (b *Brew) FileName(format string) string {
+	return fmt.Sprintf("brew-%d.%v", b.Config.Id, format)
+}
@@ -0,0 +1,86 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/hub"
+)
+
+func newBrew(t *testing.T) *hub.Hub {
+	h, err := hub.Open(filepath.Join(t.TempDir(), "alcobot.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Close() })
+	assert.NoError(t, h.Transition(hub.Prepare))
+	assert.NoError(t, h.SaveDataPoints([]*hub.DataPoint{
+		{Id: 1, Step: 1, TargetTemp: 20, CurrentTemp: 200, SG: math.NaN(), PID: 10, Power: 5, Span: 1},
+		{Id: 1, Step: 2, TargetTemp: 20, CurrentTemp: 201, SG: 1.05, PID: 10, Power: 5, Span: 1},
+	}))
+	assert.NoError(t, h.SetOG(1.06))
+	return h
+}
+
+func TestLoad(t *testing.T) {
+	h := newBrew(t)
+	b, err := Load(h, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, b.Config.Id)
+	assert.Len(t, b.Points, 2)
+	assert.Equal(t, b.Config.BrewingStartTime.Add(2*time.Second), b.Points[1].Time)
+	assert.Equal(t, Value(1.05), b.Points[1].SG)
+	assert.Equal(t, Value(16.67), b.Points[1].ApparentAttenuation)
+	assert.Equal(t, Value(1.31), b.Points[1].ABV)
+	assert.True(t, math.IsNaN(float64(b.Points[0].ABV)))
+	assert.Equal(t, []string{"preparation", "brew"}, []string{b.Control[0].Scope, b.Control[1].Scope})
+	assert.Equal(t, 2*time.Second, b.Control[0].Duration)
+
+	_, err = Load(h, 2)
+	assert.Error(t, err)
+}
+
+func TestWriteJSON(t *testing.T) {
+	b, err := Load(newBrew(t), 1)
+	assert.NoError(t, err)
+	var out bytes.Buffer
+	assert.NoError(t, b.Write(&out, JSON))
+
+	var decoded struct {
+		Config struct{ Id int }
+		Points []struct {
+			Step int
+			SG   *float64
+		}
+	}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, 1, decoded.Config.Id)
+	assert.Nil(t, decoded.Points[0].SG)
+	assert.Equal(t, 1.05, *decoded.Points[1].SG)
+}
+
+func TestWriteCSV(t *testing.T) {
+	b, err := Load(newBrew(t), 1)
+	assert.NoError(t, err)
+	var out bytes.Buffer
+	assert.NoError(t, b.Write(&out, CSV))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Contains(t, lines, "# Stage: preparation")
+	data := lines[len(lines)-3:]
+	assert.Contains(t, lines, "# OG: 1.06")
+	assert.Contains(t, lines, "# Control brew: Duration=2s RMSDeviation=7.468815376282908 MaxDeviation=7.5 InBand=0s Setpoints=1 Overshoot=0 SettlingTime=0s Settled=0 Reversals=0 AveragePower=5")
+	assert.Contains(t, lines, "# Setpoint brew: Step=0 Target=20 Overshoot=0 SettlingTime=never")
+	assert.Equal(t, "Time,Step,Span,TargetTemp,CurrentTemp,SG,PID,Power,ApparentAttenuation,RealAttenuation,ABV,ABVAlternate", data[0])
+	start := b.Config.BrewingStartTime
+	assert.Equal(t, start.Add(time.Second).Format(time.RFC3339)+",1,1,20,12.5,,10,5,,,,", data[1])
+	assert.Equal(t, start.Add(2*time.Second).Format(time.RFC3339)+",2,1,20,12.5625,1.05,10,5,16.67,13.08,1.31,1.41", data[2])
+
+	assert.Error(t, b.Write(&out, "xls"))
+}
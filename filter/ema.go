@@ -0,0 +1,37 @@
+package filter
+
+import "math"
+
+// EMA is an exponential moving average. It is ready once it has seen about
+// as many samples as its time constant.
+type EMA struct {
+	alpha  float64
+	warmup int
+	count  int
+	value  float64
+}
+
+func NewEMA(alpha float64) *EMA {
+	return &EMA{alpha: alpha, warmup: int(math.Ceil(1 / alpha))}
+}
+
+func (f *EMA) Add(x float64) {
+	if f.count == 0 {
+		f.value = x
+	} else {
+		f.value += f.alpha * (x - f.value)
+	}
+	f.count++
+}
+
+func (f *EMA) Ready() bool {
+	return f.count >= f.warmup
+}
+
+func (f *EMA) Value() float64 {
+	return f.value
+}
+
+func (f *EMA) Reset() {
+	f.count = 0
+}
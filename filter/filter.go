@@ -0,0 +1,79 @@
+// Package filter smooths raw sensor readings before they are published as
+// filtered samples.
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Filter interface {
+	Add(x float64)
+	// Ready reports whether enough samples were added for Value to be used.
+	Ready() bool
+	Value() float64
+	// Reset forgets the samples added so far, e.g. after a sensor swap.
+	Reset()
+}
+
+// New builds a filter from a spec of the form "kind:param,param":
+//
+//	trimmed:window,sample  mean of the sample values around the window median
+//	ema:alpha              exponential moving average
+//	sg:window,order        Savitzky-Golay smoothing of the latest value
+//	kalman:q,r             1-D Kalman filter with process noise q and
+//	                       measurement noise r
+func New(spec string) (Filter, error) {
+	kind, params, err := parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case "trimmed":
+		if len(params) != 2 || params[0] < 1 || params[1] < 1 || params[1] > params[0] {
+			return nil, fmt.Errorf("filter %q: want trimmed:window,sample with 1 <= sample <= window", spec)
+		}
+		return NewTrimmed(int(params[0]), int(params[1])), nil
+	case "ema":
+		if len(params) != 1 || params[0] <= 0 || params[0] > 1 {
+			return nil, fmt.Errorf("filter %q: want ema:alpha with 0 < alpha <= 1", spec)
+		}
+		return NewEMA(params[0]), nil
+	case "sg":
+		if len(params) != 2 || params[1] < 0 || params[0] < params[1]+1 {
+			return nil, fmt.Errorf("filter %q: want sg:window,order with window > order", spec)
+		}
+		return NewSavitzkyGolay(int(params[0]), int(params[1])), nil
+	case "kalman":
+		if len(params) != 2 || params[0] <= 0 || params[1] <= 0 {
+			return nil, fmt.Errorf("filter %q: want kalman:q,r with q, r > 0", spec)
+		}
+		return NewKalman(params[0], params[1]), nil
+	}
+	return nil, fmt.Errorf("filter %q: unknown kind %q", spec, kind)
+}
+
+// MustNew is New for specs known at compile time.
+func MustNew(spec string) Filter {
+	f, err := New(spec)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func parse(spec string) (string, []float64, error) {
+	kind, rest, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	var params []float64
+	if rest != "" {
+		for _, s := range strings.Split(rest, ",") {
+			x, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return "", nil, fmt.Errorf("filter %q: %v", spec, err)
+			}
+			params = append(params, x)
+		}
+	}
+	return strings.ToLower(kind), params, nil
+}
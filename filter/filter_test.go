@@ -0,0 +1,112 @@
+package filter
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zlowred/alcobot/avg"
+)
+
+func TestNew(t *testing.T) {
+	for _, spec := range []string{"trimmed:100,20", "ema:0.1", "sg:31,2", "kalman:0.01,16", " SG: 5 , 1 "} {
+		f, err := New(spec)
+		assert.NoError(t, err, spec)
+		assert.NotNil(t, f, spec)
+	}
+	for _, spec := range []string{"", "median:5", "trimmed:10", "trimmed:10,20", "ema:0", "ema:2", "sg:3,3", "kalman:0,1", "ema:x"} {
+		_, err := New(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestTrimmedMatchesAvg(t *testing.T) {
+	f := NewTrimmed(30, 10)
+	a := avg.NewAvg(30, 10)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		x := int16(r.Intn(1000))
+		f.Add(float64(x))
+		a.Add(x)
+		assert.Equal(t, a.Ready, f.Ready())
+		if a.Ready {
+			assert.Equal(t, float64(a.Average()), f.Value())
+		}
+	}
+	f.Reset()
+	assert.False(t, f.Ready())
+}
+
+func TestEMA(t *testing.T) {
+	f := NewEMA(0.25)
+	f.Add(100)
+	assert.False(t, f.Ready())
+	assert.Equal(t, 100., f.Value())
+	f.Add(200)
+	assert.Equal(t, 125., f.Value())
+	f.Add(200)
+	f.Add(200)
+	assert.True(t, f.Ready())
+	f.Reset()
+	assert.False(t, f.Ready())
+	f.Add(10)
+	assert.Equal(t, 10., f.Value())
+}
+
+func TestSavitzkyGolayFollowsPolynomial(t *testing.T) {
+	f := NewSavitzkyGolay(21, 2)
+	p := func(x float64) float64 { return 3 + 0.5*x - 0.02*x*x }
+	for i := 0; i < 20; i++ {
+		f.Add(p(float64(i)))
+		assert.False(t, f.Ready())
+	}
+	for i := 20; i < 60; i++ {
+		f.Add(p(float64(i)))
+		assert.True(t, f.Ready())
+		assert.InDelta(t, p(float64(i)), f.Value(), 1e-9)
+	}
+}
+
+func TestSavitzkyGolayWeightsSumToOne(t *testing.T) {
+	for _, w := range [][]int{{5, 0}, {5, 1}, {31, 2}, {101, 3}} {
+		var sum float64
+		for _, x := range sgWeights(w[0], w[1]) {
+			sum += x
+		}
+		assert.InDelta(t, 1, sum, 1e-9, "%v", w)
+	}
+	assert.InDeltaSlice(t, []float64{.2, .2, .2, .2, .2}, sgWeights(5, 0), 1e-12)
+}
+
+func TestSavitzkyGolayTracksRampWithoutLag(t *testing.T) {
+	sg := NewSavitzkyGolay(31, 1)
+	trimmed := NewTrimmed(31, 11)
+	for i := 0; i < 100; i++ {
+		sg.Add(float64(i))
+		trimmed.Add(float64(i))
+	}
+	assert.InDelta(t, 99, sg.Value(), 1e-9)
+	assert.InDelta(t, 84, trimmed.Value(), 1)
+}
+
+func TestKalmanReducesNoise(t *testing.T) {
+	f := NewKalman(0.001, 25)
+	r := rand.New(rand.NewSource(1))
+	var raw, filtered float64
+	for i := 0; i < 2000; i++ {
+		z := 1000 + r.NormFloat64()*5
+		f.Add(z)
+		if i >= 1000 {
+			raw += (z - 1000) * (z - 1000)
+			filtered += (f.Value() - 1000) * (f.Value() - 1000)
+		}
+	}
+	assert.True(t, f.Ready())
+	assert.True(t, filtered < raw/10, "filtered %v raw %v", filtered, raw)
+	assert.True(t, f.Variance() < 25)
+	assert.False(t, math.IsNaN(f.Value()))
+	f.Reset()
+	assert.False(t, f.Ready())
+}
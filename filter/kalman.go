@@ -0,0 +1,41 @@
+package filter
+
+// Kalman tracks a slowly wandering value: q is how much the true value may
+// move between samples and r is the variance of a single reading.
+type Kalman struct {
+	q, r  float64
+	x, p  float64
+	ready bool
+}
+
+func NewKalman(q float64, r float64) *Kalman {
+	return &Kalman{q: q, r: r}
+}
+
+func (f *Kalman) Add(z float64) {
+	if !f.ready {
+		f.x, f.p, f.ready = z, f.r, true
+		return
+	}
+	f.p += f.q
+	k := f.p / (f.p + f.r)
+	f.x += k * (z - f.x)
+	f.p *= 1 - k
+}
+
+func (f *Kalman) Ready() bool {
+	return f.ready
+}
+
+func (f *Kalman) Value() float64 {
+	return f.x
+}
+
+// Variance is the estimated variance of Value.
+func (f *Kalman) Variance() float64 {
+	return f.p
+}
+
+func (f *Kalman) Reset() {
+	f.ready = false
+}
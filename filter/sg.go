@@ -0,0 +1,108 @@
+package filter
+
+// SavitzkyGolay fits a polynomial of the given order to the last window
+// samples and returns the fit at the newest one. Unlike a centred window it
+// adds no lag for signals the polynomial can follow.
+type SavitzkyGolay struct {
+	weights []float64
+	buf     []float64
+	ptr     int
+	count   int
+}
+
+func NewSavitzkyGolay(window int, order int) *SavitzkyGolay {
+	return &SavitzkyGolay{weights: sgWeights(window, order), buf: make([]float64, window)}
+}
+
+func (f *SavitzkyGolay) Add(x float64) {
+	f.buf[f.ptr] = x
+	f.ptr = (f.ptr + 1) % len(f.buf)
+	f.count++
+}
+
+func (f *SavitzkyGolay) Ready() bool {
+	return f.count >= len(f.buf)
+}
+
+func (f *SavitzkyGolay) Value() float64 {
+	var sum float64
+	for i, w := range f.weights {
+		// weights[0] belongs to the oldest sample, which sits at ptr
+		sum += w * f.buf[(f.ptr+i)%len(f.buf)]
+	}
+	return sum
+}
+
+func (f *SavitzkyGolay) Reset() {
+	f.count = 0
+}
+
+// sgWeights returns the least-squares weights that evaluate the fitted
+// polynomial at the newest of window samples, oldest sample first.
+func sgWeights(window int, order int) []float64 {
+	n := order + 1
+	// positions are scaled to [-1, 0] to keep the normal equations well
+	// conditioned for long windows
+	t := make([]float64, window)
+	for i := range t {
+		if window > 1 {
+			t[i] = float64(i-window+1) / float64(window-1)
+		}
+	}
+	pow := func(x float64, k int) float64 {
+		r := 1.
+		for ; k > 0; k-- {
+			r *= x
+		}
+		return r
+	}
+
+	// augmented [AᵀA | I], inverted with Gauss-Jordan
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, 2*n)
+		for j := 0; j < n; j++ {
+			for _, x := range t {
+				m[i][j] += pow(x, i+j)
+			}
+		}
+		m[i][n+i] = 1
+	}
+	for c := 0; c < n; c++ {
+		p := c
+		for r := c + 1; r < n; r++ {
+			if abs(m[r][c]) > abs(m[p][c]) {
+				p = r
+			}
+		}
+		m[c], m[p] = m[p], m[c]
+		d := m[c][c]
+		for j := range m[c] {
+			m[c][j] /= d
+		}
+		for r := 0; r < n; r++ {
+			if r != c && m[r][c] != 0 {
+				k := m[r][c]
+				for j := range m[r] {
+					m[r][j] -= k * m[c][j]
+				}
+			}
+		}
+	}
+
+	// the fit at t = 0 is the constant coefficient: row 0 of (AᵀA)⁻¹Aᵀ
+	w := make([]float64, window)
+	for i, x := range t {
+		for j := 0; j < n; j++ {
+			w[i] += m[0][n+j] * pow(x, j)
+		}
+	}
+	return w
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
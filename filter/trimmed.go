@@ -0,0 +1,33 @@
+package filter
+
+import (
+	"math"
+
+	"github.com/zlowred/alcobot/avg"
+)
+
+// Trimmed is the median-trimmed mean of avg.Avg: the average of the sample
+// values nearest the median of the last window readings.
+type Trimmed struct {
+	avg *avg.Avg
+}
+
+func NewTrimmed(window int, sample int) *Trimmed {
+	return &Trimmed{avg.NewAvg(window, sample)}
+}
+
+func (f *Trimmed) Add(x float64) {
+	f.avg.Add(int16(math.Round(x)))
+}
+
+func (f *Trimmed) Ready() bool {
+	return f.avg.Ready
+}
+
+func (f *Trimmed) Value() float64 {
+	return float64(f.avg.Average())
+}
+
+func (f *Trimmed) Reset() {
+	f.avg.ResetCounter()
+}
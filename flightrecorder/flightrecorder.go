@@ -1,16 +1,30 @@
 package flightrecorder
 
 import (
+	"context"
 	"log"
 
 	"math"
 	"time"
 
 	"github.com/zlowred/alcobot/config"
-	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/debuglog"
+	"github.com/zlowred/alcobot/gravity"
 	"github.com/zlowred/alcobot/hub"
 )
 
+// staleAfter is how old the latest temperature or pressure sample may get
+// before it is recorded as missing rather than repeated.
+const staleAfter = time.Second * 10
+
+// DefaultFlushInterval is how often buffered data points are committed.
+const DefaultFlushInterval = time.Second * 30
+
+// maxBuffered bounds the data points kept while the database refuses
+// writes or the previous batch is still being written; older ones are
+// dropped first.
+const maxBuffered = 3600
+
 type FlightRecorder struct {
 	hub         *hub.Hub
 	step        int
@@ -18,45 +32,83 @@ type FlightRecorder struct {
 	sg          float64
 	pid         float64
 	power       float64
+	tempTime    time.Time
+	sgTime      time.Time
+	comp        *gravity.Compensator
 	conf        *config.Configuration
+
+	flushInterval time.Duration
+	buffer        []*hub.DataPoint
 }
 
-func New(hub *hub.Hub) {
-	r := &FlightRecorder{hub: hub, step: 0, currentTemp: math.NaN(), sg: math.NaN(), pid: math.NaN(), power: math.NaN()}
-	go r.loop()
+// New creates a recorder that commits the recorded data points every
+// flushInterval.
+func New(hub *hub.Hub, flushInterval time.Duration) *FlightRecorder {
+	return &FlightRecorder{hub: hub, step: 0, currentTemp: math.NaN(), sg: math.NaN(), pid: math.NaN(), power: math.NaN(), comp: gravity.NewCompensator(), flushInterval: flushInterval}
 }
 
-func (r *FlightRecorder) loop() {
+// Run records a data point every second until ctx is done. The points are
+// published right away and written in batches, on every flushInterval, on a
+// stage change and before returning. The batches are written on their own
+// goroutine so a busy database doesn't hold up the subscriptions.
+func (r *FlightRecorder) Run(ctx context.Context) {
+	batches := make(chan []*hub.DataPoint, 1)
+	written := make(chan struct{})
+	go r.write(batches, written)
+	defer func() {
+		if len(r.buffer) > 0 {
+			batches <- r.buffer
+		}
+		close(batches)
+		<-written
+	}()
 
-	configCh := hub.JoinConfigGroup(r.hub.Configuration)
-	currentTempCh := hub.JoinInt16Group(r.hub.DsTemperatureFiltered)
-	pressureCh := hub.JoinInt16Group(r.hub.NpaPressureFiltered)
-	pidCh := hub.JoinFloat64Group(r.hub.PidOutput)
-	powerCh := hub.JoinFloat64Group(r.hub.AdjustedPidOutput)
-	dpCh := hub.JoinDataPointGroup(r.hub.DataPoints)
+	configCh := r.hub.Configuration.JoinContext(ctx)
+	currentTempCh := r.hub.DsTemperatureFiltered.JoinContext(ctx)
+	pressureCh := r.hub.NpaPressureFiltered.JoinContext(ctx)
+	dieCh := r.hub.NpaTemperatureFiltered.JoinContext(ctx)
+	pidCh := r.hub.PidOutput.JoinContext(ctx)
+	powerCh := r.hub.AdjustedPidOutput.JoinContext(ctx)
+	dpCh := r.hub.DataPoints.JoinContext(ctx)
 
 	timer := time.NewTimer(time.Second)
 	timer.Stop()
+	defer timer.Stop()
+	flush := time.NewTicker(r.flushInterval)
+	defer flush.Stop()
 
 	for {
 		select {
 		case <-r.hub.FlightRecorderLock:
-			log.Println("Enabling flight recorder")
+			debuglog.Println("Enabling flight recorder")
 			timer.Reset(time.Millisecond * time.Duration(1000-int(time.Now().Nanosecond())/1000000))
 		case x := <-dpCh:
 			r.step = x.Step
-		case <-r.hub.Quit:
+		case <-ctx.Done():
 			return
+		case <-flush.C:
+			r.flush(batches)
 		case x := <-configCh:
+			if r.conf != nil && (r.conf.Id != x.Id || r.conf.Stage != x.Stage) {
+				r.flush(batches)
+			}
+			if r.conf != nil && r.conf.Id != x.Id {
+				// a new brew starts recording from scratch
+				r.step = 0
+			}
 			r.conf = x
 		case x := <-currentTempCh:
-			r.currentTemp = float64(x)
+			r.currentTemp = float64(x.Value)
+			r.tempTime = x.Time
+			r.comp.Wort(x.Value)
+		case x := <-dieCh:
+			r.comp.Die(x.Value)
 		case x := <-pressureCh:
 			if r.conf == nil {
 				continue
 			}
-			pres := conv.NpaToPa(x, r.conf.NpaZero, r.conf.NpaMinValue, r.conf.NpaMaxValue, r.conf.NpaMinPressure, r.conf.NpaMaxPressure)
-			r.sg = conv.PaToSg(pres, r.conf.NpaCalibration)
+			r.sg = r.comp.SG(r.conf, x.Value)
+			r.sgTime = x.Time
 		case x := <-pidCh:
 			r.pid = x / 2.55
 		case x := <-powerCh:
@@ -64,16 +116,62 @@ func (r *FlightRecorder) loop() {
 		case <-timer.C:
 			if r.conf != nil && r.conf.Stage == config.PREPARATION {
 				r.step++
-				dp := &hub.DataPoint{r.conf.Id, r.step, r.conf.TargetTemperature, r.currentTemp, math.NaN(), r.pid, r.power}
-				r.hub.SaveDataPoint(dp)
-				r.hub.DataPoints.Send(dp)
+				dp := &hub.DataPoint{Id: r.conf.Id, Step: r.step, TargetTemp: r.conf.TargetTemperature, CurrentTemp: fresh(r.currentTemp, r.tempTime), SG: math.NaN(), PID: r.pid, Power: r.power, Span: 1, Alcohol: hub.NewAlcohol(r.conf.OG, math.NaN())}
+				r.record(dp)
 			} else if r.conf != nil && r.conf.Stage == config.BREWING {
 				r.step++
-				dp := &hub.DataPoint{r.conf.Id, r.step, r.conf.TargetTemperature, r.currentTemp, r.sg, r.pid, r.power}
-				r.hub.SaveDataPoint(dp)
-				r.hub.DataPoints.Send(dp)
+				sg := fresh(r.sg, r.sgTime)
+				dp := &hub.DataPoint{Id: r.conf.Id, Step: r.step, TargetTemp: r.conf.TargetTemperature, CurrentTemp: fresh(r.currentTemp, r.tempTime), SG: sg, PID: r.pid, Power: r.power, Span: 1, Alcohol: hub.NewAlcohol(r.conf.OG, sg)}
+				r.record(dp)
 			}
 			timer.Reset(time.Millisecond * time.Duration(1000-int(time.Now().Nanosecond())/1000000))
 		}
 	}
 }
+
+func (r *FlightRecorder) record(dp *hub.DataPoint) {
+	r.buffer = append(r.buffer, dp)
+	if dropped := len(r.buffer) - maxBuffered; dropped > 0 {
+		r.buffer = r.buffer[dropped:]
+	}
+	r.hub.DataPoints.Send(dp)
+}
+
+// flush hands the buffered data points to the writer. While it is still
+// busy with the previous batch they stay buffered for the next flush.
+func (r *FlightRecorder) flush(batches chan<- []*hub.DataPoint) {
+	if len(r.buffer) == 0 {
+		return
+	}
+	select {
+	case batches <- r.buffer:
+		r.buffer = nil
+	default:
+	}
+}
+
+// write commits the batches until they are closed. On failure the data
+// points are kept and written with the next batch.
+func (r *FlightRecorder) write(batches <-chan []*hub.DataPoint, written chan<- struct{}) {
+	defer close(written)
+	var unsaved []*hub.DataPoint
+	for batch := range batches {
+		unsaved = append(unsaved, batch...)
+		if err := r.hub.SaveDataPoints(unsaved); err != nil {
+			log.Printf("Can't save %d data points: %v\n", len(unsaved), err)
+			if dropped := len(unsaved) - maxBuffered; dropped > 0 {
+				log.Printf("Dropping %d oldest unsaved data points\n", dropped)
+				unsaved = append([]*hub.DataPoint(nil), unsaved[dropped:]...)
+			}
+			continue
+		}
+		unsaved = nil
+	}
+}
+
+func fresh(value float64, t time.Time) float64 {
+	if time.Since(t) > staleAfter {
+		return math.NaN()
+	}
+	return value
+}
@@ -0,0 +1,73 @@
+package gravity
+
+import (
+	"math"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+)
+
+// ReferenceTemperature is the temperature in ºC the compensated pressure is
+// scaled to.
+const ReferenceTemperature = 20.
+
+// Compensator turns the NPA pressure readings into SG, compensated for the
+// temperatures it was last given. The pressure of the wort column follows
+// its density, which falls as it warms the way water does, so the pressure
+// is scaled to what it would be at ReferenceTemperature by the density of
+// water at 20ºC over the one at the wort temperature. The calibration is
+// scaled the same way, by the density at the calibration temperature over
+// the one at 20ºC, so the SG is that of the wort against water, both at
+// 20ºC. The NPA offset drifts with its die temperature by NpaDrift Pa/ºC,
+// which is taken off the pressure.
+type Compensator struct {
+	wort float64
+	die  float64
+}
+
+func NewCompensator() *Compensator {
+	return &Compensator{wort: math.NaN(), die: math.NaN()}
+}
+
+// Wort takes the raw DS18B20 reading of the wort temperature.
+func (c *Compensator) Wort(raw int16) {
+	c.wort = conv.DsToC(raw)
+}
+
+// Die takes the raw NPA temperature reading.
+func (c *Compensator) Die(raw int16) {
+	c.die = conv.NpaToC(raw)
+}
+
+// SG is the SG of the raw NPA pressure reading under conf. Each
+// compensation is left out until its temperature is known, both the current
+// one and the one at calibration.
+func (c *Compensator) SG(conf *config.Configuration, raw int16) float64 {
+	return SG(conf, raw, c.wort, c.die)
+}
+
+// Pressure is the compensated pressure of the raw NPA pressure reading
+// under conf, with the wort temperature it's compensated for.
+func (c *Compensator) Pressure(conf *config.Configuration, raw int16) (float64, float64) {
+	return Pressure(conf, raw, c.wort, c.die), c.wort
+}
+
+// SG is the SG of the raw NPA pressure reading under conf at the wort and
+// NPA die temperatures in ºC, NaN when unknown.
+func SG(conf *config.Configuration, raw int16, wort, die float64) float64 {
+	return FromPressure(conf, Pressure(conf, raw, wort, die))
+}
+
+// Pressure is the pressure of the raw NPA pressure reading under conf, less
+// the NPA drift and, once the calibration has a temperature, scaled from
+// the wort temperature to ReferenceTemperature.
+func Pressure(conf *config.Configuration, raw int16, wort, die float64) float64 {
+	pa := conv.NpaToPa(raw, conf.NpaZero, conf.NpaMinValue, conf.NpaMaxValue, conf.NpaMinPressure, conf.NpaMaxPressure)
+	if conf.NpaDrift != 0 && conf.NpaReferenceTemperature != 0 && !math.IsNaN(die) {
+		pa -= conf.NpaDrift * (die - conf.NpaReferenceTemperature)
+	}
+	if (conf.NpaCalibrationTemperature != 0 || Fitted(conf)) && !math.IsNaN(wort) {
+		pa *= conv.WaterDensity(ReferenceTemperature) / conv.WaterDensity(wort)
+	}
+	return pa
+}
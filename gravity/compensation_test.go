@@ -0,0 +1,58 @@
+package gravity
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+)
+
+func TestWaterDensity(t *testing.T) {
+	assert.InDelta(t, 999.97, conv.WaterDensity(4), 0.01)
+	assert.InDelta(t, 998.21, conv.WaterDensity(20), 0.01)
+	assert.InDelta(t, 995.65, conv.WaterDensity(30), 0.01)
+}
+
+func TestSG(t *testing.T) {
+	// a linear sensor reading 1 Pa a count
+	conf := &config.Configuration{NpaMinValue: 0, NpaMaxValue: 10000, NpaMinPressure: 0, NpaMaxPressure: 10000, NpaCalibration: 100}
+	uncompensated := SG(conf, 1050, math.NaN(), math.NaN())
+	assert.InDelta(t, conv.PaToSg(1050, 100), uncompensated, 1e-12)
+	assert.Equal(t, uncompensated, SG(conf, 1050, 30, 40), "no compensation without calibration temperatures")
+
+	conf.NpaCalibrationTemperature = 20
+	assert.Equal(t, uncompensated, SG(conf, 1050, 20, math.NaN()))
+	// warm wort is lighter, so the same pressure is a higher SG
+	assert.InDelta(t, uncompensated*998.21/995.65, SG(conf, 1050, 30, math.NaN()), 1e-5)
+
+	conf.NpaReferenceTemperature, conf.NpaDrift = 25, 2
+	assert.Equal(t, uncompensated, SG(conf, 1050, 20, 25))
+	// 5ºC warmer, the offset drifted by 10 Pa
+	assert.InDelta(t, conv.PaToSg(1050, 100), SG(conf, 1060, 20, 30), 1e-12)
+
+	c := NewCompensator()
+	assert.Equal(t, SG(conf, 1060, math.NaN(), math.NaN()), c.SG(conf, 1060))
+	c.Wort(20 * 16)
+	// 25ºC on the NPA
+	c.Die(768)
+	assert.Equal(t, SG(conf, 1060, 20, 25), c.SG(conf, 1060))
+}
+
+func TestFitDrift(t *testing.T) {
+	var die, pa []float64
+	for i := 0; i < 60; i++ {
+		d := 20 + float64(i)/40
+		die = append(die, d)
+		pa = append(pa, 100-1.5*d+0.1*math.Sin(float64(i)))
+	}
+	drift, err := fitDrift(die, pa)
+	assert.NoError(t, err)
+	assert.InDelta(t, -1.5, drift, 0.1)
+
+	_, err = fitDrift(die[:10], pa[:10])
+	assert.Error(t, err, "too few readings")
+	_, err = fitDrift(die[:30], pa[:30])
+	assert.Error(t, err, "the temperature barely moved")
+}
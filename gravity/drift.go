@@ -0,0 +1,78 @@
+package gravity
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+)
+
+const (
+	// minDriftSpan is how far the die temperature has to move while the
+	// drift is learned, in ºC.
+	minDriftSpan = 1.
+	// minDriftSamples is how many pressure readings the drift is fitted to
+	// at least.
+	minDriftSamples = 30
+)
+
+// LearnDrift learns how the NPA offset drifts with its die temperature,
+// in Pa/ºC under conf. It collects the filtered pressure against the die
+// temperature until ctx is done and fits a line through them. Meanwhile the
+// pressure on the probe must stay the same, best with the probe out of the
+// liquid, while the die temperature changes, e.g. as the bot warms up after
+// it's switched on.
+func LearnDrift(ctx context.Context, h *hub.Hub, conf *config.Configuration) (float64, error) {
+	tempCh := h.NpaTemperatureFiltered.JoinContext(ctx)
+	pressureCh := h.NpaPressureFiltered.JoinContext(ctx)
+
+	var die []float64
+	var pa []float64
+	temp := math.NaN()
+	for {
+		select {
+		case <-ctx.Done():
+			return fitDrift(die, pa)
+		case x := <-tempCh:
+			temp = conv.NpaToC(x.Value)
+		case x := <-pressureCh:
+			if math.IsNaN(temp) {
+				continue
+			}
+			die = append(die, temp)
+			pa = append(pa, conv.NpaToPa(x.Value, conf.NpaZero, conf.NpaMinValue, conf.NpaMaxValue, conf.NpaMinPressure, conf.NpaMaxPressure))
+		}
+	}
+}
+
+// fitDrift is the least squares slope of pa over die.
+func fitDrift(die, pa []float64) (float64, error) {
+	if len(die) < minDriftSamples {
+		return 0, fmt.Errorf("only %d readings, need %d", len(die), minDriftSamples)
+	}
+	low, high := die[0], die[0]
+	var sx, sy float64
+	for i := range die {
+		sx, sy = sx+die[i], sy+pa[i]
+		if die[i] < low {
+			low = die[i]
+		}
+		if die[i] > high {
+			high = die[i]
+		}
+	}
+	if high-low < minDriftSpan {
+		return 0, fmt.Errorf("the NPA temperature only moved by %.2fºC, need %.1fºC", high-low, minDriftSpan)
+	}
+	n := float64(len(die))
+	mx, my := sx/n, sy/n
+	var sxy, sxx float64
+	for i := range die {
+		sxy += (die[i] - mx) * (pa[i] - my)
+		sxx += (die[i] - mx) * (die[i] - mx)
+	}
+	return sxy / sxx, nil
+}
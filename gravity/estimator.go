@@ -0,0 +1,122 @@
+// Package gravity estimates specific gravity and the fermentation rate from
+// the hydrostatic pressure readings.
+package gravity
+
+import (
+	"math"
+	"time"
+
+	"github.com/zlowred/alcobot/hub"
+)
+
+const (
+	// z95 turns a standard deviation into a 95% confidence half-width.
+	z95 = 1.96
+	// point is one gravity point in SG.
+	point = 0.001
+	day   = 24 * 60 * 60
+	// initialRate is the standard deviation of the rate before any
+	// readings, in points per day.
+	initialRate = 50.
+	// tempRateScale is how fast the temperature may change, in ºC per
+	// hour, before convection doubles the reading noise.
+	tempRateScale = 0.5
+)
+
+// Estimator is a two-state Kalman filter tracking SG and its rate of change
+// under a constant-rate model. Changes of the rate are modelled as white
+// noise, so the rate is allowed to drift over the course of a fermentation.
+type Estimator struct {
+	r float64 // variance of a single SG reading
+	q float64 // spectral density of rate changes, SG²/s³
+
+	x     [2]float64 // SG, SG per second
+	p     [2][2]float64
+	t     time.Time
+	ready bool
+
+	temp      float64
+	tempRate  float64 // ºC per hour, smoothed
+	tempTime  time.Time
+	tempReady bool
+}
+
+// NewEstimator takes the standard deviation of a single SG reading and how
+// many points per day the rate may plausibly change in a day.
+func NewEstimator(noise float64, drift float64) *Estimator {
+	v := drift * point / day
+	return &Estimator{r: noise * noise, q: v * v / day}
+}
+
+// Update adds an SG reading taken at t.
+func (e *Estimator) Update(sg float64, t time.Time) {
+	if math.IsNaN(sg) || math.IsInf(sg, 0) {
+		return
+	}
+	if !e.ready {
+		v := initialRate * point / day
+		e.x = [2]float64{sg, 0}
+		e.p = [2][2]float64{{e.r, 0}, {0, v * v}}
+		e.t, e.ready = t, true
+		return
+	}
+
+	dt := t.Sub(e.t).Seconds()
+	if dt < 0 {
+		dt = 0
+	}
+	e.t = t
+
+	// predict
+	e.x[0] += dt * e.x[1]
+	p00 := e.p[0][0] + 2*dt*e.p[0][1] + dt*dt*e.p[1][1] + e.q*dt*dt*dt/3
+	p01 := e.p[0][1] + dt*e.p[1][1] + e.q*dt*dt/2
+	p11 := e.p[1][1] + e.q*dt
+
+	// correct
+	r := e.r * (1 + math.Abs(e.tempRate)/tempRateScale)
+	s := p00 + r
+	k0, k1 := p00/s, p01/s
+	y := sg - e.x[0]
+	e.x[0] += k0 * y
+	e.x[1] += k1 * y
+	e.p = [2][2]float64{
+		{(1 - k0) * p00, (1 - k0) * p01},
+		{(1 - k0) * p01, p11 - k1*p01},
+	}
+}
+
+// UpdateTemperature adds a fermenter temperature reading in ºC. While the
+// temperature changes, convection disturbs the pressure readings, so they
+// are trusted less.
+func (e *Estimator) UpdateTemperature(c float64, t time.Time) {
+	if !e.tempReady {
+		e.temp, e.tempTime, e.tempReady = c, t, true
+		return
+	}
+	dt := t.Sub(e.tempTime).Hours()
+	if dt <= 0 {
+		return
+	}
+	e.tempRate += 0.1 * ((c-e.temp)/dt - e.tempRate)
+	e.temp, e.tempTime = c, t
+}
+
+func (e *Estimator) Ready() bool {
+	return e.ready
+}
+
+func (e *Estimator) Reset() {
+	e.ready = false
+}
+
+func (e *Estimator) Estimate() hub.Gravity {
+	sg := z95 * math.Sqrt(math.Max(e.p[0][0], 0))
+	rate := e.x[1] * day / point
+	rateBand := z95 * math.Sqrt(math.Max(e.p[1][1], 0)) * day / point
+	return hub.Gravity{
+		SG: e.x[0], SGLow: e.x[0] - sg, SGHigh: e.x[0] + sg,
+		Rate: rate, RateLow: rate - rateBand, RateHigh: rate + rateBand,
+		Time: e.t,
+	}
+}
@@ -0,0 +1,68 @@
+package gravity
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimatorTracksFermentation(t *testing.T) {
+	e := NewEstimator(0.001, 20)
+	r := rand.New(rand.NewSource(1))
+	start := time.Unix(0, 0)
+	// 1.050 dropping 10 points a day, one noisy reading a minute for a day
+	for i := 0; i <= 24*60; i++ {
+		now := start.Add(time.Minute * time.Duration(i))
+		truth := 1.050 - 0.010*now.Sub(start).Hours()/24
+		e.Update(truth+r.NormFloat64()*0.001, now)
+	}
+	g := e.Estimate()
+	assert.InDelta(t, 1.040, g.SG, 0.0005)
+	assert.True(t, g.SGLow < 1.040 && 1.040 < g.SGHigh, "%+v", g)
+	assert.True(t, g.SGHigh-g.SG < 0.001)
+	assert.InDelta(t, -10, g.Rate, 3)
+	assert.True(t, g.RateLow < -10 && -10 < g.RateHigh, "%+v", g)
+	assert.Equal(t, start.Add(time.Hour*24), g.Time)
+}
+
+func TestEstimatorStartsUncertain(t *testing.T) {
+	e := NewEstimator(0.001, 20)
+	assert.False(t, e.Ready())
+	e.Update(1.050, time.Unix(0, 0))
+	assert.True(t, e.Ready())
+	g := e.Estimate()
+	assert.Equal(t, 1.050, g.SG)
+	assert.Equal(t, 0., g.Rate)
+	assert.InDelta(t, 1.96*50, g.RateHigh, 1e-9)
+	e.Reset()
+	assert.False(t, e.Ready())
+}
+
+func TestEstimatorTrustsReadingsLessWhileTemperatureChanges(t *testing.T) {
+	steady := NewEstimator(0.001, 20)
+	changing := NewEstimator(0.001, 20)
+	start := time.Unix(0, 0)
+	for i := 0; i < 60; i++ {
+		now := start.Add(time.Minute * time.Duration(i))
+		changing.UpdateTemperature(20+float64(i)*0.05, now)
+		steady.UpdateTemperature(20, now)
+		steady.Update(1.050, now)
+		changing.Update(1.050, now)
+	}
+	now := start.Add(time.Hour)
+	steady.Update(1.060, now)
+	changing.Update(1.060, now)
+	assert.True(t, changing.Estimate().SG < steady.Estimate().SG)
+}
+
+func TestEstimatorIgnoresInvalidReadings(t *testing.T) {
+	e := NewEstimator(0.001, 20)
+	e.Update(1/zero(), time.Unix(0, 0))
+	assert.False(t, e.Ready())
+}
+
+func zero() float64 {
+	return 0
+}
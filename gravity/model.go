@@ -0,0 +1,242 @@
+package gravity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+)
+
+// Fitted reports whether conf has an SG model fitted to calibration points
+// rather than the single point NpaCalibration.
+func Fitted(conf *config.Configuration) bool {
+	return conf.SgModel[1] != 0
+}
+
+// Calibrated reports whether the pressure can be turned into SG under conf.
+func Calibrated(conf *config.Configuration) bool {
+	return Fitted(conf) || conf.NpaCalibration != 0
+}
+
+// FromPressure is the SG of the pressure p, compensated to
+// ReferenceTemperature, under conf. A fitted model takes p as it is; the
+// single point calibration was taken at NpaCalibrationTemperature, so its
+// SG is scaled by the density of water then over the one at 20ºC.
+func FromPressure(conf *config.Configuration, p float64) float64 {
+	if Fitted(conf) {
+		m := conf.SgModel
+		return m[0] + m[1]*p + m[2]*p*p
+	}
+	sg := conv.PaToSg(p, conf.NpaCalibration)
+	if conf.NpaCalibrationTemperature != 0 {
+		sg *= conv.WaterDensity(conf.NpaCalibrationTemperature) / conv.WaterDensity(ReferenceTemperature)
+	}
+	return sg
+}
+
+// ToPressure is the pressure of sg compensated to ReferenceTemperature
+// under conf, the inverse of FromPressure: the density ratio of the single
+// point calibration is undone before it is turned into pressure.
+func ToPressure(conf *config.Configuration, sg float64) float64 {
+	if !Fitted(conf) {
+		if conf.NpaCalibrationTemperature != 0 {
+			sg *= conv.WaterDensity(ReferenceTemperature) / conv.WaterDensity(conf.NpaCalibrationTemperature)
+		}
+		return conv.SgToPa(sg, conf.NpaCalibration)
+	}
+	m := conf.SgModel
+	linear := (sg - m[0]) / m[1]
+	if m[2] == 0 {
+		return linear
+	}
+	// the root of the parabola closer to the linear part of the model
+	d := math.Sqrt(m[1]*m[1] - 4*m[2]*(m[0]-sg))
+	a, b := (-m[1]+d)/(2*m[2]), (-m[1]-d)/(2*m[2])
+	if math.Abs(a-linear) < math.Abs(b-linear) {
+		return a
+	}
+	return b
+}
+
+// recordedPressure is the compensated pressure sg was recorded from under
+// conf at the wort temperature in ºC. A single point calibration without a
+// temperature left the pressure uncompensated, so it's compensated here when
+// the temperature is known, as Pressure does under a fitted model.
+func recordedPressure(conf *config.Configuration, sg, wort float64) float64 {
+	p := ToPressure(conf, sg)
+	if !Fitted(conf) && conf.NpaCalibrationTemperature == 0 && !math.IsNaN(wort) {
+		p *= conv.WaterDensity(ReferenceTemperature) / conv.WaterDensity(wort)
+	}
+	return p
+}
+
+// Fit fits an SG model of degree 1 or 2 to the calibration points by least
+// squares. It takes one point more than the degree to fit, so the residuals
+// tell how good the fit is.
+func Fit(points []hub.CalibrationPoint, degree int) ([3]float64, error) {
+	if degree != 1 && degree != 2 {
+		return [3]float64{}, fmt.Errorf("invalid degree %d", degree)
+	}
+	if len(points) < degree+2 {
+		return [3]float64{}, fmt.Errorf("%d calibration points, need %d", len(points), degree+2)
+	}
+	// the pressure is scaled to keep the normal equations well conditioned
+	scale := 0.
+	for _, p := range points {
+		scale = math.Max(scale, math.Abs(p.Pressure))
+	}
+	if scale == 0 {
+		return [3]float64{}, errors.New("no pressure in the calibration points")
+	}
+	n := degree + 1
+	var a [3][4]float64
+	for _, p := range points {
+		x := p.Pressure / scale
+		pow := [3]float64{1, x, x * x}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				a[i][j] += pow[i] * pow[j]
+			}
+			a[i][n] += pow[i] * p.SG
+		}
+	}
+	c, err := solve(a, n)
+	if err != nil {
+		return [3]float64{}, err
+	}
+	model := [3]float64{c[0], c[1] / scale, c[2] / (scale * scale)}
+	if model[1] == 0 {
+		return [3]float64{}, errors.New("the SG doesn't follow the pressure")
+	}
+	return model, nil
+}
+
+// solve solves the n linear equations in the augmented matrix a by Gaussian
+// elimination with partial pivoting.
+func solve(a [3][4]float64, n int) ([3]float64, error) {
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return [3]float64{}, errors.New("the calibration points don't pin down the model, they need different pressures")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for r := col + 1; r < n; r++ {
+			f := a[r][col] / a[col][col]
+			for k := col; k <= n; k++ {
+				a[r][k] -= f * a[col][k]
+			}
+		}
+	}
+	var x [3]float64
+	for r := n - 1; r >= 0; r-- {
+		x[r] = a[r][n]
+		for k := r + 1; k < n; k++ {
+			x[r] -= a[r][k] * x[k]
+		}
+		x[r] /= a[r][r]
+	}
+	return x, nil
+}
+
+// Residuals are the differences of the SG of the points from the SG conf
+// gives for their pressures, in gravity points (0.001 SG).
+func Residuals(conf *config.Configuration, points []hub.CalibrationPoint) []float64 {
+	r := make([]float64, len(points))
+	for i, p := range points {
+		r[i] = (p.SG - FromPressure(conf, p.Pressure)) / point
+	}
+	return r
+}
+
+// Refit fits an SG model of degree to the calibration points of brew id and
+// applies it. With history the recorded SG of the brew is corrected from
+// its previous model to the new one. It returns the configuration with the
+// new model.
+func Refit(h *hub.Hub, id, degree int, history bool) (*config.Configuration, error) {
+	points, err := h.CalibrationPoints(id)
+	if err != nil {
+		return nil, err
+	}
+	model, err := Fit(points, degree)
+	if err != nil {
+		return nil, err
+	}
+	old, err := h.SessionConfig(id)
+	if err != nil {
+		return nil, err
+	}
+	fitted := *old
+	fitted.SgModel = model
+
+	var correct func(sg, wort float64) float64
+	if history {
+		if !Calibrated(old) {
+			return nil, fmt.Errorf("brew %d had no calibration to correct its SG from", id)
+		}
+		correct = func(sg, wort float64) float64 {
+			return FromPressure(&fitted, recordedPressure(old, sg, wort))
+		}
+	}
+	if err := h.ApplySgModel(id, model, correct); err != nil {
+		return nil, err
+	}
+	return &fitted, nil
+}
+
+// Calibration is the SG calibration of a brew: its model and the points
+// it's fitted to, with their residuals.
+type Calibration struct {
+	Id     int
+	Fitted bool
+	Model  [3]float64
+	Points []CalibratedPoint
+}
+
+// CalibratedPoint is a calibration point and its residual in gravity
+// points. The temperature is nil when it's unknown.
+type CalibratedPoint struct {
+	Time        time.Time
+	Pressure    float64
+	Temperature *float64
+	SG          float64
+	Source      string
+	Residual    float64
+}
+
+// LoadCalibration loads the calibration of brew id, the current one if id
+// is 0.
+func LoadCalibration(h *hub.Hub, id int) (*Calibration, error) {
+	var conf *config.Configuration
+	var err error
+	if id == 0 {
+		conf, err = h.Config()
+	} else {
+		conf, err = h.SessionConfig(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	points, err := h.CalibrationPoints(conf.Id)
+	if err != nil {
+		return nil, err
+	}
+	c := &Calibration{Id: conf.Id, Fitted: Fitted(conf), Model: conf.SgModel}
+	for i, r := range Residuals(conf, points) {
+		p := points[i]
+		cp := CalibratedPoint{Time: p.Time, Pressure: p.Pressure, SG: p.SG, Source: p.Source, Residual: r}
+		if !math.IsNaN(p.Temperature) {
+			cp.Temperature = &p.Temperature
+		}
+		c.Points = append(c.Points, cp)
+	}
+	return c, nil
+}
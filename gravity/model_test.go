@@ -0,0 +1,128 @@
+package gravity
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+)
+
+func points(model [3]float64, pressures ...float64) []hub.CalibrationPoint {
+	var points []hub.CalibrationPoint
+	for _, p := range pressures {
+		points = append(points, hub.CalibrationPoint{Pressure: p, SG: model[0] + model[1]*p + model[2]*p*p, Temperature: math.NaN()})
+	}
+	return points
+}
+
+func TestFit(t *testing.T) {
+	linear := [3]float64{0.02, 0.00098, 0}
+	model, err := Fit(points(linear, 1000, 1040, 1080), 1)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, linear[:], model[:], 1e-9)
+
+	curved := [3]float64{0.3, 0.0006, 2e-7}
+	model, err = Fit(points(curved, 900, 1000, 1050, 1100), 2)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, curved[:], model[:], 1e-9)
+
+	_, err = Fit(points(linear, 1000, 1040), 1)
+	assert.Error(t, err, "no residual left")
+	_, err = Fit(points(linear, 1000, 1000, 1000), 1)
+	assert.Error(t, err, "the same pressure")
+	_, err = Fit(points(linear, 1000, 1040, 1080), 3)
+	assert.Error(t, err)
+}
+
+func TestPressureRoundTrip(t *testing.T) {
+	single := &config.Configuration{NpaCalibration: 100, NpaCalibrationTemperature: 18}
+	curved := &config.Configuration{SgModel: [3]float64{0.3, 0.0006, 2e-7}}
+	for _, conf := range []*config.Configuration{single, curved} {
+		for _, sg := range []float64{1, 1.012, 1.050, 1.1} {
+			assert.InDelta(t, sg, FromPressure(conf, ToPressure(conf, sg)), 1e-12)
+		}
+	}
+
+	conf := &config.Configuration{SgModel: [3]float64{0.02, 0.00098, 0}}
+	r := Residuals(conf, []hub.CalibrationPoint{{Pressure: 1000, SG: 1.001}, {Pressure: 1040, SG: 1.0392}})
+	assert.InDeltaSlice(t, []float64{1, 0}, r, 1e-9)
+}
+
+func TestRecordedPressure(t *testing.T) {
+	fitted := &config.Configuration{SgModel: [3]float64{0.02, 0.00098, 0}}
+	// a legacy single point calibration recorded the SG of the uncompensated
+	// pressure, which a fitted model gets compensated
+	legacy := &config.Configuration{NpaCalibration: 1000}
+	pa, wort := 1045., 12.
+	compensated := conv.WaterDensity(ReferenceTemperature) / conv.WaterDensity(wort)
+	assert.InDelta(t, pa*compensated, recordedPressure(legacy, FromPressure(legacy, pa), wort), 1e-9)
+	assert.InDelta(t, pa, recordedPressure(legacy, FromPressure(legacy, pa), math.NaN()), 1e-9, "unknown temperature")
+
+	single := &config.Configuration{NpaCalibration: 1000, NpaCalibrationTemperature: 18}
+	assert.InDelta(t, pa, recordedPressure(single, FromPressure(single, pa), wort), 1e-9)
+	assert.InDelta(t, pa, recordedPressure(fitted, FromPressure(fitted, pa), wort), 1e-9)
+}
+
+// running runs the hub on a fresh database until the test ends.
+func running(t *testing.T) *hub.Hub {
+	h, err := hub.Open(filepath.Join(t.TempDir(), "alcobot.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return h
+}
+
+// calibrate records sg at the pressure pa once the hub has it.
+func calibrate(t *testing.T, h *hub.Hub, pa, sg float64) {
+	h.Pressure.Send(hub.Pressure{Pa: pa, Temperature: math.NaN(), Time: time.Now()})
+	assert.Eventually(t, func() bool {
+		p, err := h.AddCalibrationPoint(sg, "solution")
+		if err == nil && p.Pressure != pa {
+			assert.NoError(t, h.DeleteCalibrationPoint(1, p.Time))
+			return false
+		}
+		return err == nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestRefit(t *testing.T) {
+	h := running(t)
+	conf, err := h.Config()
+	assert.NoError(t, err)
+	_, err = Refit(h, conf.Id, 1, false)
+	assert.Error(t, err, "no points")
+
+	calibrate(t, h, 1000, 1.000)
+	calibrate(t, h, 1040, 1.040)
+	calibrate(t, h, 1081, 1.080)
+	fitted, err := Refit(h, conf.Id, 1, false)
+	assert.NoError(t, err)
+	assert.True(t, Fitted(fitted))
+	assert.InDelta(t, 1.060, FromPressure(fitted, 1060), 0.001)
+
+	c, err := LoadCalibration(h, 0)
+	assert.NoError(t, err)
+	assert.True(t, c.Fitted)
+	assert.Len(t, c.Points, 3)
+	assert.Nil(t, c.Points[0].Temperature)
+	assert.InDelta(t, 0, c.Points[1].Residual, 1)
+
+	_, err = Refit(h, conf.Id, 1, true)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,75 @@
+package gravity
+
+import (
+	"context"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+)
+
+const (
+	readingNoise = 0.001
+	rateDrift    = 20.
+)
+
+// Tracker feeds the filtered pressure, compensated for the fermenter and
+// NPA temperatures, and the fermenter temperature into an Estimator and
+// publishes the estimate on the hub's Gravity topic. The compensated
+// pressure goes to the Pressure topic, for calibration points.
+type Tracker struct {
+	hub       *hub.Hub
+	estimator *Estimator
+	comp      *Compensator
+	conf      *config.Configuration
+}
+
+func NewTracker(h *hub.Hub) *Tracker {
+	return &Tracker{hub: h, estimator: NewEstimator(readingNoise, rateDrift), comp: NewCompensator()}
+}
+
+func (t *Tracker) Run(ctx context.Context) {
+	configCh := t.hub.Configuration.JoinContext(ctx)
+	pressureCh := t.hub.NpaPressureFiltered.JoinContext(ctx)
+	tempCh := t.hub.DsTemperatureFiltered.JoinContext(ctx)
+	dieCh := t.hub.NpaTemperatureFiltered.JoinContext(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case x := <-configCh:
+			if t.conf == nil || calibrationChanged(t.conf, x) {
+				t.estimator.Reset()
+			}
+			t.conf = x
+		case x := <-tempCh:
+			t.comp.Wort(x.Value)
+			t.estimator.UpdateTemperature(conv.DsToC(x.Value), x.Time)
+		case x := <-dieCh:
+			t.comp.Die(x.Value)
+		case x := <-pressureCh:
+			if t.conf == nil {
+				continue
+			}
+			pa, wort := t.comp.Pressure(t.conf, x.Value)
+			t.hub.Pressure.Send(hub.Pressure{Pa: pa, Temperature: wort, Time: x.Time})
+			if !Calibrated(t.conf) {
+				continue
+			}
+			t.estimator.Update(FromPressure(t.conf, pa), x.Time)
+			if t.estimator.Ready() {
+				t.hub.Gravity.Send(t.estimator.Estimate())
+			}
+		}
+	}
+}
+
+func calibrationChanged(a *config.Configuration, b *config.Configuration) bool {
+	return a.NpaZero != b.NpaZero || a.NpaCalibration != b.NpaCalibration ||
+		a.NpaMinValue != b.NpaMinValue || a.NpaMaxValue != b.NpaMaxValue ||
+		a.NpaMinPressure != b.NpaMinPressure || a.NpaMaxPressure != b.NpaMaxPressure ||
+		a.NpaCalibrationTemperature != b.NpaCalibrationTemperature ||
+		a.NpaReferenceTemperature != b.NpaReferenceTemperature || a.NpaDrift != b.NpaDrift ||
+		a.SgModel != b.SgModel
+}
@@ -1,17 +1,18 @@
 package gui
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"time"
 
 	"strings"
 
-	"github.com/zlowred/goqt/ui"
 	"github.com/zlowred/alcobot/config"
 	"github.com/zlowred/alcobot/conv"
-	"github.com/zlowred/alcobot/hub"
+	"github.com/zlowred/alcobot/gravity"
 	"github.com/zlowred/alcobot/series"
+	"github.com/zlowred/goqt/ui"
 )
 
 const sx int32 = 75
@@ -25,6 +26,9 @@ type BrewingChart struct {
 	adsSeries     *series.Series
 	dsSeries      *series.Series
 	npaPresSeries *series.Series
+	sgSeries      *series.Series
+	sgLowSeries   *series.Series
+	sgHighSeries  *series.Series
 
 	conf *config.Configuration
 }
@@ -35,26 +39,27 @@ func NewBrewingChart(screen *RootScreen) *BrewingChart {
 
 	c.w.InstallEventFilter(c)
 
-	go c.loop()
+	screen.spawn(c.loop)
 
 	return c
 }
 
-func (c *BrewingChart) loop() {
-	adsValueSensor := hub.JoinInt16Group(c.screen.hub.AdsValueSensor)
-	dsTemp := hub.JoinInt16Group(c.screen.hub.DsTemperatureFiltered)
-	npaPres := hub.JoinInt16Group(c.screen.hub.NpaPressureFiltered)
-	configCh := hub.JoinConfigGroup(c.screen.hub.Configuration)
+func (c *BrewingChart) loop(ctx context.Context) {
+	adsValueSensor := c.screen.hub.AdsValueSensor.JoinContext(ctx)
+	dsTemp := c.screen.hub.DsTemperatureFiltered.JoinContext(ctx)
+	npaPres := c.screen.hub.NpaPressureFiltered.JoinContext(ctx)
+	gravityCh := c.screen.hub.Gravity.JoinContext(ctx)
+	configCh := c.screen.hub.Configuration.JoinContext(ctx)
 
 	for {
 		select {
-		case <-c.screen.hub.Quit:
+		case <-ctx.Done():
 			return
 		case x := <-adsValueSensor:
 			if c.adsSeries == nil {
 				break
 			}
-			c.adsSeries.Push((float64(x) - 4500))
+			c.adsSeries.PushAt(x.Time, float64(x.Value)-4500)
 			ui.Async(func() {
 				c.w.Update()
 			})
@@ -65,7 +70,7 @@ func (c *BrewingChart) loop() {
 			if c.dsSeries == nil {
 				break
 			}
-			c.dsSeries.Push(float64(x))
+			c.dsSeries.PushAt(x.Time, float64(x.Value))
 			ui.Async(func() {
 				c.w.Update()
 			})
@@ -76,11 +81,29 @@ func (c *BrewingChart) loop() {
 			if c.npaPresSeries == nil {
 				break
 			}
-			c.npaPresSeries.Push(conv.NpaToPa(x, c.conf.NpaZero, c.conf.NpaMinValue, c.conf.NpaMaxValue, c.conf.NpaMinPressure, c.conf.NpaMaxPressure))
+			c.npaPresSeries.PushAt(x.Time, conv.NpaToPa(x.Value, c.conf.NpaZero, c.conf.NpaMinValue, c.conf.NpaMaxValue, c.conf.NpaMinPressure, c.conf.NpaMaxPressure))
 			ui.Async(func() {
 				c.w.Update()
 			})
+		case x := <-gravityCh:
+			if c.conf == nil {
+				continue
+			}
+			if c.sgSeries == nil {
+				break
+			}
+			// the estimate is drawn on the pressure axis
+			c.sgSeries.PushAt(x.Time, gravity.ToPressure(c.conf, x.SG))
+			c.sgLowSeries.PushAt(x.Time, gravity.ToPressure(c.conf, x.SGLow))
+			c.sgHighSeries.PushAt(x.Time, gravity.ToPressure(c.conf, x.SGHigh))
 		case x := <-configCh:
+			if c.conf != nil && c.conf.Id != x.Id {
+				ui.Async(func() {
+					c.adsSeries, c.dsSeries, c.npaPresSeries = nil, nil, nil
+					c.sgSeries, c.sgLowSeries, c.sgHighSeries = nil, nil, nil
+					c.w.Update()
+				})
+			}
 			c.conf = x
 		}
 	}
@@ -89,14 +112,24 @@ func (c *BrewingChart) loop() {
 func (c *BrewingChart) OnPaintEvent(e *ui.QPaintEvent) bool {
 	c.w.PaintEvent(e)
 
+	// the series share their start so that they line up in time
+	now := time.Now()
 	if c.adsSeries == nil {
-		c.adsSeries = series.NewSeries(int(c.w.Width() - sx))
+		c.adsSeries = series.NewTimeSeries(int(c.w.Width()-sx), now)
 	}
 	if c.dsSeries == nil {
-		c.dsSeries = series.NewSeries(int(c.w.Width() - sx))
+		c.dsSeries = series.NewTimeSeries(int(c.w.Width()-sx), now)
 	}
 	if c.npaPresSeries == nil {
-		c.npaPresSeries = series.NewSeries(int(c.w.Width() - sx))
+		c.npaPresSeries = series.NewTimeSeries(int(c.w.Width()-sx), now)
+	}
+	if c.sgSeries == nil {
+		c.sgSeries = series.NewTimeSeries(int(c.w.Width()-sx), now)
+		c.sgLowSeries = series.NewTimeSeries(int(c.w.Width()-sx), now)
+		c.sgHighSeries = series.NewTimeSeries(int(c.w.Width()-sx), now)
+	}
+	for _, s := range []*series.Series{c.adsSeries, c.dsSeries, c.npaPresSeries, c.sgSeries, c.sgLowSeries, c.sgHighSeries} {
+		s.Advance(now)
 	}
 
 	painter := ui.NewPainterWithPaintDevice(c.w)
@@ -144,6 +177,9 @@ func (c *BrewingChart) OnPaintEvent(e *ui.QPaintEvent) bool {
 
 	data = c.dsSeries.Get()
 	for i, _ := range data {
+		if math.IsNaN(data[i]) {
+			continue
+		}
 		if c.conf.TemperatureScale == config.F {
 			data[i] = conv.DsToF(int16(data[i]))
 		} else {
@@ -156,6 +192,9 @@ func (c *BrewingChart) OnPaintEvent(e *ui.QPaintEvent) bool {
 	data = c.npaPresSeries.Get()
 	shift3, scale3, min3, max3 := analyze(data, float64(c.w.Height()-sy), 1)
 	draw(data, shift3, scale3, ui.Qt_cyan, painter, float64(c.w.Width()), float64(c.w.Height()))
+	draw(c.sgLowSeries.Get(), shift3, scale3, ui.Qt_darkCyan, painter, float64(c.w.Width()), float64(c.w.Height()))
+	draw(c.sgHighSeries.Get(), shift3, scale3, ui.Qt_darkCyan, painter, float64(c.w.Width()), float64(c.w.Height()))
+	draw(c.sgSeries.Get(), shift3, scale3, ui.Qt_white, painter, float64(c.w.Width()), float64(c.w.Height()))
 
 	totalTime := (time.Now().Sub(c.conf.BrewingStartTime))
 	for i = 0; i < steps; i++ {
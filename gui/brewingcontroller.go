@@ -1,13 +1,15 @@
 package gui
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"time"
 
-	"github.com/zlowred/goqt/ui"
 	"github.com/zlowred/alcobot/config"
 	"github.com/zlowred/alcobot/conv"
 	"github.com/zlowred/alcobot/hub"
+	"github.com/zlowred/goqt/ui"
 )
 
 type BrewingController struct {
@@ -18,11 +20,17 @@ type BrewingController struct {
 	minus *ui.QPushButton
 	pwm   []*ui.QLabel
 
+	finish *confirmButton
+	abort  *confirmButton
+
 	fermenterTemp *ui.QLabel
 	npaTemp       *ui.QLabel
 	pressure      *ui.QLabel
 	sg            *ui.QLabel
 	og            *ui.QLabel
+	abv           *ui.QLabel
+	attenuation   *ui.QLabel
+	eta           *ui.QLabel
 	pidOut        *ui.QLabel
 	curOut        *ui.QLabel
 	adcOut        *ui.QLabel
@@ -44,11 +52,21 @@ func NewBrewingController(screen *RootScreen) *BrewingController {
 	ctl.pressure = ui.NewLabelFromDriver(screen.FindChild("pressure"))
 	ctl.sg = ui.NewLabelFromDriver(screen.FindChild("sg"))
 	ctl.og = ui.NewLabelFromDriver(screen.FindChild("og"))
+	ctl.abv = ui.NewLabelFromDriver(screen.FindChild("abv"))
+	ctl.attenuation = ui.NewLabelFromDriver(screen.FindChild("attenuation"))
+	ctl.eta = ui.NewLabelFromDriver(screen.FindChild("eta"))
 	ctl.pidOut = ui.NewLabelFromDriver(screen.FindChild("pidOut"))
 	ctl.curOut = ui.NewLabelFromDriver(screen.FindChild("curOut"))
 	ctl.adcOut = ui.NewLabelFromDriver(screen.FindChild("adcOut"))
 	ctl.timer = ui.NewLabelFromDriver(screen.FindChild("timer"))
 
+	ctl.finish = newConfirmButton(ui.NewPushButtonFromDriver(screen.FindChild("finishBrewing")), "Finish", func() {
+		transition(screen.hub, hub.Finish)
+	})
+	ctl.abort = newConfirmButton(ui.NewPushButtonFromDriver(screen.FindChild("abortBrewing")), "Abort", func() {
+		transition(screen.hub, hub.Abort)
+	})
+
 	ctl.pwm = make([]*ui.QLabel, 16)
 
 	for i := 0; i < 16; i++ {
@@ -78,20 +96,22 @@ func NewBrewingController(screen *RootScreen) *BrewingController {
 		ctl.screen.hub.Configuration.Send(ctl.conf)
 	})
 
-	go ctl.loop()
+	screen.spawn(ctl.loop)
 
 	return ctl
 }
 
-func (ctl *BrewingController) loop() {
-	configCh := hub.JoinConfigGroup(ctl.screen.hub.Configuration)
-	pwmCh := hub.JoinPwmValueGroup(ctl.screen.hub.PwmOutput)
-	npaTemperatureFiltered := hub.JoinInt16Group(ctl.screen.hub.NpaTemperatureFiltered)
-	npaPressureFiltered := hub.JoinInt16Group(ctl.screen.hub.NpaPressureFiltered)
-	dsTemperatureFiltered := hub.JoinInt16Group(ctl.screen.hub.DsTemperatureFiltered)
-	adsValue := hub.JoinInt16Group(ctl.screen.hub.AdsValueSensor)
-	pid := hub.JoinFloat64Group(ctl.screen.hub.PidOutput)
-	pidAdj := hub.JoinFloat64Group(ctl.screen.hub.AdjustedPidOutput)
+func (ctl *BrewingController) loop(ctx context.Context) {
+	configCh := ctl.screen.hub.Configuration.JoinContext(ctx)
+	pwmCh := ctl.screen.hub.PwmOutput.JoinContext(ctx)
+	npaTemperatureFiltered := ctl.screen.hub.NpaTemperatureFiltered.JoinContext(ctx)
+	npaPressureFiltered := ctl.screen.hub.NpaPressureFiltered.JoinContext(ctx)
+	dsTemperatureFiltered := ctl.screen.hub.DsTemperatureFiltered.JoinContext(ctx)
+	adsValue := ctl.screen.hub.AdsValueSensor.JoinContext(ctx)
+	pid := ctl.screen.hub.PidOutput.JoinContext(ctx)
+	pidAdj := ctl.screen.hub.AdjustedPidOutput.JoinContext(ctx)
+	gravity := ctl.screen.hub.Gravity.JoinContext(ctx)
+	fermentation := ctl.screen.hub.Fermentation.JoinContext(ctx)
 	ticker := time.NewTicker(time.Second)
 	for {
 		select {
@@ -101,16 +121,23 @@ func (ctl *BrewingController) loop() {
 				duration := now.Sub(ctl.startTime.Round(time.Second))
 				ctl.timer.SetText(duration.String())
 			})
-		case <-ctl.screen.hub.Quit:
+		case <-ctx.Done():
 			return
 		case x := <-configCh:
 			ctl.conf = x
 			ui.Async(func() {
+				ctl.finish.SetEnabled(hub.Finish.Allowed(ctl.conf.Stage))
+				ctl.abort.SetEnabled(ctl.conf.Stage == config.BREWING)
 				if ctl.conf.TemperatureScale == config.F {
 					ctl.temp.SetText(fmt.Sprintf("%.1fºF", conv.CtoF(ctl.conf.TargetTemperature)))
 				} else {
 					ctl.temp.SetText(fmt.Sprintf("%.1fºC", ctl.conf.TargetTemperature))
 				}
+				if ctl.conf.OG > 0 {
+					ctl.og.SetText(fmt.Sprintf("%.4f", ctl.conf.OG))
+				} else {
+					ctl.og.SetText("---")
+				}
 			})
 		case x := <-pwmCh:
 			if ctl.conf == nil {
@@ -125,9 +152,9 @@ func (ctl *BrewingController) loop() {
 			}
 			ui.Async(func() {
 				if ctl.conf.TemperatureScale == config.F {
-					ctl.npaTemp.SetText(fmt.Sprintf("%.1fºF", conv.NpaToF(x)))
+					ctl.npaTemp.SetText(fmt.Sprintf("%.1fºF", conv.NpaToF(x.Value)))
 				} else {
-					ctl.npaTemp.SetText(fmt.Sprintf("%.1fºC", conv.NpaToC(x)))
+					ctl.npaTemp.SetText(fmt.Sprintf("%.1fºC", conv.NpaToC(x.Value)))
 				}
 			})
 		case x := <-pid:
@@ -149,10 +176,28 @@ func (ctl *BrewingController) loop() {
 				continue
 			}
 			ui.Async(func() {
-				pa := conv.NpaToPa(x, ctl.conf.NpaZero, ctl.conf.NpaMinValue, ctl.conf.NpaMaxValue, ctl.conf.NpaMinPressure, ctl.conf.NpaMaxPressure)
+				pa := conv.NpaToPa(x.Value, ctl.conf.NpaZero, ctl.conf.NpaMinValue, ctl.conf.NpaMaxValue, ctl.conf.NpaMinPressure, ctl.conf.NpaMaxPressure)
 				ctl.pressure.SetText(fmt.Sprintf("%.1fPa<font color='cyan'>&nbsp;➟</font>", pa))
-				ctl.sg.SetText(fmt.Sprintf("%.4f", conv.PaToSg(pa, ctl.conf.NpaCalibration)))
-				ctl.og.SetText(fmt.Sprintf("%.4f", ctl.og))
+			})
+		case x := <-gravity:
+			og := 0.
+			if ctl.conf != nil {
+				og = ctl.conf.OG
+			}
+			a := hub.NewAlcohol(og, x.SG)
+			ui.Async(func() {
+				ctl.sg.SetText(fmt.Sprintf("%.4f<font size='2'>&nbsp;±%.4f&nbsp;%+.1fpts/d</font>", x.SG, (x.SGHigh-x.SGLow)/2, x.Rate))
+				if math.IsNaN(a.ABV) {
+					ctl.abv.SetText("---")
+					ctl.attenuation.SetText("---")
+					return
+				}
+				ctl.abv.SetText(fmt.Sprintf("%.1f%%<font size='2'>&nbsp;alt %.1f%%</font>", a.ABV, a.ABVAlternate))
+				ctl.attenuation.SetText(fmt.Sprintf("%.0f%%<font size='2'>&nbsp;real %.0f%%</font>", a.ApparentAttenuation, a.RealAttenuation))
+			})
+		case x := <-fermentation:
+			ui.Async(func() {
+				ctl.eta.SetText(formatCompletion(x, time.Now()))
 			})
 		case x := <-dsTemperatureFiltered:
 			if ctl.conf == nil {
@@ -160,9 +205,9 @@ func (ctl *BrewingController) loop() {
 			}
 			ui.Async(func() {
 				if ctl.conf.TemperatureScale == config.F {
-					ctl.fermenterTemp.SetText(fmt.Sprintf("%.1fºF<font color='#ff0'>&nbsp;➟</font>", conv.DsToF(x)))
+					ctl.fermenterTemp.SetText(fmt.Sprintf("%.1fºF<font color='#ff0'>&nbsp;➟</font>", conv.DsToF(x.Value)))
 				} else {
-					ctl.fermenterTemp.SetText(fmt.Sprintf("%.1fºC<font color='#ff0'>&nbsp;➟</font>", conv.DsToC(x)))
+					ctl.fermenterTemp.SetText(fmt.Sprintf("%.1fºC<font color='#ff0'>&nbsp;➟</font>", conv.DsToC(x.Value)))
 				}
 			})
 		case x := <-adsValue:
@@ -170,8 +215,24 @@ func (ctl *BrewingController) loop() {
 				continue
 			}
 			ui.Async(func() {
-				ctl.adcOut.SetText(fmt.Sprintf("%.d<font color='#0f0'>&nbsp;➟</font>", x))
+				ctl.adcOut.SetText(fmt.Sprintf("%.d<font color='#0f0'>&nbsp;➟</font>", x.Value))
 			})
 		}
 	}
 }
+
+// formatCompletion shows whether fermentation is complete, or when it is
+// expected to be and at what FG.
+func formatCompletion(f hub.Fermentation, now time.Time) string {
+	switch {
+	case f.Stable:
+		return "<font color='#0f0'>stable</font>"
+	case math.IsNaN(f.FG):
+		return "---"
+	case !f.ETA.After(now):
+		return fmt.Sprintf("due<font size='2'>&nbsp;FG %.4f</font>", f.FG)
+	}
+	left := f.ETA.Sub(now)
+	days, hours := int(left/(24*time.Hour)), int(left%(24*time.Hour)/time.Hour)
+	return fmt.Sprintf("%dd %dh<font size='2'>&nbsp;FG %.4f</font>", days, hours, f.FG)
+}
@@ -0,0 +1,58 @@
+package gui
+
+import (
+	"log"
+	"time"
+
+	"github.com/zlowred/alcobot/hub"
+	"github.com/zlowred/goqt/ui"
+)
+
+// confirmTimeout is how long a confirm button waits for the second tap.
+const confirmTimeout = time.Second * 3
+
+// confirmButton runs its action only when tapped twice within
+// confirmTimeout. The first tap asks for confirmation on the button itself.
+type confirmButton struct {
+	*ui.QPushButton
+
+	text  string
+	armed bool
+	timer *time.Timer
+}
+
+func newConfirmButton(button *ui.QPushButton, text string, action func()) *confirmButton {
+	b := &confirmButton{QPushButton: button, text: text}
+	button.OnClicked(func() {
+		if b.armed {
+			b.disarm()
+			action()
+			return
+		}
+		b.armed = true
+		button.SetText("Confirm?")
+		b.timer = time.AfterFunc(confirmTimeout, func() {
+			ui.Async(b.disarm)
+		})
+	})
+	return b
+}
+
+func (b *confirmButton) disarm() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.armed = false
+	b.SetText(b.text)
+}
+
+// transition applies t off the UI thread; the screens follow the
+// configuration it publishes.
+func transition(h *hub.Hub, t hub.Transition) {
+	go func() {
+		if err := h.Transition(t); err != nil {
+			log.Printf("Can't %v: %v\n", t, err)
+		}
+	}()
+}
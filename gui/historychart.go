@@ -0,0 +1,171 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+	"github.com/zlowred/alcobot/series"
+	"github.com/zlowred/goqt/ui"
+)
+
+// HistoryChart draws the recorded temperatures and SG of an archived brew.
+type HistoryChart struct {
+	screen *RootScreen
+
+	w    *ui.QWidget
+	conf *config.Configuration
+
+	session hub.Session
+	dps     []*hub.DataPoint
+}
+
+func NewHistoryChart(screen *RootScreen) *HistoryChart {
+	c := &HistoryChart{screen: screen}
+	c.w = ui.NewWidgetFromDriver(screen.FindChild("historyChart"))
+
+	c.w.InstallEventFilter(c)
+
+	screen.spawn(c.loop)
+
+	return c
+}
+
+func (c *HistoryChart) loop(ctx context.Context) {
+	configCh := c.screen.hub.Configuration.JoinContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case x := <-configCh:
+			c.conf = x
+		}
+	}
+}
+
+// show replaces the charted session; it must be called on the UI thread.
+func (c *HistoryChart) show(s hub.Session, dps []*hub.DataPoint) {
+	c.session = s
+	c.dps = dps
+	c.w.Update()
+}
+
+func (c *HistoryChart) OnPaintEvent(e *ui.QPaintEvent) bool {
+	c.w.PaintEvent(e)
+
+	if c.conf == nil || len(c.dps) == 0 {
+		return true
+	}
+
+	targetTemp := series.NewSeries(int(c.w.Width() - sx))
+	currentTemp := series.NewSeries(int(c.w.Width() - sx))
+	sg := series.NewSeries(int(c.w.Width() - sx))
+	for _, dp := range c.dps {
+		current := dp.CurrentTemp
+		if !math.IsNaN(current) {
+			current = conv.DsToC(int16(current))
+		}
+		target := dp.TargetTemp
+		if c.conf.TemperatureScale == config.F {
+			current = conv.CtoF(current)
+			target = conv.CtoF(target)
+		}
+		targetTemp.PushN(target, dp.Span)
+		currentTemp.PushN(current, dp.Span)
+		sg.PushN(dp.SG, dp.Span)
+	}
+
+	painter := ui.NewPainterWithPaintDevice(c.w)
+	defer painter.Delete()
+
+	pen := ui.NewPen()
+	pen.SetWidth(0)
+	pen.SetColor(ui.NewColorWithGlobalcolor(ui.Qt_white))
+	painter.SetPenWithPen(pen)
+
+	painter.DrawLineWithX1Y1X2Y2(sx, c.w.Height()-sy, c.w.Width(), c.w.Height()-sy)
+	painter.DrawLineWithX1Y1X2Y2(sx, 0, sx, c.w.Height()-sy)
+	painter.DrawLineWithX1Y1X2Y2(sx-25, 0, sx-25, c.w.Height()-sy)
+
+	var i int32
+	for i = 0; i < steps*2; i++ {
+		px := sx + (c.w.Width()-sx)/(steps*2)*i
+		painter.DrawLineWithX1Y1X2Y2(px, c.w.Height()-sy, px, c.w.Height()-sy+5+(5*((i+1)%2)))
+		py := c.w.Height() - sy - (c.w.Height()-sy)/(steps*2)*i
+		painter.DrawLineWithX1Y1X2Y2(sx, py, sx-5-(5*((i+1)%2)), py)
+		painter.DrawLineWithX1Y1X2Y2(sx-25, py, sx-25-5-(5*((i+1)%2)), py)
+	}
+
+	pen.SetColor(ui.NewColorWithGlobalcolor(ui.Qt_darkGray))
+	pen.SetStyle(ui.Qt_DotLine)
+	painter.SetPenWithPen(pen)
+
+	for i = 1; i < steps; i++ {
+		px := sx + (c.w.Width()-sx)/steps*i
+		painter.DrawLineWithX1Y1X2Y2(px, c.w.Height()-sy, px, 0)
+		py := c.w.Height() - sy - (c.w.Height()-sy)/steps*i
+		painter.DrawLineWithX1Y1X2Y2(sx, py, c.w.Width(), py)
+	}
+
+	painter.SetRenderHint(ui.QPainter_Antialiasing)
+	pen.SetStyle(ui.Qt_SolidLine)
+	painter.SetPenWithPen(pen)
+
+	data := sg.Get()
+	shift1, scale1, min1, max1 := analyze(data, float64(c.w.Height()-sy), 0.001)
+	draw(data, shift1, scale1, ui.Qt_green, painter, float64(c.w.Width()), float64(c.w.Height()))
+
+	data = currentTemp.Get()
+	data2 := targetTemp.Get()
+	shift2, scale2, min2, max2 := analyze(append(data, data2...), float64(c.w.Height()-sy), 5)
+	draw(data, shift2, scale2, ui.Qt_cyan, painter, float64(c.w.Width()), float64(c.w.Height()))
+	draw(data2, shift2, scale2, ui.Qt_blue, painter, float64(c.w.Width()), float64(c.w.Height()))
+
+	font := painter.Font()
+	defer font.Delete()
+	font.SetPointSize(int32(10))
+	painter.SetFont(font)
+
+	for i = 0; i < steps; i++ {
+		pen.SetColor(ui.NewColorWithGlobalcolor(ui.Qt_gray))
+		painter.SetPenWithPen(pen)
+		painter.Save()
+		painter.TranslateFWithDxDy(float64(sx+i*(c.w.Width()-sx)/steps), float64(c.w.Height()-sy+10))
+		painter.Rotate(15)
+		t := c.session.Duration / time.Duration(steps) * time.Duration(i)
+		t -= t % time.Minute
+		s := strings.Replace(t.String(), "0s", "", -1)
+		if i != 0 && s == "0" {
+			s = ""
+		}
+		painter.DrawTextWithXYText(0, 0, s)
+		painter.Restore()
+
+		pen.SetColor(ui.NewColorWithGlobalcolor(ui.Qt_green))
+		painter.SetPenWithPen(pen)
+		painter.Save()
+		painter.TranslateFWithDxDy(float64(sx-5), float64(c.w.Height()-sy-(c.w.Height()-sy)/steps*i)-5)
+		painter.Rotate(-105)
+		painter.DrawTextWithXYText(0, 0, fmt.Sprintf("%.3f", min1+(max1-min1)/float64(steps)*float64(i)))
+		painter.Restore()
+
+		pen.SetColor(ui.NewColorWithGlobalcolor(ui.Qt_cyan))
+		painter.SetPenWithPen(pen)
+		painter.Save()
+		painter.TranslateFWithDxDy(float64(sx-5-25), float64(c.w.Height()-sy-(c.w.Height()-sy)/steps*i)-5)
+		painter.Rotate(-105)
+		if c.conf.TemperatureScale == config.F {
+			painter.DrawTextWithXYText(0, 0, fmt.Sprintf("%.0fºF", min2+(max2-min2)/float64(steps)*float64(i)))
+		} else {
+			painter.DrawTextWithXYText(0, 0, fmt.Sprintf("%.0fºC", min2+(max2-min2)/float64(steps)*float64(i)))
+		}
+		painter.Restore()
+	}
+
+	return true
+}
@@ -0,0 +1,200 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/control"
+	"github.com/zlowred/alcobot/hub"
+	"github.com/zlowred/goqt/ui"
+)
+
+type HistoryController struct {
+	screen *RootScreen
+
+	session *ui.QComboBox
+	details *ui.QLabel
+	newBrew *confirmButton
+
+	sessions []hub.Session
+	selected int
+	filling  bool
+
+	conf *config.Configuration
+}
+
+func NewHistoryController(screen *RootScreen) *HistoryController {
+	ctl := &HistoryController{screen: screen, selected: -1}
+
+	ctl.session = ui.NewComboBoxFromDriver(screen.FindChild("historySession"))
+	ctl.details = ui.NewLabelFromDriver(screen.FindChild("historyDetails"))
+
+	ctl.session.OnCurrentIndexChanged(func(s string) {
+		if ctl.filling {
+			return
+		}
+		ctl.show(int(ctl.session.CurrentIndex()))
+	})
+	ctl.newBrew = newConfirmButton(ui.NewPushButtonFromDriver(screen.FindChild("newBrewBtn")), "New brew", func() {
+		go func() {
+			if err := ctl.screen.hub.Transition(hub.Reset); err != nil {
+				log.Printf("Can't start a new brew: %v\n", err)
+				return
+			}
+			ctl.refresh()
+		}()
+	})
+
+	screen.spawn(ctl.loop)
+
+	return ctl
+}
+
+func (ctl *HistoryController) loop(ctx context.Context) {
+	configCh := ctl.screen.hub.Configuration.JoinContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case x := <-configCh:
+			ctl.conf = x
+			ui.Async(func() {
+				ctl.newBrew.SetEnabled(hub.Reset.Allowed(x.Stage))
+			})
+		}
+	}
+}
+
+// refresh reloads the list of sessions and shows the current one.
+func (ctl *HistoryController) refresh() {
+	go func() {
+		sessions, err := ctl.screen.hub.Sessions()
+		if err != nil {
+			log.Printf("Can't load brew sessions: %v\n", err)
+			return
+		}
+		names := make([]string, len(sessions))
+		for i, s := range sessions {
+			names[i] = sessionName(s, i == 0)
+		}
+		ui.Async(func() {
+			ctl.filling = true
+			ctl.sessions = sessions
+			for ctl.session.Count() > 0 {
+				ctl.session.RemoveItem(0)
+			}
+			ctl.session.AddItems(names)
+			ctl.session.SetCurrentIndex(0)
+			ctl.filling = false
+			ctl.show(0)
+		})
+	}()
+}
+
+// show displays the session at index i of the list, read-only.
+func (ctl *HistoryController) show(i int) {
+	if i < 0 || i >= len(ctl.sessions) {
+		ctl.selected = -1
+		ctl.details.SetText("")
+		ctl.screen.historyChart.show(hub.Session{}, nil)
+		return
+	}
+	s := ctl.sessions[i]
+	ctl.selected = s.Id
+	ctl.details.SetText(sessionDetails(s, i == 0))
+
+	go func() {
+		dps, err := ctl.screen.hub.SessionDataPoints(s.Id)
+		if err != nil {
+			log.Printf("Can't load data points of brew %d: %v\n", s.Id, err)
+			return
+		}
+		stats, err := ctl.screen.hub.ControlStats(s.Id)
+		if err != nil {
+			log.Printf("Can't load control stats of brew %d: %v\n", s.Id, err)
+		}
+		scale := config.C
+		if ctl.conf != nil {
+			scale = ctl.conf.TemperatureScale
+		}
+		ui.Async(func() {
+			if ctl.selected == s.Id {
+				ctl.details.SetText(sessionDetails(s, i == 0) + formatControl(stats, scale))
+				ctl.screen.historyChart.show(s, dps)
+			}
+		})
+	}()
+}
+
+func sessionName(s hub.Session, current bool) string {
+	name := s.Name
+	if name == "" {
+		name = fmt.Sprintf("Brew #%d", s.Id)
+	}
+	if s.Started() {
+		name += " — " + s.Start.Format("2006-01-02")
+	}
+	if current {
+		name += " (current)"
+	}
+	return name
+}
+
+func sessionDetails(s hub.Session, current bool) string {
+	if !s.Started() {
+		return "Not started yet"
+	}
+	end := s.End.Format("2006-01-02 15:04")
+	if current && (s.Stage == config.PREPARATION || s.Stage == config.BREWING) {
+		end = "in progress"
+	}
+	pitch := "—"
+	if s.Pitch.Unix() > 0 {
+		pitch = s.Pitch.Format("2006-01-02 15:04")
+	}
+	return fmt.Sprintf("Started: <font color='#0ff'>%v</font>&nbsp; Pitched: <font color='#0ff'>%v</font>&nbsp; Ended: <font color='#0ff'>%v</font>&nbsp; Duration: <font color='#0ff'>%v</font><br>"+
+		"OG: <font color='#ff0'>%v</font>&nbsp; FG: <font color='#ff0'>%v</font>&nbsp; ABV: <font color='#ff0'>%v</font>",
+		s.Start.Format("2006-01-02 15:04"), pitch, end, s.Duration-s.Duration%time.Minute, formatSG(s.OG), formatSG(s.FG), formatABV(s.OG, s.FG))
+}
+
+// formatControl shows how well the temperature was controlled, a line per
+// scope of the stats.
+func formatControl(stats []hub.ControlStats, scale config.TempScale) string {
+	unit, factor := "ºC", 1.
+	if scale == config.F {
+		unit, factor = "ºF", 1.8
+	}
+	var text string
+	for _, s := range stats {
+		settled := "—"
+		if s.Settled > 0 {
+			settled = (s.SettlingTime - s.SettlingTime%time.Minute).String()
+		}
+		text += fmt.Sprintf("<br>%v: <font color='#0f0'>%.2f%v</font> rms, %.1f%v max, %.0f%% in ±%.1f%v&nbsp; "+
+			"Setpoints: %d, %d settled in %v, overshoot %.1f%v&nbsp; Reversals: %d&nbsp; Power: %.0f%%",
+			strings.ToUpper(s.Scope[:1])+s.Scope[1:], s.RMSDeviation*factor, unit, s.MaxDeviation*factor, unit,
+			100*s.InBand.Seconds()/s.Duration.Seconds(), control.Band*factor, unit,
+			s.Setpoints, s.Settled, settled, s.Overshoot*factor, unit, s.Reversals, s.AveragePower)
+	}
+	return text
+}
+
+func formatABV(og, fg float64) string {
+	a := hub.NewAlcohol(og, fg)
+	if math.IsNaN(a.ABV) {
+		return "—"
+	}
+	return fmt.Sprintf("%.1f%%", a.ABV)
+}
+
+func formatSG(sg float64) string {
+	if math.IsNaN(sg) || sg == 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%.4f", sg)
+}
@@ -1,6 +1,7 @@
 package gui
 
 import (
+	"context"
 	"fmt"
 
 	"strings"
@@ -8,11 +9,10 @@ import (
 
 	"math"
 
-	"github.com/zlowred/goqt/ui"
 	"github.com/zlowred/alcobot/config"
 	"github.com/zlowred/alcobot/conv"
-	"github.com/zlowred/alcobot/hub"
 	"github.com/zlowred/alcobot/series"
+	"github.com/zlowred/goqt/ui"
 )
 
 type PreparationChart struct {
@@ -33,32 +33,36 @@ func NewPreparationChart(screen *RootScreen) *PreparationChart {
 
 	c.w.InstallEventFilter(c)
 
-	go c.loop()
+	screen.spawn(c.loop)
 
 	return c
 }
 
-func (c *PreparationChart) loop() {
-	configCh := hub.JoinConfigGroup(c.screen.hub.Configuration)
-	dpCh := hub.JoinDataPointGroup(c.screen.hub.DataPoints)
+func (c *PreparationChart) loop(ctx context.Context) {
+	configCh := c.screen.hub.Configuration.JoinContext(ctx)
+	dpCh := c.screen.hub.DataPoints.JoinContext(ctx)
 
 	for {
 		select {
-		case <-c.screen.hub.Quit:
+		case <-ctx.Done():
 			return
 		case x := <-configCh:
+			newBrew := c.conf != nil && c.conf.Id != x.Id
 			c.conf = x
 			ui.Async(func() {
+				if newBrew {
+					c.targetTemp, c.currentTemp, c.pid, c.power = nil, nil, nil, nil
+				}
 				c.w.Update()
 			})
 		case x := <-dpCh:
 			if c.targetTemp == nil || c.currentTemp == nil || c.pid == nil || c.power == nil {
 				continue
 			}
-			c.targetTemp.Push(x.TargetTemp)
-			c.currentTemp.Push(x.CurrentTemp)
-			c.pid.Push(x.PID)
-			c.power.Push(x.Power)
+			c.targetTemp.PushN(x.TargetTemp, x.Span)
+			c.currentTemp.PushN(x.CurrentTemp, x.Span)
+			c.pid.PushN(x.PID, x.Span)
+			c.power.PushN(x.Power, x.Span)
 			ui.Async(func() {
 				c.w.Update()
 			})
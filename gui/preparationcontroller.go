@@ -1,23 +1,24 @@
 package gui
 
 import (
-	"time"
-
+	"context"
 	"fmt"
 
 	"math"
 
-	"github.com/zlowred/goqt/ui"
 	"github.com/zlowred/alcobot/config"
 	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/gravity"
 	"github.com/zlowred/alcobot/hub"
+	"github.com/zlowred/goqt/ui"
 )
 
 type PreparationController struct {
 	screen *RootScreen
 
-	prepare *ui.QPushButton
-	pitch   *ui.QPushButton
+	prepare *confirmButton
+	pitch   *confirmButton
+	abort   *confirmButton
 	minus   *ui.QPushButton
 	plus    *ui.QPushButton
 
@@ -31,32 +32,22 @@ type PreparationController struct {
 	dsTemp      int16
 	pidOutValue float64
 	outValue    float64
+	comp        *gravity.Compensator
 
 	conf *config.Configuration
 }
 
 func NewPreparationController(screen *RootScreen) *PreparationController {
-	ctl := &PreparationController{screen: screen}
+	ctl := &PreparationController{screen: screen, comp: gravity.NewCompensator()}
 
-	ctl.prepare = ui.NewPushButtonFromDriver(screen.FindChild("startPreparation"))
-	ctl.prepare.OnClicked(func() {
-		if ctl.conf == nil {
-			return
-		}
-		ctl.conf.BrewingStartTime = time.Now()
-		ctl.conf.Stage = config.PREPARATION
-		ctl.screen.hub.Configuration.Send(ctl.conf)
+	ctl.prepare = newConfirmButton(ui.NewPushButtonFromDriver(screen.FindChild("startPreparation")), "Start cooling", func() {
+		transition(screen.hub, hub.Prepare)
 	})
-
-	ctl.pitch = ui.NewPushButtonFromDriver(screen.FindChild("pitchYeast"))
-	ctl.pitch.OnClicked(func() {
-		if ctl.conf == nil {
-			return
-		}
-		ctl.conf.PitchTime = time.Now()
-		ctl.conf.Stage = config.BREWING
-		ctl.screen.hub.Configuration.Send(ctl.conf)
-		ctl.screen.hub.ScreenChange.Send(config.BREWING_SCREEN)
+	ctl.pitch = newConfirmButton(ui.NewPushButtonFromDriver(screen.FindChild("pitchYeast")), "Pitch yeast", func() {
+		transition(screen.hub, hub.Pitch)
+	})
+	ctl.abort = newConfirmButton(ui.NewPushButtonFromDriver(screen.FindChild("abortPreparation")), "Abort", func() {
+		transition(screen.hub, hub.Abort)
 	})
 
 	ctl.minus = ui.NewPushButtonFromDriver(screen.FindChild("preparationMinus"))
@@ -91,28 +82,30 @@ func NewPreparationController(screen *RootScreen) *PreparationController {
 	ctl.pidOutput = ui.NewLabelFromDriver(screen.FindChild("prepPidOutput"))
 	ctl.output = ui.NewLabelFromDriver(screen.FindChild("prepOutput"))
 
-	go ctl.loop()
+	screen.spawn(ctl.loop)
 
 	return ctl
 }
 
-func (ctl *PreparationController) loop() {
-	configCh := hub.JoinConfigGroup(ctl.screen.hub.Configuration)
-	npaPressureFiltered := hub.JoinInt16Group(ctl.screen.hub.NpaPressureFiltered)
-	dsTemperatureFiltered := hub.JoinInt16Group(ctl.screen.hub.DsTemperatureFiltered)
-	pid := hub.JoinFloat64Group(ctl.screen.hub.PidOutput)
-	pidAdj := hub.JoinFloat64Group(ctl.screen.hub.AdjustedPidOutput)
+func (ctl *PreparationController) loop(ctx context.Context) {
+	configCh := ctl.screen.hub.Configuration.JoinContext(ctx)
+	npaPressureFiltered := ctl.screen.hub.NpaPressureFiltered.JoinContext(ctx)
+	dsTemperatureFiltered := ctl.screen.hub.DsTemperatureFiltered.JoinContext(ctx)
+	npaTemperatureFiltered := ctl.screen.hub.NpaTemperatureFiltered.JoinContext(ctx)
+	pid := ctl.screen.hub.PidOutput.JoinContext(ctx)
+	pidAdj := ctl.screen.hub.AdjustedPidOutput.JoinContext(ctx)
 
 	for {
 		select {
-		case <-ctl.screen.hub.Quit:
+		case <-ctx.Done():
 			return
 		case x := <-configCh:
 			ctl.conf = x
 			ui.Async(func() {
 				ctl.plus.SetEnabled(ctl.conf.Stage == config.PREPARATION || ctl.conf.Stage == config.SETUP)
 				ctl.minus.SetEnabled(ctl.conf.Stage == config.PREPARATION || ctl.conf.Stage == config.SETUP)
-				ctl.prepare.SetEnabled(ctl.conf.Stage == config.SETUP)
+				ctl.prepare.SetEnabled(hub.Prepare.Allowed(ctl.conf.Stage))
+				ctl.abort.SetEnabled(ctl.conf.Stage == config.PREPARATION)
 				ctl.pitch.SetEnabled(ctl.conf.Stage == config.PREPARATION && math.Abs(conv.DsToC(ctl.dsTemp)-ctl.conf.TargetTemperature) < 0.2)
 				if ctl.conf.TemperatureScale == config.F {
 					ctl.targetTemp.SetText(fmt.Sprintf("<font color='#00f'>%.1fºF</font>", conv.CtoF(ctl.conf.TargetTemperature)))
@@ -142,8 +135,7 @@ func (ctl *PreparationController) loop() {
 			if ctl.conf == nil {
 				continue
 			}
-			pa := conv.NpaToPa(x, ctl.conf.NpaZero, ctl.conf.NpaMinValue, ctl.conf.NpaMaxValue, ctl.conf.NpaMinPressure, ctl.conf.NpaMaxPressure)
-			ctl.sgValue = conv.PaToSg(pa, ctl.conf.NpaCalibration)
+			ctl.sgValue = ctl.comp.SG(ctl.conf, x.Value)
 			ui.Async(func() {
 				ctl.sg.SetText(fmt.Sprintf("SG: %.3f", ctl.sgValue))
 			})
@@ -151,16 +143,18 @@ func (ctl *PreparationController) loop() {
 			if ctl.conf == nil {
 				continue
 			}
-			ctl.dsTemp = x
+			ctl.dsTemp = x.Value
+			ctl.comp.Wort(x.Value)
 			ui.Async(func() {
 				if ctl.conf.TemperatureScale == config.F {
-					ctl.fermenterTemp.SetText(fmt.Sprintf("Fermenter temp: <font color='#0ff'>%.1fºF</font>", conv.DsToF(x)))
+					ctl.fermenterTemp.SetText(fmt.Sprintf("Fermenter temp: <font color='#0ff'>%.1fºF</font>", conv.DsToF(x.Value)))
 				} else {
-					ctl.fermenterTemp.SetText(fmt.Sprintf("Fermenter temp: <font color='#0ff'>%.1fºC</font>", conv.DsToC(x)))
+					ctl.fermenterTemp.SetText(fmt.Sprintf("Fermenter temp: <font color='#0ff'>%.1fºC</font>", conv.DsToC(x.Value)))
 				}
 				ctl.pitch.SetEnabled(ctl.conf.Stage == config.PREPARATION && math.Abs(conv.DsToC(ctl.dsTemp)-ctl.conf.TargetTemperature) < 0.2)
 			})
-
+		case x := <-npaTemperatureFiltered:
+			ctl.comp.Die(x.Value)
 		}
 	}
 }
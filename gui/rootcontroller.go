@@ -1,34 +1,46 @@
 package gui
 
 import (
-	"math"
+	"context"
+	"fmt"
+	"strings"
 	"time"
 
-	"github.com/zlowred/goqt/ui"
 	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/gravity"
 	"github.com/zlowred/alcobot/hub"
+	"github.com/zlowred/goqt/ui"
 )
 
 const animationLength = 300
 const easingType = ui.QEasingCurve_OutBounce
 const height = 470
 
+// alarmLineHeight is the height of an alarm in the banner.
+const alarmLineHeight = 24
+
 type RootController struct {
 	screen *RootScreen
 
 	setupScreenHeight       float64
 	preparationScreenHeight float64
 	brewingScreenHeight     float64
+	historyScreenHeight     float64
+	alarmBannerHeight       float64
 
 	setupBtn       *ui.QPushButton
 	preparationBtn *ui.QPushButton
 	brewingBtn     *ui.QPushButton
+	historyBtn     *ui.QPushButton
 	quitBtn        *ui.QPushButton
+	alarms         *ui.QLabel
+	alarmBanner    *ui.QLabel
 	screenLayout   *ui.QVBoxLayout
 
 	setupScreen       *ui.QWidget
 	preparationScreen *ui.QWidget
 	brewingScreen     *ui.QWidget
+	historyScreen     *ui.QWidget
 
 	conf *config.Configuration
 }
@@ -55,12 +67,17 @@ func animateHeight(w ui.QObjectInterface, old float64, new float64, parent ui.QO
 	}()
 }
 
-func (ctl *RootController) loop() {
-	screenCh := hub.JoinScreenGroup(ctl.screen.hub.ScreenChange)
-	configCh := hub.JoinConfigGroup(ctl.screen.hub.Configuration)
+func (ctl *RootController) loop(ctx context.Context) {
+	screenCh := ctl.screen.hub.ScreenChange.JoinContext(ctx)
+	configCh := ctl.screen.hub.Configuration.JoinContext(ctx)
+	alarmCh := ctl.screen.hub.Alarm.JoinContext(ctx)
+	alarms := ctl.screen.hub.Alarms()
+	ui.Async(func() {
+		ctl.showAlarms(alarms)
+	})
 	for {
 		select {
-		case <-ctl.screen.hub.Quit:
+		case <-ctx.Done():
 			return
 		case x := <-screenCh:
 			switch x {
@@ -77,50 +94,68 @@ func (ctl *RootController) loop() {
 					ctl.setBrewingScreen()
 				})
 			}
+		case <-alarmCh:
+			alarms := ctl.screen.hub.Alarms()
+			ui.Async(func() {
+				ctl.showAlarms(alarms)
+			})
 		case x := <-configCh:
 			ctl.conf = x
 			ui.Async(func() {
-				ctl.preparationBtn.SetEnabled(len(ctl.conf.FermenterSensor) > 0 && math.Abs(ctl.conf.NpaCalibration) > 0 && ctl.conf.Stage != config.BREWING)
+				ctl.preparationBtn.SetEnabled(len(ctl.conf.FermenterSensor) > 0 && gravity.Calibrated(ctl.conf) && ctl.conf.Stage != config.BREWING)
 				ctl.brewingBtn.SetEnabled(ctl.conf.Stage >= config.BREWING)
 			})
 		}
 	}
 }
 
-func (ctl *RootController) setSetupScreen() {
-	animateHeight(ctl.setupScreen, ctl.setupScreenHeight, height, ctl.screenLayout)
-	animateHeight(ctl.preparationScreen, ctl.preparationScreenHeight, 0, ctl.screenLayout)
-	animateHeight(ctl.brewingScreen, ctl.brewingScreenHeight, 0, ctl.screenLayout)
-
-	ctl.setupBtn.SetChecked(true)
-	ctl.preparationBtn.SetChecked(false)
-	ctl.brewingBtn.SetChecked(false)
+// showAlarms marks the navigation bar and lists the alarms in the banner
+// above the screens while they are raised.
+func (ctl *RootController) showAlarms(alarms []hub.Alarm) {
+	lines := make([]string, len(alarms))
+	for i, a := range alarms {
+		lines[i] = fmt.Sprintf("⚠ %v since %v", a.Message, a.Since.Format("Jan 2 15:04"))
+	}
+	if len(alarms) == 0 {
+		ctl.alarms.SetText("")
+	} else {
+		ctl.alarms.SetText("⚠")
+	}
+	ctl.alarmBanner.SetText(strings.Join(lines, "\n"))
+	h := float64(alarmLineHeight * len(alarms))
+	animateHeight(ctl.alarmBanner, ctl.alarmBannerHeight, h, ctl.screenLayout)
+	ctl.alarmBannerHeight = h
+}
 
-	ctl.setupScreenHeight, ctl.preparationScreenHeight, ctl.brewingScreenHeight = height, 0, 0
+func (ctl *RootController) setSetupScreen() {
+	ctl.setScreens(height, 0, 0, 0)
 }
 
 func (ctl *RootController) setPreparationScreen() {
-	animateHeight(ctl.setupScreen, ctl.setupScreenHeight, 0, ctl.screenLayout)
-	animateHeight(ctl.preparationScreen, ctl.preparationScreenHeight, height, ctl.screenLayout)
-	animateHeight(ctl.brewingScreen, ctl.brewingScreenHeight, 0, ctl.screenLayout)
+	ctl.setScreens(0, height, 0, 0)
+}
 
-	ctl.setupBtn.SetChecked(false)
-	ctl.preparationBtn.SetChecked(true)
-	ctl.brewingBtn.SetChecked(false)
+func (ctl *RootController) setBrewingScreen() {
+	ctl.setScreens(0, 0, height, 0)
+}
 
-	ctl.setupScreenHeight, ctl.preparationScreenHeight, ctl.brewingScreenHeight = 0, height, 0
+func (ctl *RootController) setHistoryScreen() {
+	ctl.setScreens(0, 0, 0, height)
+	ctl.screen.historyController.refresh()
 }
 
-func (ctl *RootController) setBrewingScreen() {
-	animateHeight(ctl.setupScreen, ctl.setupScreenHeight, 0, ctl.screenLayout)
-	animateHeight(ctl.preparationScreen, ctl.preparationScreenHeight, 0, ctl.screenLayout)
-	animateHeight(ctl.brewingScreen, ctl.brewingScreenHeight, height, ctl.screenLayout)
+func (ctl *RootController) setScreens(setup float64, preparation float64, brewing float64, history float64) {
+	animateHeight(ctl.setupScreen, ctl.setupScreenHeight, setup, ctl.screenLayout)
+	animateHeight(ctl.preparationScreen, ctl.preparationScreenHeight, preparation, ctl.screenLayout)
+	animateHeight(ctl.brewingScreen, ctl.brewingScreenHeight, brewing, ctl.screenLayout)
+	animateHeight(ctl.historyScreen, ctl.historyScreenHeight, history, ctl.screenLayout)
 
-	ctl.setupBtn.SetChecked(false)
-	ctl.preparationBtn.SetChecked(false)
-	ctl.brewingBtn.SetChecked(true)
+	ctl.setupBtn.SetChecked(setup > 0)
+	ctl.preparationBtn.SetChecked(preparation > 0)
+	ctl.brewingBtn.SetChecked(brewing > 0)
+	ctl.historyBtn.SetChecked(history > 0)
 
-	ctl.setupScreenHeight, ctl.preparationScreenHeight, ctl.brewingScreenHeight = 0, 0, height
+	ctl.setupScreenHeight, ctl.preparationScreenHeight, ctl.brewingScreenHeight, ctl.historyScreenHeight = setup, preparation, brewing, history
 }
 
 func NewRootController(screen *RootScreen) *RootController {
@@ -129,18 +164,24 @@ func NewRootController(screen *RootScreen) *RootController {
 	ctl.setupBtn = ui.NewPushButtonFromDriver(screen.FindChild("setupBtn"))
 	ctl.preparationBtn = ui.NewPushButtonFromDriver(screen.FindChild("preparationBtn"))
 	ctl.brewingBtn = ui.NewPushButtonFromDriver(screen.FindChild("brewingBtn"))
+	ctl.historyBtn = ui.NewPushButtonFromDriver(screen.FindChild("historyBtn"))
 	ctl.quitBtn = ui.NewPushButtonFromDriver(screen.FindChild("quitBtn"))
+	ctl.alarms = ui.NewLabelFromDriver(screen.FindChild("alarms"))
+	ctl.alarmBanner = ui.NewLabelFromDriver(screen.FindChild("alarmBanner"))
 	ctl.setupScreen = ui.NewWidgetFromDriver(screen.FindChild("setupScreen"))
 	ctl.setupScreenHeight = float64(ctl.setupScreen.MaximumHeight())
 	ctl.preparationScreen = ui.NewWidgetFromDriver(screen.FindChild("preparationScreen"))
 	ctl.preparationScreenHeight = float64(ctl.preparationScreen.MaximumHeight())
 	ctl.brewingScreen = ui.NewWidgetFromDriver(screen.FindChild("brewingScreen"))
 	ctl.brewingScreenHeight = float64(ctl.brewingScreen.MaximumHeight())
+	ctl.historyScreen = ui.NewWidgetFromDriver(screen.FindChild("historyScreen"))
+	ctl.historyScreenHeight = float64(ctl.historyScreen.MaximumHeight())
 	ctl.screenLayout = ui.NewVBoxLayoutFromDriver(screen.FindChild("screenLayout"))
 
 	ctl.setupBtn.SetChecked(ctl.setupScreenHeight > 0)
 	ctl.preparationBtn.SetChecked(ctl.preparationScreenHeight > 0)
 	ctl.brewingBtn.SetChecked(ctl.brewingScreenHeight > 0)
+	ctl.historyBtn.SetChecked(ctl.historyScreenHeight > 0)
 
 	ctl.setupBtn.OnClicked(func() {
 		ctl.setSetupScreen()
@@ -151,11 +192,14 @@ func NewRootController(screen *RootScreen) *RootController {
 	ctl.brewingBtn.OnClicked(func() {
 		ctl.setBrewingScreen()
 	})
+	ctl.historyBtn.OnClicked(func() {
+		ctl.setHistoryScreen()
+	})
 	ctl.quitBtn.OnClicked(func() {
-		close(screen.hub.Quit)
+		screen.quit()
 	})
 
-	go ctl.loop()
+	screen.spawn(ctl.loop)
 
 	return ctl
 }
@@ -1,16 +1,23 @@
 package gui
 
 import (
+	"context"
 	"errors"
+	"sync"
 
-	"github.com/zlowred/goqt/ui"
+	"github.com/zlowred/alcobot/hal"
 	"github.com/zlowred/alcobot/hub"
+	"github.com/zlowred/goqt/ui"
 )
 
 type RootScreen struct {
 	*ui.QWidget
 
-	hub *hub.Hub
+	hub  *hub.Hub
+	hal  *hal.Hal
+	quit func()
+
+	loops []func(ctx context.Context)
 
 	rootController        *RootController
 	brewingController     *BrewingController
@@ -18,10 +25,14 @@ type RootScreen struct {
 	preparationController *PreparationController
 	brewingChart          *BrewingChart
 	preparationChart      *PreparationChart
+	historyController     *HistoryController
+	historyChart          *HistoryChart
 }
 
-func NewRootScreen(hub *hub.Hub) (*RootScreen, error) {
-	screen := &RootScreen{hub: hub}
+// NewRootScreen builds the screen for the hardware of hal. quit is called
+// when the user asks the bot to stop.
+func NewRootScreen(hub *hub.Hub, hal *hal.Hal, quit func()) (*RootScreen, error) {
+	screen := &RootScreen{hub: hub, hal: hal, quit: quit}
 
 	file := ui.NewFileWithName(":/screens/root.ui")
 	defer file.Delete()
@@ -46,6 +57,26 @@ func NewRootScreen(hub *hub.Hub) (*RootScreen, error) {
 	screen.brewingChart = NewBrewingChart(screen)
 	screen.preparationController = NewPreparationController(screen)
 	screen.preparationChart = NewPreparationChart(screen)
+	screen.historyChart = NewHistoryChart(screen)
+	screen.historyController = NewHistoryController(screen)
 
 	return screen, nil
 }
+
+func (screen *RootScreen) spawn(loop func(ctx context.Context)) {
+	screen.loops = append(screen.loops, loop)
+}
+
+// Run runs the controller and chart loops until ctx is done. The window is
+// left open; closing it is up to the caller.
+func (screen *RootScreen) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, loop := range screen.loops {
+		wg.Add(1)
+		go func(loop func(ctx context.Context)) {
+			defer wg.Done()
+			loop(ctx)
+		}(loop)
+	}
+	wg.Wait()
+}
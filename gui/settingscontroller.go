@@ -1,14 +1,17 @@
 package gui
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"math"
 	"time"
 
-	"github.com/zlowred/goqt/ui"
 	"github.com/zlowred/alcobot/config"
 	"github.com/zlowred/alcobot/conv"
-	"github.com/zlowred/alcobot/hal"
+	"github.com/zlowred/alcobot/gravity"
 	"github.com/zlowred/alcobot/hub"
+	"github.com/zlowred/goqt/ui"
 )
 
 type SettingsController struct {
@@ -101,8 +104,8 @@ func NewSettingsController(screen *RootScreen) *SettingsController {
 	ctl.bindControls()
 	ctl.bindControlListeners()
 
-	go ctl.loop()
-	go ctl.updateTempSensors()
+	screen.spawn(ctl.loop)
+	screen.spawn(ctl.updateTempSensors)
 
 	return ctl
 }
@@ -145,15 +148,15 @@ func (ctl *SettingsController) selectFermenterTempSensor() {
 	ctl.fermenterTempSensor.SetCurrentIndex(-1)
 }
 
-func (ctl *SettingsController) updateTempSensors() {
+func (ctl *SettingsController) updateTempSensors(ctx context.Context) {
 	ticker := time.NewTicker(time.Second)
 	for {
 		select {
-		case <-ctl.screen.hub.Quit:
+		case <-ctx.Done():
 			ticker.Stop()
 			return
 		case <-ticker.C:
-			sensors := hal.ListW1Devices()
+			sensors := ctl.screen.hal.ListW1Devices()
 			if !eq(sensors, ctl.dsSensors) {
 				ctl.dsSensors = sensors
 				ui.Async(func() {
@@ -222,9 +225,9 @@ func (ctl *SettingsController) bindControlListeners() {
 				ctl.zeroTimer = nil
 				ctl.zeroCounter = 0
 			}
-			npaPresCh := ctl.screen.hub.NpaPressureSensor.Join()
-			x := npaPresCh.Recv().(int16)
-			npaPresCh.Close()
+			npaPres := ctl.screen.hub.NpaPressureSensor.Subscribe()
+			x := (<-npaPres.C).Value
+			npaPres.Close()
 			ctl.conf.NpaZero = 8192 - x
 			ctl.screen.hub.Configuration.Send(ctl.conf)
 		}
@@ -248,22 +251,31 @@ func (ctl *SettingsController) bindControlListeners() {
 				ctl.calibrationTimer = nil
 				ctl.calibrationCounter = 0
 			}
-			npaPresCh := ctl.screen.hub.NpaPressureFiltered.Join()
+			npaPres := ctl.screen.hub.NpaPressureFiltered.Subscribe()
 			res := []int16{1, 2, 3, 4, 5}
 			ptr := 0
 			for !(res[0] == res[1] && res[1] == res[2] && res[2] == res[3] && res[3] == res[4]) {
-				res[ptr] = npaPresCh.Recv().(int16)
+				res[ptr] = (<-npaPres.C).Value
 				ptr = (ptr + 1) % 5
 			}
-			npaPresCh.Close()
+			npaPres.Close()
+			// the temperatures the calibration is compensated from
+			wort := ctl.screen.hub.DsTemperatureFiltered.Subscribe()
+			ctl.conf.NpaCalibrationTemperature = conv.DsToC((<-wort.C).Value)
+			wort.Close()
+			die := ctl.screen.hub.NpaTemperatureFiltered.Subscribe()
+			ctl.conf.NpaReferenceTemperature = conv.NpaToC((<-die.C).Value)
+			die.Close()
 			ctl.conf.NpaCalibration = conv.NpaToPa(res[0], ctl.conf.NpaZero, ctl.conf.NpaMinValue, ctl.conf.NpaMaxValue, ctl.conf.NpaMinPressure, ctl.conf.NpaMaxPressure)
+			// a single point calibration replaces a fitted model
+			ctl.conf.SgModel = [3]float64{}
 			ctl.screen.hub.Configuration.Send(ctl.conf)
 		}
 	})
 	ctl.presenceZeroBtn.OnClicked(func() {
-		adcCh := ctl.screen.hub.AdsValueFiltered.Join()
-		x := adcCh.Recv().(int16)
-		adcCh.Close()
+		adc := ctl.screen.hub.AdsValueFiltered.Subscribe()
+		x := (<-adc.C).Value
+		adc.Close()
 		ctl.conf.PresenceZero = x
 		ctl.screen.hub.Configuration.Send(ctl.conf)
 	})
@@ -574,14 +586,21 @@ func (ctl *SettingsController) bindControls() {
 	ctl.pump2MaxPlus = ui.NewPushButtonFromDriver(ctl.screen.FindChild("pump2MaxPlus"))
 
 }
-func (ctl *SettingsController) loop() {
-	configCh := hub.JoinConfigGroup(ctl.screen.hub.Configuration)
+func (ctl *SettingsController) loop(ctx context.Context) {
+	configCh := ctl.screen.hub.Configuration.JoinContext(ctx)
 	for {
 		select {
-		case <-ctl.screen.hub.Quit:
+		case <-ctx.Done():
 			return
 		case x := <-configCh:
 			ctl.conf = x
+			var points []hub.CalibrationPoint
+			if gravity.Fitted(x) {
+				var err error
+				if points, err = ctl.screen.hub.CalibrationPoints(x.Id); err != nil {
+					log.Printf("Can't load the calibration points: %v\n", err)
+				}
+			}
 			ui.Async(func() {
 				ctl.selectFermenterTempSensor()
 				if x.TemperatureScale == config.F {
@@ -593,7 +612,7 @@ func (ctl *SettingsController) loop() {
 				}
 				ctl.slope.SetText(fmt.Sprintf("PID Slope:%.0f%%/s", x.PidSlope/2.55))
 				ctl.npaZero.SetText(fmt.Sprintf("Zero point: %v", x.NpaZero))
-				ctl.npaCalibration.SetText(fmt.Sprintf("Calibration: %.1f", x.NpaCalibration))
+				ctl.npaCalibration.SetText(formatCalibration(x, points))
 				ctl.presenceZero.SetText(fmt.Sprintf("Zero point: %v", x.PresenceZero))
 				ctl.presenceCalibration.SetText(fmt.Sprintf("Calibration: %v", x.PresenceCalibration))
 				ctl.presenceEnabled.SetChecked(x.PresenceEnabled)
@@ -620,3 +639,18 @@ func (ctl *SettingsController) loop() {
 		}
 	}
 }
+
+// formatCalibration shows the single point calibration of conf, or the
+// number of points its model is fitted to and the RMS of their residuals.
+func formatCalibration(conf *config.Configuration, points []hub.CalibrationPoint) string {
+	if !gravity.Fitted(conf) {
+		return fmt.Sprintf("Calibration: %.1f at %.1fºC", conf.NpaCalibration, conf.NpaCalibrationTemperature)
+	}
+	var sum float64
+	residuals := gravity.Residuals(conf, points)
+	for _, r := range residuals {
+		sum += r * r
+	}
+	rms := math.Sqrt(sum / math.Max(float64(len(residuals)), 1))
+	return fmt.Sprintf("Calibration: %d points ±%.1fpts", len(points), rms)
+}
@@ -0,0 +1,89 @@
+package emu
+
+import "sync"
+
+const (
+	adsConversion = iota
+	adsConfig
+	adsLoThresh
+	adsHiThresh
+)
+
+const (
+	adsOs         = 0x8000
+	adsSingleShot = 0x0100
+)
+
+// Ads1115 emulates the ADS1115 converter: a pointer register selecting one
+// of four big-endian 16-bit registers, with single-shot conversions started
+// by writing the OS bit of the config register.
+type Ads1115 struct {
+	mutex     sync.Mutex
+	registers [4]uint16
+	pointer   byte
+	value     int16
+}
+
+func NewAds1115(value int16) *Ads1115 {
+	a := &Ads1115{value: value}
+	a.Reset()
+	return a
+}
+
+func (a *Ads1115) Reset() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.registers = [4]uint16{0x0000, 0x8583, 0x8000, 0x7fff}
+	a.pointer = adsConversion
+}
+
+// Set changes the voltage the converter sees, in raw conversion counts.
+func (a *Ads1115) Set(value int16) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.value = value
+	if a.registers[adsConfig]&adsSingleShot == 0 {
+		a.registers[adsConversion] = uint16(value)
+	}
+}
+
+func (a *Ads1115) Register(reg byte) uint16 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.registers[reg&3]
+}
+
+func (a *Ads1115) Write(p []byte) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if len(p) == 0 {
+		return nil
+	}
+	a.pointer = p[0] & 3
+	if len(p) < 3 || a.pointer == adsConversion {
+		return nil
+	}
+	word := uint16(p[1])<<8 | uint16(p[2])
+	if a.pointer == adsConfig {
+		if word&adsOs != 0 || word&adsSingleShot == 0 {
+			a.registers[adsConversion] = uint16(a.value)
+		}
+		word |= adsOs
+	}
+	a.registers[a.pointer] = word
+	return nil
+}
+
+func (a *Ads1115) Read(p []byte) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	word := a.registers[a.pointer]
+	for i := range p {
+		if i%2 == 0 {
+			p[i] = byte(word >> 8)
+		} else {
+			p[i] = byte(word)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package emu
+
+import "sync"
+
+const (
+	dsConvert         = 0x44
+	dsReadScratchpad  = 0xbe
+	dsWriteScratchpad = 0x4e
+	dsPowerOnReset    = 0x0550
+	dsScratchpadSize  = 9
+)
+
+// Ds18b20 emulates the DS18B20 thermometer as seen through the kernel w1
+// slave file: the ROM is already matched, so transfers start with a function
+// command. The scratchpad keeps the power-on 85ºC value until the first
+// conversion, as the real sensor does.
+type Ds18b20 struct {
+	mutex       sync.Mutex
+	scratchpad  [dsScratchpadSize]byte
+	temperature int16
+	reading     bool
+	readPtr     int
+	minusOne    int
+	crcErrors   int
+}
+
+func NewDs18b20(raw int16) *Ds18b20 {
+	d := &Ds18b20{temperature: raw}
+	d.scratchpad = [dsScratchpadSize]byte{dsPowerOnReset & 0xff, dsPowerOnReset >> 8, 0x4b, 0x46, 0x7f, 0xff, 0x0c, 0x10}
+	d.scratchpad[8] = Crc8(d.scratchpad[:8])
+	return d
+}
+
+// Set changes the temperature the next conversion measures, in 1/16 ºC.
+func (d *Ds18b20) Set(raw int16) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.temperature = raw
+}
+
+// MinusOne makes the next count conversions read back as -1, which is what
+// a half-connected sensor pulled high returns.
+func (d *Ds18b20) MinusOne(count int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.minusOne = count
+}
+
+// CrcErrors corrupts the CRC byte of the next count scratchpad reads.
+func (d *Ds18b20) CrcErrors(count int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.crcErrors = count
+}
+
+// Resolution returns the conversion resolution in bits as configured through
+// the scratchpad.
+func (d *Ds18b20) Resolution() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return int(d.scratchpad[4]>>5&3) + 9
+}
+
+func (d *Ds18b20) Write(p []byte) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if len(p) == 0 {
+		return nil
+	}
+	d.reading = false
+	switch p[0] {
+	case dsConvert:
+		raw := d.temperature
+		if d.minusOne > 0 {
+			d.minusOne--
+			raw = -1
+		}
+		d.scratchpad[0], d.scratchpad[1] = byte(raw), byte(raw>>8)
+	case dsReadScratchpad:
+		d.reading = true
+		d.readPtr = 0
+	case dsWriteScratchpad:
+		for i, b := range p[1:] {
+			if i < 3 {
+				d.scratchpad[2+i] = b
+			}
+		}
+		d.scratchpad[4] |= 0x1f
+	}
+	d.scratchpad[8] = Crc8(d.scratchpad[:8])
+	return nil
+}
+
+func (d *Ds18b20) Read(p []byte) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if !d.reading {
+		for i := range p {
+			p[i] = 0xff
+		}
+		return nil
+	}
+	for i := range p {
+		if d.readPtr < dsScratchpadSize {
+			p[i] = d.scratchpad[d.readPtr]
+			if d.readPtr == dsScratchpadSize-1 && d.crcErrors > 0 {
+				d.crcErrors--
+				p[i] = ^p[i]
+			}
+		} else {
+			p[i] = 0xff
+		}
+		d.readPtr++
+	}
+	return nil
+}
+
+// Crc8 is the Dallas/Maxim one-wire CRC used by the scratchpad.
+func Crc8(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		for i := 0; i < 8; i++ {
+			mix := (crc ^ b) & 1
+			crc >>= 1
+			if mix != 0 {
+				crc ^= 0x8c
+			}
+			b >>= 1
+		}
+	}
+	return crc
+}
@@ -0,0 +1,196 @@
+// Package emu provides in-memory I2C and one-wire buses with emulated
+// devices, so the hal pollers can run without a Raspberry Pi.
+package emu
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	ErrNack   = errors.New("emu: address not acknowledged")
+	ErrClosed = errors.New("emu: bus closed")
+)
+
+const generalCallReset = 0x06
+
+// I2CDevice is a slave attached to an emulated I2C bus. Write receives the
+// raw bytes of a write transaction and Read fills p from a read transaction.
+type I2CDevice interface {
+	Read(p []byte) error
+	Write(p []byte) error
+}
+
+// Resetter is implemented by devices that respond to the general call
+// software reset (address 0x00, data 0x06).
+type Resetter interface {
+	Reset()
+}
+
+// I2CBus implements embd.I2CBus on top of emulated devices.
+type I2CBus struct {
+	mutex   sync.Mutex
+	devices map[byte]I2CDevice
+	nacks   map[byte]int
+	closed  bool
+}
+
+func NewI2CBus() *I2CBus {
+	return &I2CBus{devices: make(map[byte]I2CDevice), nacks: make(map[byte]int)}
+}
+
+func (b *I2CBus) Attach(addr byte, dev I2CDevice) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.devices[addr] = dev
+}
+
+func (b *I2CBus) Detach(addr byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.devices, addr)
+}
+
+// Nack makes the next count transactions addressed to addr fail with
+// ErrNack. A negative count keeps failing until Nack(addr, 0) is called.
+func (b *I2CBus) Nack(addr byte, count int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if count == 0 {
+		delete(b.nacks, addr)
+	} else {
+		b.nacks[addr] = count
+	}
+}
+
+func (b *I2CBus) device(addr byte) (I2CDevice, error) {
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if n, ok := b.nacks[addr]; ok {
+		if n > 0 {
+			n--
+			if n == 0 {
+				delete(b.nacks, addr)
+			} else {
+				b.nacks[addr] = n
+			}
+		}
+		return nil, ErrNack
+	}
+	dev, ok := b.devices[addr]
+	if !ok {
+		return nil, ErrNack
+	}
+	return dev, nil
+}
+
+func (b *I2CBus) read(addr byte, p []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	dev, err := b.device(addr)
+	if err != nil {
+		return err
+	}
+	return dev.Read(p)
+}
+
+func (b *I2CBus) write(addr byte, p []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if addr == 0 {
+		return b.generalCall(p)
+	}
+	dev, err := b.device(addr)
+	if err != nil {
+		return err
+	}
+	return dev.Write(p)
+}
+
+func (b *I2CBus) readReg(addr, reg byte, p []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	dev, err := b.device(addr)
+	if err != nil {
+		return err
+	}
+	if err := dev.Write([]byte{reg}); err != nil {
+		return err
+	}
+	return dev.Read(p)
+}
+
+func (b *I2CBus) generalCall(p []byte) error {
+	if b.closed {
+		return ErrClosed
+	}
+	acked := false
+	for _, dev := range b.devices {
+		if r, ok := dev.(Resetter); ok {
+			acked = true
+			if len(p) == 1 && p[0] == generalCallReset {
+				r.Reset()
+			}
+		}
+	}
+	if !acked {
+		return ErrNack
+	}
+	return nil
+}
+
+func (b *I2CBus) ReadByte(addr byte) (byte, error) {
+	p := make([]byte, 1)
+	err := b.read(addr, p)
+	return p[0], err
+}
+
+func (b *I2CBus) ReadBytes(addr byte, num int) ([]byte, error) {
+	p := make([]byte, num)
+	err := b.read(addr, p)
+	return p, err
+}
+
+func (b *I2CBus) WriteByte(addr, value byte) error {
+	return b.write(addr, []byte{value})
+}
+
+func (b *I2CBus) WriteBytes(addr byte, value []byte) error {
+	return b.write(addr, value)
+}
+
+func (b *I2CBus) ReadFromReg(addr, reg byte, value []byte) error {
+	return b.readReg(addr, reg, value)
+}
+
+func (b *I2CBus) ReadByteFromReg(addr, reg byte) (byte, error) {
+	p := make([]byte, 1)
+	err := b.readReg(addr, reg, p)
+	return p[0], err
+}
+
+func (b *I2CBus) ReadWordFromReg(addr, reg byte) (uint16, error) {
+	p := make([]byte, 2)
+	err := b.readReg(addr, reg, p)
+	return uint16(p[0])<<8 | uint16(p[1]), err
+}
+
+func (b *I2CBus) WriteToReg(addr, reg byte, value []byte) error {
+	return b.write(addr, append([]byte{reg}, value...))
+}
+
+func (b *I2CBus) WriteByteToReg(addr, reg, value byte) error {
+	return b.write(addr, []byte{reg, value})
+}
+
+func (b *I2CBus) WriteWordToReg(addr, reg byte, value uint16) error {
+	return b.write(addr, []byte{reg, byte(value >> 8), byte(value)})
+}
+
+func (b *I2CBus) Close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.closed = true
+	return nil
+}
@@ -0,0 +1,65 @@
+package emu
+
+import "sync"
+
+const (
+	npaStatusNormal = 0
+	npaStatusStale  = 2
+)
+
+// Npa700 emulates the NPA-700 pressure sensor: every read returns two status
+// bits, 14 bits of pressure and 11 bits of die temperature.
+type Npa700 struct {
+	mutex       sync.Mutex
+	pressure    uint16
+	temperature uint16
+	stale       int
+}
+
+func NewNpa700(pressure int16, temperature int16) *Npa700 {
+	s := &Npa700{}
+	s.Set(pressure, temperature)
+	return s
+}
+
+func (s *Npa700) Set(pressure int16, temperature int16) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pressure = uint16(pressure) & 0x3fff
+	s.temperature = uint16(temperature) & 0x07ff
+}
+
+// Stale makes the next count reads report the stale data status.
+func (s *Npa700) Stale(count int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stale = count
+}
+
+func (s *Npa700) Read(p []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	status := byte(npaStatusNormal)
+	if s.stale > 0 {
+		s.stale--
+		status = npaStatusStale
+	}
+	data := []byte{
+		status<<6 | byte(s.pressure>>8),
+		byte(s.pressure),
+		byte(s.temperature >> 3),
+		byte(s.temperature << 5),
+	}
+	for i := range p {
+		if i < len(data) {
+			p[i] = data[i]
+		} else {
+			p[i] = 0xff
+		}
+	}
+	return nil
+}
+
+func (s *Npa700) Write(p []byte) error {
+	return nil
+}
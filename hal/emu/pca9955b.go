@@ -0,0 +1,78 @@
+package emu
+
+import "sync"
+
+const (
+	pcaMode1     = 0x00
+	pcaMode2     = 0x01
+	pcaPwm0      = 0x08
+	pcaRegisters = 0x50
+	pcaAutoInc   = 0x80
+)
+
+// Pca9955b emulates the PCA9955B LED driver register file, including the
+// auto-increment flag of the control register and the general call reset.
+type Pca9955b struct {
+	mutex     sync.Mutex
+	registers [pcaRegisters]byte
+	pointer   byte
+	autoInc   bool
+}
+
+func NewPca9955b() *Pca9955b {
+	p := &Pca9955b{}
+	p.Reset()
+	return p
+}
+
+func (d *Pca9955b) Reset() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.registers = [pcaRegisters]byte{}
+	d.registers[pcaMode1] = 0x89
+	d.registers[pcaMode2] = 0x05
+	d.pointer = 0
+	d.autoInc = false
+}
+
+// Output returns the PWM duty cycle last written to channel.
+func (d *Pca9955b) Output(channel uint8) byte {
+	return d.Register(pcaPwm0 + channel)
+}
+
+func (d *Pca9955b) Register(reg byte) byte {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.registers[reg%pcaRegisters]
+}
+
+func (d *Pca9955b) next() {
+	if d.autoInc {
+		d.pointer = (d.pointer + 1) % pcaRegisters
+	}
+}
+
+func (d *Pca9955b) Write(p []byte) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if len(p) == 0 {
+		return nil
+	}
+	d.autoInc = p[0]&pcaAutoInc != 0
+	d.pointer = (p[0] &^ pcaAutoInc) % pcaRegisters
+	for _, b := range p[1:] {
+		d.registers[d.pointer] = b
+		d.next()
+	}
+	return nil
+}
+
+func (d *Pca9955b) Read(p []byte) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for i := range p {
+		p[i] = d.registers[d.pointer]
+		d.next()
+	}
+	return nil
+}
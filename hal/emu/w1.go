@@ -0,0 +1,143 @@
+package emu
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/zlowred/embd"
+)
+
+var ErrNoDevice = errors.New("emu: one-wire device not present")
+
+// W1Slave is a device attached to an emulated one-wire bus.
+type W1Slave interface {
+	Read(p []byte) error
+	Write(p []byte) error
+}
+
+// W1Bus implements embd.W1Bus on top of emulated slaves keyed by their
+// sysfs id, e.g. "28-011572120bff".
+type W1Bus struct {
+	mutex   sync.Mutex
+	devices map[string]W1Slave
+	nacks   map[string]int
+	opened  int
+}
+
+func NewW1Bus() *W1Bus {
+	return &W1Bus{devices: make(map[string]W1Slave), nacks: make(map[string]int)}
+}
+
+func (b *W1Bus) Attach(id string, dev W1Slave) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.devices[id] = dev
+}
+
+func (b *W1Bus) Detach(id string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.devices, id)
+}
+
+// Nack makes the next count transfers to id fail as if the device did not
+// answer the reset pulse. A negative count keeps failing until Nack(id, 0).
+func (b *W1Bus) Nack(id string, count int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if count == 0 {
+		delete(b.nacks, id)
+	} else {
+		b.nacks[id] = count
+	}
+}
+
+// Opened returns how many times a device has been opened on this bus.
+func (b *W1Bus) Opened() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.opened
+}
+
+func (b *W1Bus) ListDevices() ([]string, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	res := make([]string, 0, len(b.devices))
+	for id := range b.devices {
+		res = append(res, id)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func (b *W1Bus) Open(address string) (embd.W1Device, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if _, ok := b.devices[address]; !ok {
+		return nil, ErrNoDevice
+	}
+	b.opened++
+	return &w1Device{bus: b, id: address}, nil
+}
+
+func (b *W1Bus) transfer(id string, f func(dev W1Slave) error) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if n, ok := b.nacks[id]; ok {
+		if n > 0 {
+			n--
+			if n == 0 {
+				delete(b.nacks, id)
+			} else {
+				b.nacks[id] = n
+			}
+		}
+		return ErrNoDevice
+	}
+	dev, ok := b.devices[id]
+	if !ok {
+		return ErrNoDevice
+	}
+	return f(dev)
+}
+
+type w1Device struct {
+	bus    *W1Bus
+	id     string
+	closed bool
+}
+
+func (d *w1Device) ReadByte() (byte, error) {
+	p, err := d.ReadBytes(1)
+	return p[0], err
+}
+
+func (d *w1Device) ReadBytes(n int) ([]byte, error) {
+	p := make([]byte, n)
+	if d.closed {
+		return p, ErrClosed
+	}
+	err := d.bus.transfer(d.id, func(dev W1Slave) error {
+		return dev.Read(p)
+	})
+	return p, err
+}
+
+func (d *w1Device) WriteByte(b byte) error {
+	return d.WriteBytes([]byte{b})
+}
+
+func (d *w1Device) WriteBytes(p []byte) error {
+	if d.closed {
+		return ErrClosed
+	}
+	return d.bus.transfer(d.id, func(dev W1Slave) error {
+		return dev.Write(p)
+	})
+}
+
+func (d *w1Device) Close() error {
+	d.closed = true
+	return nil
+}
@@ -0,0 +1,324 @@
+package hal
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zlowred/embd"
+	"github.com/zlowred/embd/controller/pca9955b"
+	"github.com/zlowred/embd/convertors/ads1115"
+	"github.com/zlowred/embd/sensor/ds18b20"
+	"github.com/zlowred/embd/sensor/npa700"
+
+	"github.com/zlowred/alcobot/bus"
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/debuglog"
+	"github.com/zlowred/alcobot/hal/emu"
+	"github.com/zlowred/alcobot/hub"
+	"github.com/zlowred/alcobot/plant"
+)
+
+type Hal struct {
+	pwm    *bus.Subscription[hub.PwmValue]
+	config *bus.Subscription[*config.Configuration]
+
+	hub *hub.Hub
+
+	i2c embd.I2CBus
+	w1  embd.W1Bus
+
+	newW1Bus func() embd.W1Bus
+	closeW1  func() error
+	closeI2C func() error
+	// simulate drives the emulated devices, if any.
+	simulate func(context.Context)
+
+	conf            *config.Configuration
+	fermenterSensor string
+}
+
+const (
+	npaSource = "npa700@0x28"
+	adsSource = "ads1115@0x48"
+)
+
+var (
+	npaInterval      = time.Millisecond * 200
+	adsInterval      = time.Millisecond * 50
+	dsInterval       = time.Millisecond * 200
+	w1ReconnectDelay = time.Second
+)
+
+// simulatedSensor is the one-wire id of the emulated fermenter thermometer.
+const simulatedSensor = "28-000000000051"
+
+// ListW1Devices lists the DS18B20 thermometers on the one-wire bus of the
+// HAL. Without hardware, a nil HAL, there are none.
+func (hal *Hal) ListW1Devices() []string {
+	if hal == nil {
+		return []string{}
+	}
+	return listW1Devices(hal.newW1Bus())
+}
+
+func listW1Devices(w1 embd.W1Bus) []string {
+	res := make([]string, 0)
+
+	devs, err := w1.ListDevices()
+
+	if err != nil {
+		return res
+	}
+
+	for _, dev := range devs {
+		if strings.HasPrefix(dev, "28-") {
+			res = append(res, dev)
+		}
+	}
+	return res
+}
+
+// NewSimulated runs the pollers against emulated devices instead of the
+// Raspberry Pi buses: the fermenter temperature follows m for the power
+// the heat pump drives, the other sensors report fixed readings and the
+// outputs go nowhere.
+func NewSimulated(h *hub.Hub, m plant.Model) *Hal {
+	i2c := emu.NewI2CBus()
+	i2c.Attach(0x28, emu.NewNpa700(8192, 1024))
+	i2c.Attach(0x48, emu.NewAds1115(1000))
+	i2c.Attach(0x0B, emu.NewPca9955b())
+	w1 := emu.NewW1Bus()
+	ds := emu.NewDs18b20(conv.CtoDs(m.Ambient))
+	w1.Attach(simulatedSensor, ds)
+
+	hal := newHal(h, i2c, func() embd.W1Bus { return w1 }, i2c.Close, func() error { return nil })
+	sim := plant.NewSimulator(m)
+	hal.simulate = func(ctx context.Context) { sim.Run(ctx, h, ds.Set) }
+	return hal
+}
+
+func newHal(h *hub.Hub, i2c embd.I2CBus, newW1Bus func() embd.W1Bus, closeI2C func() error, closeW1 func() error) *Hal {
+	hal := &Hal{pwm: h.PwmOutput.Subscribe(), config: h.Configuration.Subscribe(), hub: h,
+		i2c: i2c, newW1Bus: newW1Bus, closeI2C: closeI2C, closeW1: closeW1}
+
+	for i := 0; i < 10; i++ {
+		hal.i2c.WriteByte(0, 6)
+	}
+
+	hal.w1 = hal.newW1Bus()
+
+	return hal
+}
+
+// Run polls the sensors and drives the outputs until ctx is done. It then
+// switches every output off, resets the I2C devices and closes the buses.
+func (hal *Hal) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	pollers := []func(context.Context){hal.npaPoller, hal.adsPoller, hal.pcaUpdater, hal.configChange}
+	if hal.simulate != nil {
+		pollers = append(pollers, hal.simulate)
+	}
+	for _, f := range pollers {
+		wg.Add(1)
+		go func(f func(context.Context)) {
+			defer wg.Done()
+			f(ctx)
+		}(f)
+	}
+	wg.Wait()
+
+	hal.pwm.Close()
+	hal.config.Close()
+	hal.ResetI2C()
+	hal.closeI2C()
+	hal.closeW1()
+}
+
+func (h *Hal) ResetI2C() {
+	for i := 0; i < 10; i++ {
+		h.i2c.WriteByte(byte(0), byte(6))
+	}
+}
+
+func (hal *Hal) configChange(ctx context.Context) {
+	stop := func() {}
+	done := make(chan struct{})
+	close(done)
+
+	for {
+		select {
+		case conf := <-hal.config.C:
+			if hal.conf != nil && hal.fermenterSensor == conf.FermenterSensor {
+				continue
+			}
+			stop()
+			<-done
+			hal.fermenterSensor = conf.FermenterSensor
+			hal.conf = conf
+
+			var dsCtx context.Context
+			dsCtx, stop = context.WithCancel(ctx)
+			done = make(chan struct{})
+			go func(done chan struct{}) {
+				defer close(done)
+				hal.dsPoller(dsCtx)
+			}(done)
+		case <-ctx.Done():
+			stop()
+			<-done
+			return
+		}
+
+	}
+}
+
+func (hal *Hal) npaPoller(ctx context.Context) {
+	sensor := npa700.New(hal.i2c, 0x28)
+	var quality hub.Quality
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			time.Sleep(npaInterval)
+			if err := sensor.Read(); err != nil {
+				log.Printf("NPA error %v", err)
+				sensor = npa700.New(hal.i2c, 0x28)
+				quality = hub.Recovered
+				continue
+			}
+			hal.hub.NpaTemperatureSensor.Send(hub.NewSample(sensor.RawTemperature, npaSource, quality))
+			hal.hub.NpaPressureSensor.Send(hub.NewSample(sensor.RawPressure, npaSource, quality))
+			quality = 0
+		}
+	}
+}
+
+func (hal *Hal) adsPoller(ctx context.Context) {
+	sensor := ads1115.New(hal.i2c, 0x48)
+	var quality hub.Quality
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			time.Sleep(adsInterval)
+			if res, err := sensor.Read(); err != nil {
+				log.Printf("ADS error %v", err)
+				sensor = ads1115.New(hal.i2c, 0x48)
+				quality = hub.Recovered
+				continue
+			} else {
+				hal.hub.AdsValueSensor.Send(hub.NewSample(int16(res>>1), adsSource, quality))
+				quality = 0
+			}
+		}
+	}
+}
+
+func (hal *Hal) dsPoller(ctx context.Context) {
+	debuglog.Println("Starting DS Poller")
+	if hal.conf == nil {
+		log.Println("No conf")
+		return
+	}
+	if len(hal.conf.FermenterSensor) == 0 {
+		log.Println("No sensor")
+		return
+	}
+
+	w1d, err := hal.w1.Open(hal.conf.FermenterSensor)
+
+	if err != nil {
+		log.Printf("W1 device [%v] not found\n", hal.conf.FermenterSensor)
+		return
+	}
+	debuglog.Printf("Using W1 device [%v]\n", hal.conf.FermenterSensor)
+
+	sensor := ds18b20.New(w1d)
+
+	//err = sensor.SetResolution(ds18b20.Resolution_12bit)
+	//if err != nil {
+	//	log.Printf("[%v] set resolution failed: %v\n", hal.conf.FermenterSensor, err)
+	//}
+
+	errors := 0
+	initialized := false
+	var quality hub.Quality
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			time.Sleep(dsInterval)
+			err = sensor.ReadTemperature()
+
+			if err != nil || sensor.Raw == int16(-1) {
+				errors++
+				quality = hub.Recovered
+			} else {
+				errors = 0
+			}
+			if errors > 10 {
+				errors = 0
+				log.Printf("DS error %v", err)
+				hal.closeW1()
+				time.Sleep(w1ReconnectDelay)
+				hal.w1 = hal.newW1Bus()
+				w1d, err = hal.w1.Open(hal.conf.FermenterSensor)
+
+				if err != nil {
+					log.Printf("W1 device [%v] not found\n", hal.conf.FermenterSensor)
+					return
+				}
+				sensor = ds18b20.New(w1d)
+
+				err = sensor.SetResolution(ds18b20.Resolution_12bit)
+				if err != nil {
+					log.Printf("[%v] set resolution failed: %v\n", hal.conf.FermenterSensor, err)
+				}
+				continue
+			}
+			if errors == 0 {
+				sample := hub.NewSample(sensor.Raw, hal.conf.FermenterSensor, quality)
+				hal.hub.DsTemperatureSensor.Send(sample)
+				quality = 0
+				if !initialized {
+					sample.Quality |= hub.Primed
+					for i := 0; i < 100; i++ {
+						hal.hub.DsTemperatureSensor.Send(sample)
+					}
+					initialized = true
+				}
+			}
+		}
+	}
+}
+
+func (hal *Hal) pcaUpdater(ctx context.Context) {
+	pwm := pca9955b.New(hal.i2c, 0x0B)
+
+	for {
+		select {
+		case <-ctx.Done():
+			for channel := uint8(0); channel < 16; channel++ {
+				if err := pwm.SetOutput(channel, 0); err != nil {
+					log.Printf("PCA error %v", err)
+				}
+			}
+			return
+		case x := <-hal.pwm.C:
+			if err := pwm.SetOutput(x.Channel, x.Value); err != nil {
+				log.Printf("PCA error %v", err)
+				pwm = pca9955b.New(hal.i2c, 0x0B)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package hal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/embd"
+
+	"github.com/zlowred/alcobot/bus"
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/hal/emu"
+	"github.com/zlowred/alcobot/hub"
+	"github.com/zlowred/alcobot/plant"
+)
+
+const sensorId = "28-011572120bff"
+
+type rig struct {
+	hub *hub.Hub
+	i2c *emu.I2CBus
+	w1  *emu.W1Bus
+	npa *emu.Npa700
+	ads *emu.Ads1115
+	pca *emu.Pca9955b
+	ds  *emu.Ds18b20
+	hal *Hal
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func init() {
+	npaInterval = time.Millisecond
+	adsInterval = time.Millisecond
+	dsInterval = time.Millisecond
+	w1ReconnectDelay = time.Millisecond
+}
+
+func newRig() *rig {
+	h := &hub.Hub{
+		NpaTemperatureSensor: bus.NewTopic[hub.Sample]("npa-t"), NpaPressureSensor: bus.NewTopic[hub.Sample]("npa-p"),
+		DsTemperatureSensor: bus.NewTopic[hub.Sample]("ds"), AdsValueSensor: bus.NewTopic[hub.Sample]("ads"),
+		PwmOutput: bus.NewTopic[hub.PwmValue]("pwm"), Configuration: bus.NewTopic[*config.Configuration]("config")}
+	r := &rig{hub: h, i2c: emu.NewI2CBus(), w1: emu.NewW1Bus(),
+		npa: emu.NewNpa700(8192, 1024), ads: emu.NewAds1115(1000), pca: emu.NewPca9955b(), ds: emu.NewDs18b20(338)}
+	r.i2c.Attach(0x28, r.npa)
+	r.i2c.Attach(0x48, r.ads)
+	r.i2c.Attach(0x0B, r.pca)
+	r.w1.Attach(sensorId, r.ds)
+	r.hal = newHal(h, r.i2c, func() embd.W1Bus { return r.w1 }, r.i2c.Close, func() error { return nil })
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		r.hal.Run(r.ctx)
+	}()
+	return r
+}
+
+func (r *rig) stop() {
+	r.cancel()
+	<-r.done
+}
+
+func recv(t *testing.T, ch <-chan hub.Sample) hub.Sample {
+	select {
+	case x := <-ch:
+		return x
+	case <-time.After(time.Second):
+		t.Fatal("no value received")
+		return hub.Sample{}
+	}
+}
+
+func recvInt16(t *testing.T, ch <-chan hub.Sample) int16 {
+	return recv(t, ch).Value
+}
+
+func TestListW1DevicesOnlyThermometers(t *testing.T) {
+	w1 := emu.NewW1Bus()
+	w1.Attach("28-0000075e1c27", emu.NewDs18b20(0))
+	w1.Attach("10-000802b4c9a2", emu.NewDs18b20(0))
+	w1.Attach(sensorId, emu.NewDs18b20(0))
+	assert.Equal(t, []string{"28-0000075e1c27", sensorId}, listW1Devices(w1))
+}
+
+func TestListW1DevicesEmptyBus(t *testing.T) {
+	assert.Equal(t, []string{}, listW1Devices(emu.NewW1Bus()))
+}
+
+func TestNpaPoller(t *testing.T) {
+	r := newRig()
+	defer r.stop()
+	pressure := r.hub.NpaPressureSensor.JoinContext(r.ctx)
+	temperature := r.hub.NpaTemperatureSensor.JoinContext(r.ctx)
+	assert.Equal(t, int16(1024), recvInt16(t, temperature))
+	x := recv(t, pressure)
+	assert.Equal(t, int16(8192), x.Value)
+	assert.Equal(t, npaSource, x.Source)
+	assert.WithinDuration(t, time.Now(), x.Time, time.Second)
+}
+
+func TestNpaPollerSurvivesNack(t *testing.T) {
+	r := newRig()
+	defer r.stop()
+	r.i2c.Nack(0x28, -1)
+	pressure := r.hub.NpaPressureSensor.JoinContext(r.ctx)
+	temperature := r.hub.NpaTemperatureSensor.JoinContext(r.ctx)
+	select {
+	case <-pressure:
+		t.Fatal("reading published while sensor is not acknowledging")
+	case <-time.After(time.Millisecond * 50):
+	}
+	r.npa.Set(9000, 1000)
+	r.i2c.Nack(0x28, 0)
+	x := recv(t, temperature)
+	assert.Equal(t, int16(1000), x.Value)
+	assert.Equal(t, hub.Recovered, x.Quality)
+	x = recv(t, pressure)
+	assert.Equal(t, int16(9000), x.Value)
+	assert.Equal(t, hub.Recovered, x.Quality)
+	assert.Equal(t, hub.Quality(0), recv(t, pressure).Quality)
+}
+
+func TestAdsPoller(t *testing.T) {
+	r := newRig()
+	defer r.stop()
+	ads := r.hub.AdsValueSensor.JoinContext(r.ctx)
+	assert.Equal(t, int16(500), recvInt16(t, ads))
+}
+
+func TestAdsPollerSurvivesNack(t *testing.T) {
+	r := newRig()
+	defer r.stop()
+	r.i2c.Nack(0x48, 5)
+	r.ads.Set(-2000)
+	ads := r.hub.AdsValueSensor.JoinContext(r.ctx)
+	x := recvInt16(t, ads)
+	for x == 500 {
+		x = recvInt16(t, ads)
+	}
+	assert.Equal(t, int16(-1000), x)
+}
+
+func TestPcaUpdater(t *testing.T) {
+	r := newRig()
+	defer r.stop()
+	r.i2c.Nack(0x0B, 1)
+	r.hub.PwmOutput.Send(hub.PwmValue{Channel: 4, Value: 10})
+	r.hub.PwmOutput.Send(hub.PwmValue{Channel: 3, Value: 128})
+	r.hub.PwmOutput.Send(hub.PwmValue{Channel: 15, Value: 255})
+	r.hub.PwmOutput.Send(hub.PwmValue{Channel: 5, Value: 20})
+	assert.Eventually(t, func() bool { return r.pca.Output(5) == 20 }, time.Second, time.Millisecond)
+	assert.Equal(t, byte(128), r.pca.Output(3))
+	assert.Equal(t, byte(255), r.pca.Output(15))
+	assert.Equal(t, byte(0), r.pca.Output(4))
+}
+
+func TestDsPollerWaitsForSensor(t *testing.T) {
+	r := newRig()
+	defer r.stop()
+	ds := r.hub.DsTemperatureSensor.JoinContext(r.ctx)
+	r.hub.Configuration.Send(&config.Configuration{})
+	select {
+	case <-ds:
+		t.Fatal("reading published without a configured sensor")
+	case <-time.After(time.Millisecond * 50):
+	}
+}
+
+func TestDsPoller(t *testing.T) {
+	r := newRig()
+	defer r.stop()
+	ds := r.hub.DsTemperatureSensor.JoinContext(r.ctx)
+	r.hub.Configuration.Send(&config.Configuration{FermenterSensor: sensorId})
+	first := recv(t, ds)
+	assert.Equal(t, int16(338), first.Value)
+	assert.Equal(t, sensorId, first.Source)
+	assert.Equal(t, hub.Quality(0), first.Quality)
+	for i := 0; i < 100; i++ {
+		x := recv(t, ds)
+		assert.Equal(t, int16(338), x.Value)
+		assert.Equal(t, hub.Primed, x.Quality)
+		assert.Equal(t, first.Time, x.Time)
+	}
+	assert.Equal(t, hub.Quality(0), recv(t, ds).Quality)
+}
+
+func TestDsPollerSkipsBadReadings(t *testing.T) {
+	r := newRig()
+	defer r.stop()
+	r.ds.MinusOne(3)
+	r.ds.CrcErrors(3)
+	ds := r.hub.DsTemperatureSensor.JoinContext(r.ctx)
+	r.hub.Configuration.Send(&config.Configuration{FermenterSensor: sensorId})
+	for i := 0; i < 110; i++ {
+		assert.Equal(t, int16(338), recvInt16(t, ds))
+	}
+	assert.Equal(t, 1, r.w1.Opened())
+}
+
+func TestDsPollerReconnects(t *testing.T) {
+	r := newRig()
+	defer r.stop()
+	r.w1.Nack(sensorId, 11)
+	ds := r.hub.DsTemperatureSensor.JoinContext(r.ctx)
+	r.hub.Configuration.Send(&config.Configuration{FermenterSensor: sensorId})
+	assert.Equal(t, int16(338), recvInt16(t, ds))
+	assert.Equal(t, 2, r.w1.Opened())
+}
+
+func TestDsPollerGivesUpOnMissingSensor(t *testing.T) {
+	r := newRig()
+	defer r.stop()
+	r.w1.Nack(sensorId, -1)
+	r.hub.Configuration.Send(&config.Configuration{FermenterSensor: sensorId})
+	assert.Eventually(t, func() bool { return r.w1.Opened() > 1 }, time.Second, time.Millisecond)
+	r.w1.Detach(sensorId)
+	opened := r.w1.Opened()
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, opened, r.w1.Opened())
+}
+
+func TestRunStopsPollersAndSafesOutputs(t *testing.T) {
+	r := newRig()
+	ads := r.hub.AdsValueSensor.JoinContext(r.ctx)
+	recvInt16(t, ads)
+	r.hub.PwmOutput.Send(hub.PwmValue{Channel: 2, Value: 200})
+	assert.Eventually(t, func() bool { return r.pca.Output(2) == 200 }, time.Second, time.Millisecond)
+	r.stop()
+	assert.Equal(t, byte(0), r.pca.Output(2))
+	_, err := r.i2c.ReadByte(0x28)
+	assert.Equal(t, emu.ErrClosed, err)
+}
+
+func TestNewSimulated(t *testing.T) {
+	h := &hub.Hub{
+		NpaTemperatureSensor: bus.NewTopic[hub.Sample]("npa-t"), NpaPressureSensor: bus.NewTopic[hub.Sample]("npa-p"),
+		DsTemperatureSensor: bus.NewTopic[hub.Sample]("ds"), AdsValueSensor: bus.NewTopic[hub.Sample]("ads"),
+		PwmOutput: bus.NewTopic[hub.PwmValue]("pwm"), Configuration: bus.NewTopic[*config.Configuration]("config"),
+		AdjustedPidOutput: bus.NewTopic[float64]("power")}
+	hal := NewSimulated(h, plant.Model{Gain: 0.1, TimeConstant: time.Hour, Ambient: 21.125})
+	assert.Equal(t, []string{simulatedSensor}, hal.ListW1Devices())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	pressure := h.NpaPressureSensor.JoinContext(ctx)
+	temperature := h.DsTemperatureSensor.JoinContext(ctx)
+	go func() {
+		defer close(done)
+		hal.Run(ctx)
+	}()
+	h.Configuration.Send(&config.Configuration{FermenterSensor: simulatedSensor})
+	assert.Equal(t, int16(8192), recvInt16(t, pressure))
+	assert.Equal(t, int16(338), recvInt16(t, temperature))
+	cancel()
+	<-done
+}
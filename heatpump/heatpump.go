@@ -1,6 +1,7 @@
 package heatpump
 
 import (
+	"context"
 	"math"
 	"time"
 
@@ -19,7 +20,6 @@ type HeatPump struct {
 
 func New(h *hub.Hub) *HeatPump {
 	heatPump := &HeatPump{hub: h, timer: time.Now(), current: 0, target: 0, enabled: false}
-	go heatPump.loop()
 	return heatPump
 }
 
@@ -61,14 +61,26 @@ func (p *HeatPump) setPwm() {
 	}
 }
 
-func (p *HeatPump) loop() {
-	pidOutputCh := hub.JoinFloat64Group(p.hub.PidOutput)
-	configCh := hub.JoinConfigGroup(p.hub.Configuration)
+// safe turns every output off.
+func (p *HeatPump) safe() {
+	p.current, p.target = 0, 0
+	p.hub.AdjustedPidOutput.Send(0)
+	for _, channel := range []uint8{0, 1, 2, 3, 4, 5, 6, 7, 15} {
+		p.hub.PwmOutput.Send(hub.PwmValue{Channel: channel, Value: 0})
+	}
+}
+
+// Run ramps the outputs towards the PID output until ctx is done and then
+// turns them off.
+func (p *HeatPump) Run(ctx context.Context) {
+	pidOutputCh := p.hub.PidOutput.JoinContext(ctx)
+	configCh := p.hub.Configuration.JoinContext(ctx)
 	t := time.NewTicker(time.Second)
+	defer t.Stop()
 	for {
 		select {
 		case <-t.C:
-			if p.conf == nil {
+			if p.conf == nil || p.conf.Stage == config.DONE {
 				continue
 			}
 			if math.Abs(p.current-p.target) < p.conf.PidSlope {
@@ -80,11 +92,16 @@ func (p *HeatPump) loop() {
 			}
 			p.setPwm()
 		case c := <-configCh:
+			if c.Stage == config.DONE && (p.conf == nil || p.conf.Stage != config.DONE) {
+				// the brew is over, leave the outputs off
+				p.safe()
+			}
 			p.conf = c
 		case v := <-pidOutputCh:
 			p.enabled = true
 			p.target = v
-		case <-p.hub.Quit:
+		case <-ctx.Done():
+			p.safe()
 			return
 		}
 	}
@@ -0,0 +1,84 @@
+package hub
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// StorageAlarm is raised while the storage is degraded.
+	StorageAlarm = "storage"
+	// HardwareAlarm is raised when the hardware can't be opened.
+	HardwareAlarm = "hardware"
+	// ControlAlarm is raised when the temperature control can't run.
+	ControlAlarm = "control"
+)
+
+// Alarm is a condition that needs the brewer's attention. It is published
+// on the Alarm topic when a rule raises it and again when it clears.
+type Alarm struct {
+	Rule    string
+	Message string
+	Raised  bool
+	Since   time.Time
+}
+
+type alarms struct {
+	sync.Mutex
+	raised map[string]Alarm
+}
+
+// Raise raises the alarm of rule. Raising it again while it is raised only
+// updates the message, without publishing it again.
+func (h *Hub) Raise(rule, message string) {
+	h.alarms.Lock()
+	a, ok := h.alarms.raised[rule]
+	if !ok {
+		a = Alarm{rule, message, true, time.Now()}
+	}
+	a.Message = message
+	if h.alarms.raised == nil {
+		h.alarms.raised = make(map[string]Alarm)
+	}
+	h.alarms.raised[rule] = a
+	h.alarms.Unlock()
+
+	if !ok {
+		log.Printf("Alarm %v: %v\n", rule, message)
+		if h.Alarm != nil {
+			h.Alarm.Send(a)
+		}
+	}
+}
+
+// Clear clears the alarm of rule if it is raised.
+func (h *Hub) Clear(rule string) {
+	h.alarms.Lock()
+	a, ok := h.alarms.raised[rule]
+	delete(h.alarms.raised, rule)
+	h.alarms.Unlock()
+
+	if ok {
+		log.Printf("Alarm %v cleared after %v\n", rule, time.Since(a.Since))
+		a.Raised = false
+		if h.Alarm != nil {
+			h.Alarm.Send(a)
+		}
+	}
+}
+
+// Alarms returns the raised alarms, oldest first.
+func (h *Hub) Alarms() []Alarm {
+	h.alarms.Lock()
+	defer h.alarms.Unlock()
+	raised := make([]Alarm, 0, len(h.alarms.raised))
+	for _, a := range h.alarms.raised {
+		raised = append(raised, a)
+	}
+	sort.Slice(raised, func(i, j int) bool {
+		return raised[i].Since.Before(raised[j].Since)
+	})
+	return raised
+}
@@ -0,0 +1,35 @@
+package hub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRaiseAndClear(t *testing.T) {
+	h := newTestHub(t)
+	alarms := h.Alarm.Subscribe()
+	defer alarms.Close()
+
+	h.Raise("stall", "SG stuck")
+	a := <-alarms.C
+	assert.True(t, a.Raised)
+	assert.Equal(t, "stall", a.Rule)
+
+	// raising it again only updates the message
+	h.Raise("stall", "SG still stuck")
+	h.Raise("lag", "SG not falling")
+	a = <-alarms.C
+	assert.Equal(t, "lag", a.Rule)
+	if assert.Len(t, h.Alarms(), 2) {
+		assert.Equal(t, "SG still stuck", h.Alarms()[0].Message)
+	}
+
+	h.Clear("stall")
+	a = <-alarms.C
+	assert.False(t, a.Raised)
+	assert.Equal(t, "stall", a.Rule)
+	h.Clear("stall")
+	assert.Equal(t, 0, alarms.Pending())
+	assert.Len(t, h.Alarms(), 1)
+}
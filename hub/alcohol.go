@@ -0,0 +1,80 @@
+package hub
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+)
+
+// ogMaxAge is how old the gravity estimate may be to be taken as the OG at
+// pitch time.
+const ogMaxAge = time.Minute
+
+// Alcohol is how far fermentation got from the OG of a brew: the apparent
+// and real attenuation and the ABV by the common and alternate formulas,
+// all in %. They are NaN while the OG or the SG is unknown.
+type Alcohol struct {
+	ApparentAttenuation float64
+	RealAttenuation     float64
+	ABV                 float64
+	ABVAlternate        float64
+}
+
+func NewAlcohol(og, sg float64) Alcohol {
+	if og <= 1 || math.IsNaN(sg) {
+		return Alcohol{math.NaN(), math.NaN(), math.NaN(), math.NaN()}
+	}
+	return Alcohol{conv.ApparentAttenuation(og, sg), conv.RealAttenuation(og, sg), conv.ABV(og, sg), conv.ABVAlternate(og, sg)}
+}
+
+// SetOG overrides the OG of the current brew, e.g. with a hydrometer
+// reading; 0 clears it.
+func (h *Hub) SetOG(og float64) error {
+	if og != 0 && (og < 1 || og >= 1.2) {
+		return fmt.Errorf("invalid OG %v", og)
+	}
+	h.dbLock.Lock()
+	conf, err := h.setOG(og)
+	h.dbLock.Unlock()
+	if err != nil {
+		return h.report(err)
+	}
+
+	log.Printf("Brew %d: OG set to %.4f\n", conf.Id, og)
+	h.Configuration.Send(conf)
+	return nil
+}
+
+func (h *Hub) setOG(og float64) (*config.Configuration, error) {
+	return h.changeConfig(func(conf *config.Configuration) { conf.OG = og })
+}
+
+// latestGravity is the latest gravity estimate, for the OG at pitch time.
+type latestGravity struct {
+	sync.Mutex
+	last Gravity
+}
+
+// setGravity keeps the latest gravity estimate for the OG at pitch time.
+func (h *Hub) setGravity(g Gravity) {
+	h.gravity.Lock()
+	h.gravity.last = g
+	h.gravity.Unlock()
+}
+
+// pitchOG is the current SG rounded to 0.0001, if there is a recent
+// estimate.
+func (h *Hub) pitchOG(now time.Time) (float64, bool) {
+	h.gravity.Lock()
+	g := h.gravity.last
+	h.gravity.Unlock()
+	if g.Time.IsZero() || now.Sub(g.Time) > ogMaxAge || math.IsNaN(g.SG) {
+		return 0, false
+	}
+	return math.Round(g.SG*10000) / 10000, true
+}
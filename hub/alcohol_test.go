@@ -0,0 +1,49 @@
+package hub
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAlcohol(t *testing.T) {
+	a := NewAlcohol(1.050, 1.010)
+	assert.InDelta(t, 80, a.ApparentAttenuation, 0.001)
+	assert.InDelta(t, 65.0, a.RealAttenuation, 0.1)
+	assert.InDelta(t, 5.25, a.ABV, 0.001)
+	assert.InDelta(t, 5.33, a.ABVAlternate, 0.01)
+
+	assert.True(t, math.IsNaN(NewAlcohol(0, 1.010).ABV))
+	assert.True(t, math.IsNaN(NewAlcohol(1.050, math.NaN()).ApparentAttenuation))
+}
+
+func TestOGAtPitch(t *testing.T) {
+	h := newTestHub(t)
+	assert.NoError(t, h.Plan("Planned", 1.048, nil))
+	assert.NoError(t, h.Transition(Prepare))
+	h.setGravity(Gravity{SG: 1.05123, Time: time.Now()})
+	assert.NoError(t, h.Transition(Pitch))
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0512, conf.OG)
+
+	assert.NoError(t, h.SetOG(1.052))
+	conf, err = h.latestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 1.052, conf.OG)
+	assert.Error(t, h.SetOG(0.99))
+	assert.Error(t, h.SetOG(12))
+}
+
+func TestStaleGravityKeepsPlannedOG(t *testing.T) {
+	h := newTestHub(t)
+	assert.NoError(t, h.Plan("Planned", 1.048, nil))
+	assert.NoError(t, h.Transition(Prepare))
+	h.setGravity(Gravity{SG: 1.051, Time: time.Now().Add(-ogMaxAge - time.Second)})
+	assert.NoError(t, h.Transition(Pitch))
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 1.048, conf.OG)
+}
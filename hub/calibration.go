@@ -0,0 +1,266 @@
+package hub
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+)
+
+// Config returns the stored configuration of the current brew.
+func (h *Hub) Config() (*config.Configuration, error) {
+	h.dbLock.Lock()
+	defer h.dbLock.Unlock()
+	return h.latestConfig()
+}
+
+// SetNpaDrift sets how the NPA offset drifts with its die temperature, in
+// Pa/ºC.
+func (h *Hub) SetNpaDrift(drift float64) error {
+	if math.IsNaN(drift) || math.IsInf(drift, 0) {
+		return errors.New("invalid NPA drift")
+	}
+	h.dbLock.Lock()
+	conf, err := h.changeConfig(func(conf *config.Configuration) { conf.NpaDrift = drift })
+	h.dbLock.Unlock()
+	if err != nil {
+		return h.report(err)
+	}
+
+	log.Printf("NPA drift set to %.2f Pa/ºC\n", drift)
+	h.Configuration.Send(conf)
+	return nil
+}
+
+// changeConfig applies change to the configuration of the current brew,
+// stores it and returns it. The caller holds dbLock.
+func (h *Hub) changeConfig(change func(conf *config.Configuration)) (*config.Configuration, error) {
+	conf, err := h.current()
+	if err != nil {
+		return nil, err
+	}
+	change(conf)
+	tx, err := h.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := updateConfig(tx, conf); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return conf, tx.Commit()
+}
+
+// calibrationMaxAge is how old the compensated pressure may be to take a
+// calibration point.
+const calibrationMaxAge = time.Minute
+
+// Pressure is the hydrostatic pressure the SG is modelled on, in Pa,
+// compensated for the NPA drift and for the wort temperature, which is in
+// ºC and NaN when unknown.
+type Pressure struct {
+	Pa          float64
+	Temperature float64
+	Time        time.Time
+}
+
+// CalibrationPoint is a known SG, e.g. of water, a sugar solution or a
+// hydrometer reading, and the pressure the bot measured for it.
+type CalibrationPoint struct {
+	Time        time.Time
+	Pressure    float64
+	Temperature float64
+	SG          float64
+	Source      string
+}
+
+// latestPressure is the latest compensated pressure, for calibration
+// points.
+type latestPressure struct {
+	sync.Mutex
+	last Pressure
+}
+
+func (h *Hub) setPressure(p Pressure) {
+	h.pressure.Lock()
+	h.pressure.last = p
+	h.pressure.Unlock()
+}
+
+// AddCalibrationPoint records the current pressure as the one of sg for the
+// current brew.
+func (h *Hub) AddCalibrationPoint(sg float64, source string) (CalibrationPoint, error) {
+	if sg < 0.98 || sg >= 1.2 {
+		return CalibrationPoint{}, fmt.Errorf("invalid SG %v", sg)
+	}
+	h.pressure.Lock()
+	p := h.pressure.last
+	h.pressure.Unlock()
+	now := time.Now()
+	if now.Sub(p.Time) > calibrationMaxAge {
+		return CalibrationPoint{}, errors.New("no recent pressure reading")
+	}
+
+	point := CalibrationPoint{now.UTC(), p.Pa, p.Temperature, sg, source}
+	h.dbLock.Lock()
+	defer h.dbLock.Unlock()
+	conf, err := h.current()
+	if err != nil {
+		return CalibrationPoint{}, h.report(err)
+	}
+	_, err = h.db.Exec(query("insertCalibrationPoint.sql"), conf.Id, point.Time, point.Pressure, point.Temperature, point.SG, point.Source)
+	if err != nil {
+		return CalibrationPoint{}, h.report(err)
+	}
+	log.Printf("Brew %d: calibration point SG %.4f at %.1f Pa (%v)\n", conf.Id, sg, p.Pa, source)
+	return point, h.report(nil)
+}
+
+// CalibrationPoints loads the calibration points of a brew, oldest first.
+func (h *Hub) CalibrationPoints(id int) ([]CalibrationPoint, error) {
+	rows, err := h.db.Query(query("selectCalibrationPoints.sql"), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []CalibrationPoint
+	for rows.Next() {
+		var p CalibrationPoint
+		var temp sql.NullFloat64
+		if err := rows.Scan(&p.Time, &p.Pressure, &temp, &p.SG, &p.Source); err != nil {
+			return nil, err
+		}
+		p.Temperature = nullable(temp)
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// DeleteCalibrationPoint deletes the calibration point of brew id taken at
+// t.
+func (h *Hub) DeleteCalibrationPoint(id int, t time.Time) error {
+	h.dbLock.Lock()
+	defer h.dbLock.Unlock()
+	res, err := h.db.Exec(query("deleteCalibrationPoint.sql"), id, t.UTC())
+	if err != nil {
+		return h.report(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("brew %d has no calibration point at %v", id, t.Format(time.RFC3339Nano))
+	}
+	return h.report(nil)
+}
+
+// ApplySgModel sets the SG model of brew id. Unless correct is nil, it
+// also corrects the recorded SG of the brew, including its rollups, mapping
+// each value through correct with the wort temperature it was recorded at,
+// in ºC and NaN when unknown.
+func (h *Hub) ApplySgModel(id int, model [3]float64, correct func(sg, wort float64) float64) error {
+	h.dbLock.Lock()
+	conf, current, err := h.applySgModel(id, model, correct)
+	h.dbLock.Unlock()
+	if err != nil {
+		return h.report(err)
+	}
+	h.report(nil)
+
+	log.Printf("Brew %d: SG model set to %.6g + %.6g·p + %.6g·p²\n", id, model[0], model[1], model[2])
+	if current {
+		h.Configuration.Send(conf)
+	}
+	return nil
+}
+
+// applySgModel sets the SG model of brew id, on the configuration in memory
+// when it's the current brew. The caller holds dbLock.
+func (h *Hub) applySgModel(id int, model [3]float64, correct func(sg, wort float64) float64) (*config.Configuration, bool, error) {
+	conf, err := h.current()
+	if err != nil {
+		return nil, false, err
+	}
+	current := conf.Id == id
+	if !current {
+		if conf, err = h.SessionConfig(id); err != nil {
+			return nil, false, err
+		}
+	}
+	conf.SgModel = model
+	tx, err := h.db.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	if err := updateConfig(tx, conf); err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+	if correct != nil {
+		if err := correctSG(tx, id, correct); err != nil {
+			tx.Rollback()
+			return nil, false, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	if current {
+		h.Conf = conf
+	}
+	return conf, current, nil
+}
+
+// correctSG maps the recorded SG of brew id through correct. The averages of
+// the rollups are mapped as they are, at their average temperature, which
+// is exact for a linear mapping and close for a gently curved one.
+func correctSG(tx *sql.Tx, id int, correct func(sg, wort float64) float64) error {
+	for _, table := range []struct{ selectSG, updateSG string }{
+		{"selectDataSG.sql", "updateDataSG.sql"},
+		{"selectMinuteSG.sql", "updateMinuteSG.sql"},
+		{"selectHourSG.sql", "updateHourSG.sql"},
+	} {
+		type row struct {
+			key            int64
+			low, avg, high float64
+			wort           float64
+		}
+		rows, err := tx.Query(query(table.selectSG), id)
+		if err != nil {
+			return err
+		}
+		var all []row
+		for rows.Next() {
+			var r row
+			var temp sql.NullFloat64
+			if err := rows.Scan(&r.key, &r.low, &r.avg, &r.high, &temp); err != nil {
+				rows.Close()
+				return err
+			}
+			r.wort = nullable(temp) * conv.DsToC(1)
+			all = append(all, r)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		stmt, err := tx.Prepare(query(table.updateSG))
+		if err != nil {
+			return err
+		}
+		for _, r := range all {
+			low, high := correct(r.low, r.wort), correct(r.high, r.wort)
+			if _, err := stmt.Exec(math.Min(low, high), correct(r.avg, r.wort), math.Max(low, high), r.key, id); err != nil {
+				stmt.Close()
+				return err
+			}
+		}
+		stmt.Close()
+	}
+	return nil
+}
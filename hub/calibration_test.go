@@ -0,0 +1,124 @@
+package hub
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/config"
+)
+
+func TestNpaCompensationCarriesOverToNewBrew(t *testing.T) {
+	h := newTestHub(t)
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	h.Conf = conf
+	h.Conf.Stage = config.DONE
+	h.Conf.NpaCalibrationTemperature, h.Conf.NpaReferenceTemperature = 18.5, 24
+	assert.NoError(t, h.saveConfig())
+	assert.NoError(t, h.SetNpaDrift(-3.5))
+	assert.Error(t, h.SetNpaDrift(math.NaN()))
+
+	assert.NoError(t, h.NewBrew("Next"))
+	conf, err = h.Config()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, conf.Id)
+	assert.Equal(t, 18.5, conf.NpaCalibrationTemperature)
+	assert.Equal(t, 24., conf.NpaReferenceTemperature)
+	assert.Equal(t, -3.5, conf.NpaDrift)
+}
+
+func TestCalibrationPoints(t *testing.T) {
+	h := newTestHub(t)
+	_, err := h.AddCalibrationPoint(1.000, "water")
+	assert.Error(t, err, "no pressure yet")
+
+	h.setPressure(Pressure{Pa: 1000, Temperature: math.NaN(), Time: time.Now()})
+	water, err := h.AddCalibrationPoint(1.000, "water")
+	assert.NoError(t, err)
+	h.setPressure(Pressure{Pa: 1050, Temperature: 19.5, Time: time.Now()})
+	_, err = h.AddCalibrationPoint(1.050, "solution")
+	assert.NoError(t, err)
+	_, err = h.AddCalibrationPoint(0.5, "solution")
+	assert.Error(t, err)
+
+	points, err := h.CalibrationPoints(1)
+	assert.NoError(t, err)
+	if assert.Len(t, points, 2) {
+		assert.True(t, points[0].Time.Equal(water.Time))
+		assert.True(t, math.IsNaN(points[0].Temperature))
+		assert.Equal(t, CalibrationPoint{points[1].Time, 1050, 19.5, 1.050, "solution"}, points[1])
+	}
+
+	assert.NoError(t, h.DeleteCalibrationPoint(1, water.Time))
+	assert.Error(t, h.DeleteCalibrationPoint(1, water.Time))
+	points, err = h.CalibrationPoints(1)
+	assert.NoError(t, err)
+	assert.Len(t, points, 1)
+}
+
+func TestApplySgModelCorrectsHistory(t *testing.T) {
+	h := newTestHub(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	// two hours rolled up by the hour and two by the minute, then raw ones
+	conf.BrewingStartTime = now.Add(-minuteRetention - 2*time.Hour)
+	h.Conf = conf
+	h.saveConfig()
+	var points []*DataPoint
+	for step := 1; step <= 4*3600; step++ {
+		sg := 1.050
+		if step%100 == 0 {
+			sg = math.NaN()
+		}
+		points = append(points, &DataPoint{Id: conf.Id, Step: step, TargetTemp: 20, CurrentTemp: 200, SG: sg, Span: 1})
+	}
+	assert.NoError(t, h.SaveDataPoints(points))
+	h.compact(now)
+	assert.NoError(t, h.SaveDataPoints([]*DataPoint{{Id: conf.Id, Step: 4*3600 + 1, TargetTemp: 20, CurrentTemp: 200, SG: 1.050, Span: 1}}))
+
+	model := [3]float64{0.1, 0.001, 0}
+	assert.NoError(t, h.ApplySgModel(conf.Id, model, func(sg, wort float64) float64 {
+		assert.Equal(t, 12.5, wort)
+		return 1.001*sg - 0.003
+	}))
+	conf, err = h.SessionConfig(conf.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, model, conf.SgModel)
+
+	dps, err := h.SessionDataPoints(conf.Id)
+	assert.NoError(t, err)
+	for _, dp := range dps {
+		if !math.IsNaN(dp.SG) {
+			assert.InDelta(t, 1.04805, dp.SG, 1e-9, "step %d", dp.Step)
+		}
+	}
+	var low, high float64
+	assert.NoError(t, h.db.QueryRow("select SGMin, SGMax from data_minute where id = ? limit 1", conf.Id).Scan(&low, &high))
+	assert.InDelta(t, 1.04805, low, 1e-9)
+	assert.InDelta(t, 1.04805, high, 1e-9)
+
+	assert.NoError(t, h.ApplySgModel(conf.Id, [3]float64{}, nil))
+	dps, err = h.SessionDataPoints(conf.Id)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.04805, dps[0].SG, 1e-9, "no correction without a mapping")
+}
+
+func TestApplySgModelKeepsTheStageInMemory(t *testing.T) {
+	h := newTestHub(t)
+	h.db.SetMaxOpenConns(1)
+	_, err := h.db.Exec("pragma query_only = 1")
+	assert.NoError(t, err)
+	assert.NoError(t, h.Transition(Prepare))
+	_, err = h.db.Exec("pragma query_only = 0")
+	assert.NoError(t, err)
+
+	model := [3]float64{0.1, 0.001, 0}
+	assert.NoError(t, h.ApplySgModel(h.Conf.Id, model, nil))
+	assert.Equal(t, model, h.Conf.SgModel)
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, config.PREPARATION, conf.Stage, "the model is stored with the stage in memory")
+}
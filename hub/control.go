@@ -0,0 +1,150 @@
+package hub
+
+import (
+	"database/sql"
+	"math"
+	"time"
+)
+
+// BrewScope is the scope of the ControlStats over a whole brew; those over
+// a stage are scoped by its name.
+const BrewScope = "brew"
+
+// ControlStats are how closely the fermenter temperature followed its
+// target over a stage of a brew or the whole brew. The deviations are in
+// ºC, of the temperature averaged over the span of the data points. A
+// setpoint change settles once the temperature stays within the band of
+// the target until the next one, and overshoots by how far it gets past
+// the target. The power is the average magnitude in %, whether heating or
+// cooling, and a reversal a switch between the two. The setpoint stats
+// aggregate the Responses to each change.
+type ControlStats struct {
+	Scope        string
+	Duration     time.Duration
+	RMSDeviation float64
+	MaxDeviation float64
+	InBand       time.Duration
+	Setpoints    int
+	Overshoot    float64
+	SettlingTime time.Duration
+	Settled      int
+	Reversals    int
+	AveragePower float64
+	Responses    []SetpointResponse
+}
+
+// SetpointResponse is how the temperature followed the setpoint change to
+// Target ºC at Step: how far it got past the target, and how long it took
+// to settle if it did.
+type SetpointResponse struct {
+	Step         int
+	Target       float64
+	Overshoot    float64
+	Settled      bool
+	SettlingTime time.Duration
+}
+
+// SetResponses sets the responses of s and its setpoint stats from them:
+// the largest overshoot and the mean settling time of those that settled.
+func (s *ControlStats) SetResponses(responses []SetpointResponse) {
+	s.Responses = responses
+	s.Setpoints, s.Overshoot, s.Settled, s.SettlingTime = len(responses), 0, 0, 0
+	var settling time.Duration
+	for _, r := range responses {
+		s.Overshoot = math.Max(s.Overshoot, r.Overshoot)
+		if r.Settled {
+			s.Settled++
+			settling += r.SettlingTime
+		}
+	}
+	if s.Settled > 0 {
+		s.SettlingTime = (settling / time.Duration(s.Settled)).Truncate(time.Second)
+	}
+}
+
+// SaveControlStats replaces the control stats of brew id with stats,
+// including their setpoint responses.
+func (h *Hub) SaveControlStats(id int, stats []ControlStats) error {
+	h.dbLock.Lock()
+	defer h.dbLock.Unlock()
+	tx, err := h.db.Begin()
+	if err != nil {
+		return h.report(err)
+	}
+	if err := exec(tx, "deleteControlSetpoints.sql", id); err != nil {
+		tx.Rollback()
+		return h.report(err)
+	}
+	for _, s := range stats {
+		if err := exec(tx, "insertControlStats.sql", id, s.Scope, int(s.Duration/time.Second), s.RMSDeviation, s.MaxDeviation,
+			int(s.InBand/time.Second), s.Setpoints, s.Overshoot, int(s.SettlingTime/time.Second), s.Settled, s.Reversals, s.AveragePower); err != nil {
+			tx.Rollback()
+			return h.report(err)
+		}
+		for _, r := range s.Responses {
+			var settling sql.NullInt64
+			if r.Settled {
+				settling = sql.NullInt64{Int64: int64(r.SettlingTime / time.Second), Valid: true}
+			}
+			if err := exec(tx, "insertControlSetpoint.sql", id, s.Scope, r.Step, r.Target, r.Overshoot, settling); err != nil {
+				tx.Rollback()
+				return h.report(err)
+			}
+		}
+	}
+	return h.report(tx.Commit())
+}
+
+// ControlStats loads the control stats of brew id in the order they were
+// saved. Their setpoint stats are aggregated from the stored responses;
+// stats saved before those were kept have none and keep their own.
+func (h *Hub) ControlStats(id int) ([]ControlStats, error) {
+	responses, err := h.setpointResponses(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := h.db.Query(query("selectControlStats.sql"), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []ControlStats
+	for rows.Next() {
+		var s ControlStats
+		var duration, inBand, settling int
+		if err := rows.Scan(&s.Scope, &duration, &s.RMSDeviation, &s.MaxDeviation, &inBand, &s.Setpoints,
+			&s.Overshoot, &settling, &s.Settled, &s.Reversals, &s.AveragePower); err != nil {
+			return nil, err
+		}
+		s.Duration, s.InBand, s.SettlingTime = time.Duration(duration)*time.Second, time.Duration(inBand)*time.Second, time.Duration(settling)*time.Second
+		if r, ok := responses[s.Scope]; ok {
+			s.SetResponses(r)
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// setpointResponses loads the setpoint responses of brew id by scope.
+func (h *Hub) setpointResponses(id int) (map[string][]SetpointResponse, error) {
+	rows, err := h.db.Query(query("selectControlSetpoints.sql"), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	responses := map[string][]SetpointResponse{}
+	for rows.Next() {
+		var scope string
+		var r SetpointResponse
+		var settling sql.NullInt64
+		if err := rows.Scan(&scope, &r.Step, &r.Target, &r.Overshoot, &settling); err != nil {
+			return nil, err
+		}
+		r.Settled, r.SettlingTime = settling.Valid, time.Duration(settling.Int64)*time.Second
+		responses[scope] = append(responses[scope], r)
+	}
+	return responses, rows.Err()
+}
@@ -0,0 +1,61 @@
+package hub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestControlStats(t *testing.T) {
+	h := newTestHub(t)
+	stats, err := h.ControlStats(1)
+	assert.NoError(t, err)
+	assert.Empty(t, stats)
+
+	brewing := ControlStats{Scope: "brewing", Duration: time.Hour, RMSDeviation: 0.2, MaxDeviation: 0.7, InBand: 50 * time.Minute,
+		Setpoints: 2, Overshoot: 0.3, SettlingTime: 20 * time.Minute, Settled: 1, Reversals: 4, AveragePower: 35}
+	brew := brewing
+	brew.Scope = BrewScope
+	assert.NoError(t, h.SaveControlStats(1, []ControlStats{brewing, brew}))
+
+	brewing.Reversals, brew.Reversals = 5, 5
+	assert.NoError(t, h.SaveControlStats(1, []ControlStats{brewing, brew}))
+	stats, err = h.ControlStats(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []ControlStats{brewing, brew}, stats)
+}
+
+func TestControlStatsFromSetpoints(t *testing.T) {
+	h := newTestHub(t)
+	brew := ControlStats{Scope: BrewScope, Duration: time.Hour, InBand: 50 * time.Minute, Reversals: 1, AveragePower: 20}
+	brew.SetResponses([]SetpointResponse{
+		{Step: 0, Target: 20, Overshoot: 0.4, Settled: true, SettlingTime: 10 * time.Minute},
+		{Step: 1800, Target: 21, Overshoot: 0.2, Settled: true, SettlingTime: 5 * time.Minute},
+		{Step: 3000, Target: 18, Overshoot: 0.6},
+	})
+	assert.Equal(t, 3, brew.Setpoints)
+	assert.Equal(t, 0.6, brew.Overshoot)
+	assert.Equal(t, 2, brew.Settled)
+	assert.Equal(t, 450*time.Second, brew.SettlingTime)
+	assert.NoError(t, h.SaveControlStats(1, []ControlStats{brew}))
+
+	stats, err := h.ControlStats(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []ControlStats{brew}, stats)
+
+	// the aggregates follow the stored responses
+	_, err = h.db.Exec("update control_setpoints set SettlingTime = 60 where Step = 3000")
+	assert.NoError(t, err)
+	stats, err = h.ControlStats(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats[0].Settled)
+	assert.Equal(t, 320*time.Second, stats[0].SettlingTime)
+
+	// saving again replaces the responses
+	brew.SetResponses(brew.Responses[:1])
+	assert.NoError(t, h.SaveControlStats(1, []ControlStats{brew}))
+	stats, err = h.ControlStats(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []ControlStats{brew}, stats)
+}
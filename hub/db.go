@@ -0,0 +1,32 @@
+package hub
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// dbOptions puts the database in write-ahead log mode, so readers don't
+	// block the recorder and a commit is a single append to the log.
+	dbOptions = "?_journal_mode=WAL"
+	// writeRetries is how many times a write is tried while the database
+	// stays busy past the driver's own busy timeout.
+	writeRetries = 5
+	// writeRetryDelay is the delay before the first retry, doubled for
+	// every next one.
+	writeRetryDelay = time.Millisecond * 200
+)
+
+// open opens the database stored in file.
+func open(file string) (*sql.DB, error) {
+	return sql.Open("sqlite3", file+dbOptions)
+}
+
+// busy reports whether err is a transient lock conflict.
+func busy(err error) bool {
+	var e sqlite3.Error
+	return errors.As(err, &e) && (e.Code == sqlite3.ErrBusy || e.Code == sqlite3.ErrLocked)
+}
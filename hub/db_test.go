@@ -0,0 +1,48 @@
+package hub
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/config"
+)
+
+func TestOpenUsesWriteAheadLog(t *testing.T) {
+	db, _ := openTestDb(t)
+	var mode string
+	assert.NoError(t, db.QueryRow("pragma journal_mode").Scan(&mode))
+	assert.Equal(t, "wal", mode)
+}
+
+func TestSaveDataPointsKeepsTheirBrew(t *testing.T) {
+	h := newTestHub(t)
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	conf.Stage = config.DONE
+	h.Conf = conf
+	h.saveConfig()
+	assert.NoError(t, h.NewBrew("Second"))
+
+	// points buffered before the new brew started still belong to the old one
+	assert.NoError(t, h.SaveDataPoints([]*DataPoint{
+		{Id: conf.Id, Step: 1, TargetTemp: 20, CurrentTemp: 200, SG: math.NaN(), Span: 1},
+		{Id: conf.Id, Step: 2, TargetTemp: 20, CurrentTemp: 200, SG: math.NaN(), Span: 1},
+	}))
+	dps, err := h.SessionDataPoints(conf.Id)
+	assert.NoError(t, err)
+	assert.Len(t, dps, 2)
+	dps, err = h.SessionDataPoints(conf.Id + 1)
+	assert.NoError(t, err)
+	assert.Empty(t, dps)
+}
+
+func TestBusy(t *testing.T) {
+	assert.True(t, busy(sqlite3.Error{Code: sqlite3.ErrBusy}))
+	assert.True(t, busy(fmt.Errorf("commit: %w", sqlite3.Error{Code: sqlite3.ErrLocked})))
+	assert.False(t, busy(sqlite3.Error{Code: sqlite3.ErrConstraint}))
+	assert.False(t, busy(errors.New("disk full")))
+}
@@ -0,0 +1,50 @@
+package hub
+
+import (
+	"log"
+	"math"
+
+	"github.com/zlowred/alcobot/bus"
+	"github.com/zlowred/alcobot/filter"
+)
+
+// The defaults match the column defaults in
+// migration002ConfigFilters.sql.
+const (
+	defaultNpaFilter = "trimmed:100,20"
+	defaultDsFilter  = "trimmed:30,10"
+	defaultAdsFilter = "trimmed:100,20"
+)
+
+// sensorFilter is the filter of one sensor together with the spec it was
+// built from, so a configuration change only rebuilds filters that changed.
+type sensorFilter struct {
+	filter.Filter
+	name string
+	spec string
+}
+
+func newSensorFilter(name string, spec string) *sensorFilter {
+	return &sensorFilter{filter.MustNew(spec), name, spec}
+}
+
+func (f *sensorFilter) configure(spec string) {
+	if spec == "" || spec == f.spec {
+		return
+	}
+	nf, err := filter.New(spec)
+	if err != nil {
+		log.Printf("Keeping %v filter %v: %v\n", f.name, f.spec, err)
+		return
+	}
+	log.Printf("Using %v filter %v\n", f.name, spec)
+	f.Filter, f.spec = nf, spec
+}
+
+func (f *sensorFilter) apply(x Sample, out *bus.Topic[Sample]) {
+	f.Add(float64(x.Value))
+	if f.Ready() {
+		x.Value = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(f.Value()))))
+		out.Send(x)
+	}
+}
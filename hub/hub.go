@@ -1,15 +1,14 @@
 package hub
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"time"
 
-	"github.com/IvanMalison/bcast"
-	"github.com/eapache/channels"
-
-	"github.com/zlowred/alcobot/avg"
+	"github.com/zlowred/alcobot/bus"
 	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/debuglog"
 
 	"math"
 
@@ -18,6 +17,9 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// DataPoint is the state of the brew at Step seconds since its start.
+// Points loaded from the rolled-up tiers stand for the Span seconds up to
+// Step; recorded points have a Span of 1.
 type DataPoint struct {
 	Id          int
 	Step        int
@@ -26,6 +28,9 @@ type DataPoint struct {
 	SG          float64
 	PID         float64
 	Power       float64
+	Span        int
+	// Alcohol is derived from SG and the OG of the brew, it isn't stored.
+	Alcohol
 }
 
 type PwmValue struct {
@@ -33,106 +38,248 @@ type PwmValue struct {
 	Value   byte
 }
 
+type Quality uint8
+
+const (
+	// Primed marks copies of a reading sent to fill the filters at start.
+	Primed Quality = 1 << iota
+	// Recovered marks the first reading after a sensor error or reconnect.
+	Recovered
+	// Simulated marks readings that do not come from real hardware.
+	Simulated
+)
+
+// Sample is a raw or filtered sensor reading. Time comes from time.Now so it
+// carries the monotonic clock; filtered samples keep the source, time and
+// quality of the latest raw sample that went into the filter.
+type Sample struct {
+	Value   int16
+	Source  string
+	Time    time.Time
+	Quality Quality
+}
+
+func NewSample(value int16, source string, quality Quality) Sample {
+	return Sample{Value: value, Source: source, Time: time.Now(), Quality: quality}
+}
+
+func (s Sample) Age() time.Duration {
+	return time.Since(s.Time)
+}
+
+// Gravity is the estimated specific gravity and its rate of change in
+// gravity points (0.001 SG) per day, each with a 95% confidence band.
+type Gravity struct {
+	SG       float64
+	SGLow    float64
+	SGHigh   float64
+	Rate     float64
+	RateLow  float64
+	RateHigh float64
+	Time     time.Time
+}
+
+// Fermentation is the analysis of the SG curve of a brew: the final gravity
+// and the time the SG gets within tolerance of it, both estimated from a
+// fit of the curve and unknown (NaN and zero) until there is one, and
+// whether the SG has been stable over the analysis window.
+type Fermentation struct {
+	Id     int
+	FG     float64
+	ETA    time.Time
+	Stable bool
+	Time   time.Time
+}
+
+const (
+	NpaTemperatureSensorTopic   = "sensor/npa/temperature"
+	NpaPressureSensorTopic      = "sensor/npa/pressure"
+	DsTemperatureSensorTopic    = "sensor/ds/temperature"
+	AdsValueSensorTopic         = "sensor/ads/value"
+	NpaTemperatureFilteredTopic = "filtered/npa/temperature"
+	NpaPressureFilteredTopic    = "filtered/npa/pressure"
+	DsTemperatureFilteredTopic  = "filtered/ds/temperature"
+	AdsValueFilteredTopic       = "filtered/ads/value"
+	PwmOutputTopic              = "control/pwm"
+	PidOutputTopic              = "control/pid"
+	AdjustedPidOutputTopic      = "control/pid/adjusted"
+	ConfigurationTopic          = "config"
+	ScreenChangeTopic           = "gui/screen"
+	DataPointsTopic             = "recorder/datapoints"
+	GravityTopic                = "estimate/gravity"
+	StorageTopic                = "storage/status"
+	FermentationTopic           = "analysis/fermentation"
+	AlarmTopic                  = "alarm"
+	PressureTopic               = "estimate/pressure"
+)
+
 type Hub struct {
-	Quit               chan bool
 	FlightRecorderLock chan bool
 
-	NpaTemperatureSensor *bcast.Group
-	NpaPressureSensor    *bcast.Group
-	DsTemperatureSensor  *bcast.Group
-	AdsValueSensor       *bcast.Group
+	Bus *bus.Bus
+
+	NpaTemperatureSensor *bus.Topic[Sample]
+	NpaPressureSensor    *bus.Topic[Sample]
+	DsTemperatureSensor  *bus.Topic[Sample]
+	AdsValueSensor       *bus.Topic[Sample]
 
-	PwmOutput *bcast.Group
-	PidOutput *bcast.Group
+	PwmOutput *bus.Topic[PwmValue]
+	PidOutput *bus.Topic[float64]
 
-	NpaTemperatureFiltered *bcast.Group
-	NpaPressureFiltered    *bcast.Group
-	DsTemperatureFiltered  *bcast.Group
-	AdsValueFiltered       *bcast.Group
+	NpaTemperatureFiltered *bus.Topic[Sample]
+	NpaPressureFiltered    *bus.Topic[Sample]
+	DsTemperatureFiltered  *bus.Topic[Sample]
+	AdsValueFiltered       *bus.Topic[Sample]
 
-	Configuration     *bcast.Group
-	AdjustedPidOutput *bcast.Group
+	Configuration     *bus.Topic[*config.Configuration]
+	AdjustedPidOutput *bus.Topic[float64]
 
-	ScreenChange *bcast.Group
+	ScreenChange *bus.Topic[config.Screen]
 
-	DataPoints *bcast.Group
+	DataPoints *bus.Topic[*DataPoint]
 
-	npaTemperatureFilter *avg.Avg
-	npaPressureFilter    *avg.Avg
-	dsTemperatureFilter  *avg.Avg
-	adsValueFilter       *avg.Avg
+	Gravity *bus.Topic[Gravity]
+
+	Pressure *bus.Topic[Pressure]
+
+	Fermentation *bus.Topic[Fermentation]
+
+	Storage *bus.Topic[StorageStatus]
+
+	Alarm *bus.Topic[Alarm]
+
+	npaTemperatureFilter *sensorFilter
+	npaPressureFilter    *sensorFilter
+	dsTemperatureFilter  *sensorFilter
+	adsValueFilter       *sensorFilter
 
 	fermenterSensor string
 
 	Conf   *config.Configuration
 	db     *sql.DB
 	dbLock sync.Mutex
+	// file is where the database is stored, also while the hub runs from
+	// memory
+	file string
+
+	storage  storageHealth
+	alarms   alarms
+	gravity  latestGravity
+	pressure latestPressure
+	// configDirty is set while Conf or pendingEvents couldn't be stored;
+	// both are guarded by dbLock, like Conf.
+	configDirty   bool
+	pendingEvents []StageEvent
 }
 
-func New() (*Hub, error) {
-
-	pwm := bcast.NewGroup()
-	hub := &Hub{
-		Quit: make(chan bool), PidOutput: bcast.NewGroup(), PwmOutput: pwm, Configuration: bcast.NewGroup(), AdjustedPidOutput: bcast.NewGroup(),
-		NpaTemperatureSensor: bcast.NewGroup(), NpaPressureSensor: bcast.NewGroup(), DsTemperatureSensor: bcast.NewGroup(), AdsValueSensor: bcast.NewGroup(),
-		NpaTemperatureFiltered: bcast.NewGroup(), NpaPressureFiltered: bcast.NewGroup(), DsTemperatureFiltered: bcast.NewGroup(), AdsValueFiltered: bcast.NewGroup(),
-		npaTemperatureFilter: avg.NewAvg(100, 20), npaPressureFilter: avg.NewAvg(100, 20), dsTemperatureFilter: avg.NewAvg(30, 10), adsValueFilter: avg.NewAvg(100, 20),
-		ScreenChange: bcast.NewGroup(), FlightRecorderLock: make(chan bool), DataPoints: bcast.NewGroup(),
+// New opens the hub on the database in file. If the database can't be
+// opened or migrated, the hub runs from an in-memory database with a
+// degraded storage status, and opening the file is retried.
+func New(file string) (*Hub, error) {
+	hub := newHub()
+	hub.file = file
+	if err := restoreStaged(file); err != nil {
+		log.Printf("Can't restore the staged backup of %v: %v\n", file, err)
 	}
-
-	db, err := sql.Open("sqlite3", "./alcobot.db")
+	db, err := openMigrated(file)
 	if err != nil {
-		log.Fatal(err)
+		// control can still run from a blank configuration; nothing is kept
+		// over a restart until the database file is fixed
+		log.Printf("Can't open database %v, running from memory: %v\n", file, err)
+		hub.runFromMemory(err)
+		if db, err = openMigrated(":memory:"); err != nil {
+			return nil, err
+		}
+		db.SetMaxOpenConns(1)
 	}
 	hub.db = db
 
-	hub.queryDb(query("configTableExists.sql"), func(rows *sql.Rows) {
-		if rows.Next() {
-			return
-		}
-		hub.execDb(query("createConfigTable.sql"), func(r sql.Result) {
-			hub.execDb(query("insertDefaultConfig.sql"), nil)
-		})
-	})
-
-	hub.queryDb(query("dataTableExists.sql"), func(rows *sql.Rows) {
-		if rows.Next() {
-			return
-		}
-		hub.execDb(query("createDataTable.sql"), nil)
-	})
-
-	go hub.NpaTemperatureFiltered.Broadcast(0)
-	go hub.NpaPressureFiltered.Broadcast(0)
-	go hub.DsTemperatureFiltered.Broadcast(0)
-	go hub.AdsValueFiltered.Broadcast(0)
+	return hub, nil
+}
 
-	go hub.PidOutput.Broadcast(0)
-	go hub.AdjustedPidOutput.Broadcast(0)
-	go hub.PwmOutput.Broadcast(0)
+// Open opens the hub on the database in file for maintenance, without
+// falling back to memory. Close releases it.
+func Open(file string) (*Hub, error) {
+	db, err := openMigrated(file)
+	if err != nil {
+		return nil, err
+	}
+	hub := newHub()
+	hub.db, hub.file = db, file
+	return hub, nil
+}
 
-	go hub.Configuration.Broadcast(0)
-	go hub.AdjustedPidOutput.Broadcast(0)
+// Close closes the bus and the database of a hub that isn't running.
+func (h *Hub) Close() error {
+	h.Bus.Close()
+	return h.db.Close()
+}
 
-	go hub.ScreenChange.Broadcast(0)
+// configurationDefaults keep a Configuration subscriber from holding up the
+// others: several of them send back into the topic from their receive
+// path, which deadlocks with blocking subscribers. Only the latest
+// configurations matter.
+var configurationDefaults = []bus.Option{bus.Buffer(8), bus.Drop(bus.DropOldest)}
 
-	go hub.DataPoints.Broadcast(0)
+func newHub() *Hub {
+	b := bus.New()
+	hub := &Hub{
+		FlightRecorderLock: make(chan bool), Bus: b,
+		NpaTemperatureSensor: bus.MustRegister[Sample](b, NpaTemperatureSensorTopic), NpaPressureSensor: bus.MustRegister[Sample](b, NpaPressureSensorTopic),
+		DsTemperatureSensor: bus.MustRegister[Sample](b, DsTemperatureSensorTopic), AdsValueSensor: bus.MustRegister[Sample](b, AdsValueSensorTopic),
+		NpaTemperatureFiltered: bus.MustRegister[Sample](b, NpaTemperatureFilteredTopic), NpaPressureFiltered: bus.MustRegister[Sample](b, NpaPressureFilteredTopic),
+		DsTemperatureFiltered: bus.MustRegister[Sample](b, DsTemperatureFilteredTopic), AdsValueFiltered: bus.MustRegister[Sample](b, AdsValueFilteredTopic),
+		PwmOutput: bus.MustRegister[PwmValue](b, PwmOutputTopic), PidOutput: bus.MustRegister[float64](b, PidOutputTopic), AdjustedPidOutput: bus.MustRegister[float64](b, AdjustedPidOutputTopic),
+		Configuration: bus.MustRegister[*config.Configuration](b, ConfigurationTopic, configurationDefaults...), ScreenChange: bus.MustRegister[config.Screen](b, ScreenChangeTopic),
+		DataPoints: bus.MustRegister[*DataPoint](b, DataPointsTopic), Gravity: bus.MustRegister[Gravity](b, GravityTopic),
+		Fermentation: bus.MustRegister[Fermentation](b, FermentationTopic), Storage: bus.MustRegister[StorageStatus](b, StorageTopic),
+		Alarm: bus.MustRegister[Alarm](b, AlarmTopic), Pressure: bus.MustRegister[Pressure](b, PressureTopic),
+		npaTemperatureFilter: newSensorFilter("NPA temperature", defaultNpaFilter), npaPressureFilter: newSensorFilter("NPA pressure", defaultNpaFilter),
+		dsTemperatureFilter: newSensorFilter("DS temperature", defaultDsFilter), adsValueFilter: newSensorFilter("ADS value", defaultAdsFilter),
+	}
+	return hub
+}
 
-	go hub.NpaTemperatureSensor.Broadcast(0)
-	go hub.NpaPressureSensor.Broadcast(0)
-	go hub.DsTemperatureSensor.Broadcast(0)
-	go hub.AdsValueSensor.Broadcast(0)
+func openMigrated(file string) (*sql.DB, error) {
+	db, err := open(file)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(db, file); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
 
-	go hub.loop()
+// Run filters sensor samples until ctx is done, then closes the bus and the
+// database, so it has to be the last component to stop.
+func (h *Hub) Run(ctx context.Context) {
+	setupDone := make(chan struct{})
+	go func() {
+		defer close(setupDone)
+		h.setup(ctx)
+	}()
+	retentionDone := make(chan struct{})
+	go func() {
+		defer close(retentionDone)
+		<-setupDone
+		h.retention(ctx)
+	}()
 
-	go hub.setup()
+	h.loop(ctx)
 
-	return hub, nil
+	<-setupDone
+	<-retentionDone
+	h.Close()
 }
 
-func (h *Hub) loadDataPoints() {
+func (h *Hub) loadDataPoints(ctx context.Context) {
 	defer func() {
-		h.FlightRecorderLock <- true
+		select {
+		case h.FlightRecorderLock <- true:
+		case <-ctx.Done():
+		}
 	}()
 
 	if h.Conf.Stage < config.PREPARATION {
@@ -141,326 +288,231 @@ func (h *Hub) loadDataPoints() {
 	}
 	log.Println("Loading saved data points")
 
+	dps, err := h.SessionDataPoints(h.Conf.Id)
+	if err != nil {
+		log.Printf("Can't load saved data points: %v\n", h.report(err))
+	}
 	lastStep := 0
-	h.queryDb(query("selectDataPoints.sql"), func(r *sql.Rows) {
-		for r.Next() {
-			var Id int
-			var Step int
-			var TargetTemp sql.NullFloat64
-			var CurrentTemp sql.NullFloat64
-			var SG sql.NullFloat64
-			var PID sql.NullFloat64
-			var Power sql.NullFloat64
-
-			r.Scan(
-				&Id,
-				&Step,
-				&TargetTemp,
-				&CurrentTemp,
-				&SG,
-				&PID,
-				&Power,
-			)
-
-			dp := &DataPoint{Id: Id, Step: Step}
-			if TargetTemp.Valid {
-				dp.TargetTemp = TargetTemp.Float64
-			} else {
-				dp.TargetTemp = math.NaN()
-			}
-			if CurrentTemp.Valid {
-				dp.CurrentTemp = CurrentTemp.Float64
-			} else {
-				dp.CurrentTemp = math.NaN()
-			}
-			if SG.Valid {
-				dp.SG = SG.Float64
-			} else {
-				dp.SG = math.NaN()
-			}
-			if PID.Valid {
-				dp.PID = PID.Float64
-			} else {
-				dp.PID = math.NaN()
-			}
-			if Power.Valid {
-				dp.Power = Power.Float64
-			} else {
-				dp.Power = math.NaN()
-			}
-			lastStep = dp.Step
-			h.DataPoints.Send(dp)
-		}
-	})
+	for _, dp := range dps {
+		lastStep = dp.Step
+		h.DataPoints.Send(dp)
+	}
 
-	log.Printf("Completed loading saved %v data points\n", lastStep)
+	log.Printf("Completed loading %d saved data points up to step %v\n", len(dps), lastStep)
 
 	if h.Conf.Stage == config.DONE {
 		return
 	}
 
-	points := make([]*DataPoint, 0)
-	count := 0
-	for lastStep < int((time.Now().Sub(h.Conf.BrewingStartTime) / time.Second)) {
-		lastStep++
-		tmp := &DataPoint{h.Conf.Id, lastStep, math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()}
-		h.DataPoints.Send(tmp)
-		points = append(points, tmp)
-		count++
-	}
-	h.saveDataPoints(points)
-	if count > 0 {
-		log.Printf("Created %d missing data points\n", count)
+	// the time the recorder was off is sent as a single empty point instead
+	// of filling the table with one row per missing second
+	now := int(time.Now().Sub(h.Conf.BrewingStartTime) / time.Second)
+	if gap := now - lastStep; gap > 0 {
+		h.DataPoints.Send(&DataPoint{Id: h.Conf.Id, Step: now, TargetTemp: math.NaN(), CurrentTemp: math.NaN(), SG: math.NaN(), PID: math.NaN(), Power: math.NaN(), Span: gap, Alcohol: NewAlcohol(h.Conf.OG, math.NaN())})
+		log.Printf("Skipped %d missing data points\n", gap)
 	}
 }
 
-func (h *Hub) SaveDataPoint(dp *DataPoint) {
-	h.dbLock.Lock()
-	defer h.dbLock.Unlock()
-	tx, err := h.db.Begin()
-	if err != nil {
-		log.Fatal(err)
-	}
-	stmt, err := tx.Prepare(query("insertDataPoint.sql"))
-	if err != nil {
-		panic(err)
-		log.Fatal(err)
-	}
-	defer stmt.Close()
-
-	_, err = stmt.Exec(
-		h.Conf.Id,
-		dp.Step,
-		dp.TargetTemp,
-		dp.CurrentTemp,
-		dp.SG,
-		dp.PID,
-		dp.Power,
-	)
-
-	if err != nil {
-		log.Fatal(err)
+// SaveDataPoints stores a batch of data points in one transaction, each
+// under the brew it was recorded for. A busy database is retried
+// writeRetries times before the error is returned.
+func (h *Hub) SaveDataPoints(dps []*DataPoint) error {
+	var err error
+	delay := writeRetryDelay
+	for attempt := 1; ; attempt++ {
+		if err = h.saveDataPoints(dps); err == nil || !busy(err) || attempt == writeRetries {
+			return h.report(err)
+		}
+		log.Printf("Database busy saving %d data points, retrying in %v: %v\n", len(dps), delay, err)
+		time.Sleep(delay)
+		delay *= 2
 	}
-	tx.Commit()
 }
 
-func (h *Hub) saveDataPoints(dps []*DataPoint) {
+func (h *Hub) saveDataPoints(dps []*DataPoint) error {
 	h.dbLock.Lock()
 	defer h.dbLock.Unlock()
 	tx, err := h.db.Begin()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	stmt, err := tx.Prepare(query("insertDataPoint.sql"))
 	if err != nil {
-		panic(err)
-		log.Fatal(err)
+		tx.Rollback()
+		return err
 	}
 	defer stmt.Close()
 
 	for _, dp := range dps {
-		_, err = stmt.Exec(
-			h.Conf.Id,
-			dp.Step,
-			dp.TargetTemp,
-			dp.CurrentTemp,
-			dp.SG,
-			dp.PID,
-			dp.Power,
-		)
-	}
-
-	if err != nil {
-		log.Fatal(err)
+		if _, err := stmt.Exec(dp.Id, dp.Step, dp.TargetTemp, dp.CurrentTemp, dp.SG, dp.PID, dp.Power); err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
-	tx.Commit()
+	return tx.Commit()
 }
 
-func (h *Hub) loop() {
-	npaTemperatureCh := JoinInt16Group(h.NpaTemperatureSensor)
-	npaPressureCh := JoinInt16Group(h.NpaPressureSensor)
-	dsTemperatureCh := JoinInt16Group(h.DsTemperatureSensor)
-	adsValueCh := JoinInt16Group(h.AdsValueSensor)
-	configCh := JoinConfigGroup(h.Configuration)
+func (h *Hub) loop(ctx context.Context) {
+	npaTemperatureCh := h.NpaTemperatureSensor.JoinContext(ctx)
+	npaPressureCh := h.NpaPressureSensor.JoinContext(ctx)
+	dsTemperatureCh := h.DsTemperatureSensor.JoinContext(ctx)
+	adsValueCh := h.AdsValueSensor.JoinContext(ctx)
+	configCh := h.Configuration.JoinContext(ctx)
+	gravityCh := h.Gravity.JoinContext(ctx, bus.Buffer(1), bus.Drop(bus.DropOldest), bus.Name("hub"))
+	pressureCh := h.Pressure.JoinContext(ctx, bus.Buffer(1), bus.Drop(bus.DropOldest), bus.Name("hub"))
+	retry := time.NewTicker(storageRetryPeriod)
+	defer retry.Stop()
 
 	for {
 		select {
 		case x := <-configCh:
+			h.dbLock.Lock()
 			h.Conf = x
+			h.dbLock.Unlock()
 			if h.fermenterSensor != x.FermenterSensor {
 				h.fermenterSensor = x.FermenterSensor
-				h.dsTemperatureFilter.ResetCounter()
+				h.dsTemperatureFilter.Reset()
 			}
+			h.npaTemperatureFilter.configure(x.NpaTemperatureFilter)
+			h.npaPressureFilter.configure(x.NpaPressureFilter)
+			h.dsTemperatureFilter.configure(x.DsTemperatureFilter)
+			h.adsValueFilter.configure(x.AdsValueFilter)
 			h.saveConfig()
-		case x := <-npaTemperatureCh:
-			h.npaTemperatureFilter.Add(x)
-			if h.npaTemperatureFilter.Ready {
-				h.NpaTemperatureFiltered.Send(h.npaTemperatureFilter.Average())
+		case x := <-gravityCh:
+			h.setGravity(x)
+		case x := <-pressureCh:
+			h.setPressure(x)
+		case <-retry.C:
+			if h.inMemory() {
+				h.reopen()
+			} else if h.dirty() {
+				h.saveConfig()
 			}
+		case x := <-npaTemperatureCh:
+			h.npaTemperatureFilter.apply(x, h.NpaTemperatureFiltered)
 		case x := <-npaPressureCh:
-			h.npaPressureFilter.Add(x)
-			if h.npaPressureFilter.Ready {
-				h.NpaPressureFiltered.Send(h.npaPressureFilter.Average())
-			}
+			h.npaPressureFilter.apply(x, h.NpaPressureFiltered)
 		case x := <-dsTemperatureCh:
-			h.dsTemperatureFilter.Add(x)
-			if h.dsTemperatureFilter.Ready {
-				h.DsTemperatureFiltered.Send(h.dsTemperatureFilter.Average())
-			}
+			h.dsTemperatureFilter.apply(x, h.DsTemperatureFiltered)
 		case x := <-adsValueCh:
-			h.adsValueFilter.Add(x)
-			if h.adsValueFilter.Ready {
-				h.AdsValueFiltered.Send(h.adsValueFilter.Average())
-			}
-		case <-h.Quit:
-			h.db.Close()
-
-			h.NpaTemperatureFiltered.Close()
-			h.NpaPressureFiltered.Close()
-			h.DsTemperatureFiltered.Close()
-			h.AdsValueFiltered.Close()
-
-			h.PidOutput.Close()
-			h.PwmOutput.Close()
-
-			h.ScreenChange.Close()
-
-			h.DataPoints.Close()
-
-			h.NpaTemperatureSensor.Close()
-			h.NpaPressureSensor.Close()
-			h.DsTemperatureSensor.Close()
-			h.AdsValueSensor.Close()
-
-			h.Configuration.Close()
-			h.AdjustedPidOutput.Close()
-
+			h.adsValueFilter.apply(x, h.AdsValueFiltered)
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (h *Hub) setup() {
-	time.Sleep(time.Millisecond * 1000)
-	h.queryDb(query("selectLatestConfig.sql"), func(r *sql.Rows) {
-		for r.Next() {
-			conf := &config.Configuration{}
-			r.Scan(&conf.Id,
-				&conf.FermenterSensor,
-				&conf.PresenceZero,
-				&conf.PresenceCalibration,
-				&conf.PresenceOnTimer,
-				&conf.PresenceEnabled,
-				&conf.PresenceTimeout,
-				&conf.TemperatureScale,
-				&conf.TargetTemperature,
-				&conf.PidSlope,
-				&conf.NpaZero,
-				&conf.NpaCalibration,
-				&conf.Tec1Threshold,
-				&conf.Tec1Min,
-				&conf.Tec1Max,
-				&conf.Tec2Threshold,
-				&conf.Tec2Min,
-				&conf.Tec2Max,
-				&conf.Fan1Threshold,
-				&conf.Fan1Min,
-				&conf.Fan1Max,
-				&conf.Fan2Threshold,
-				&conf.Fan2Min,
-				&conf.Fan2Max,
-				&conf.Pump1Threshold,
-				&conf.Pump1Min,
-				&conf.Pump1Max,
-				&conf.Pump2Threshold,
-				&conf.Pump2Min,
-				&conf.Pump2Max,
-				&conf.NpaMinValue,
-				&conf.NpaMaxValue,
-				&conf.NpaMinPressure,
-				&conf.NpaMaxPressure,
-				&conf.Stage,
-				&conf.OG,
-				&conf.BrewingStartTime,
-				&conf.PitchTime)
-			log.Printf("Loaded config: %#v\n", conf)
-			h.Configuration.Send(conf)
-			switch conf.Stage {
-			case config.SETUP:
-				h.ScreenChange.Send(config.SETUP_SCREEN)
-			case config.PREPARATION:
-				h.ScreenChange.Send(config.PREPARATION_SCREEN)
-			case config.BREWING:
-				h.ScreenChange.Send(config.BREWING_SCREEN)
-			}
+func (h *Hub) setup(ctx context.Context) {
+	if !sleep(ctx, time.Millisecond*1000) {
+		return
+	}
+	conf, err := h.latestConfig()
+	for err != nil {
+		log.Printf("Can't load config, retrying in %v: %v\n", storageRetryPeriod, h.report(err))
+		if !sleep(ctx, storageRetryPeriod) {
+			return
 		}
-	})
-
-	time.Sleep(time.Millisecond * 1000)
-
-	h.loadDataPoints()
-}
-func JoinInt16Group(group *bcast.Group) <-chan int16 {
-	ch := make(chan int16)
-	channels.Unwrap(channels.Wrap(group.Join().Read), ch)
-	return (<-chan int16)(ch)
-}
-
-func JoinFloat64Group(group *bcast.Group) <-chan float64 {
-	ch := make(chan float64)
-	channels.Unwrap(channels.Wrap(group.Join().Read), ch)
-	return (<-chan float64)(ch)
-}
-
-func JoinPwmValueGroup(group *bcast.Group) <-chan PwmValue {
-	ch := make(chan PwmValue)
-	channels.Unwrap(channels.Wrap(group.Join().Read), ch)
-	return (<-chan PwmValue)(ch)
-}
+		conf, err = h.latestConfig()
+	}
+	debuglog.Printf("Loaded config: %#v\n", conf)
+	h.Configuration.Send(conf)
+	h.ScreenChange.Send(stageScreen(conf.Stage))
 
-func JoinStringGroup(group *bcast.Group) <-chan string {
-	ch := make(chan string)
-	channels.Unwrap(channels.Wrap(group.Join().Read), ch)
-	return (<-chan string)(ch)
-}
+	if !sleep(ctx, time.Millisecond*1000) {
+		return
+	}
 
-func JoinConfigGroup(group *bcast.Group) <-chan *config.Configuration {
-	ch := make(chan *config.Configuration)
-	channels.Unwrap(channels.Wrap(group.Join().Read), ch)
-	return (<-chan *config.Configuration)(ch)
+	h.compact(time.Now())
+	h.loadDataPoints(ctx)
 }
 
-func JoinDataPointGroup(group *bcast.Group) <-chan *DataPoint {
-	ch := make(chan *DataPoint)
-	channels.Unwrap(channels.Wrap(group.Join().Read), ch)
-	return (<-chan *DataPoint)(ch)
+// stageScreen is the screen a brew in stage is shown on; a finished brew
+// stays on the brewing screen it ended on.
+func stageScreen(stage config.Stage) config.Screen {
+	switch stage {
+	case config.PREPARATION:
+		return config.PREPARATION_SCREEN
+	case config.BREWING, config.DONE:
+		return config.BREWING_SCREEN
+	default:
+		return config.SETUP_SCREEN
+	}
 }
 
-func JoinScreenGroup(group *bcast.Group) <-chan config.Screen {
-	ch := make(chan config.Screen)
-	channels.Unwrap(channels.Wrap(group.Join().Read), ch)
-	return (<-chan config.Screen)(ch)
+// latestConfig loads the configuration of the current brew, which is the
+// latest row of the config table.
+func (h *Hub) latestConfig() (*config.Configuration, error) {
+	return scanConfig(h.db.QueryRow(query("selectLatestConfig.sql")))
 }
 
-func (hub *Hub) queryDb(stmt string, f func(rows *sql.Rows)) {
-	if rows, err := hub.db.Query(stmt); err != nil {
-		log.Fatal(err)
-	} else {
-		defer rows.Close()
-		f(rows)
+func scanConfig(row *sql.Row) (*config.Configuration, error) {
+	conf := &config.Configuration{}
+	err := row.Scan(&conf.Id,
+		&conf.FermenterSensor,
+		&conf.PresenceZero,
+		&conf.PresenceCalibration,
+		&conf.PresenceOnTimer,
+		&conf.PresenceEnabled,
+		&conf.PresenceTimeout,
+		&conf.TemperatureScale,
+		&conf.TargetTemperature,
+		&conf.PidSlope,
+		&conf.NpaZero,
+		&conf.NpaCalibration,
+		&conf.Tec1Threshold,
+		&conf.Tec1Min,
+		&conf.Tec1Max,
+		&conf.Tec2Threshold,
+		&conf.Tec2Min,
+		&conf.Tec2Max,
+		&conf.Fan1Threshold,
+		&conf.Fan1Min,
+		&conf.Fan1Max,
+		&conf.Fan2Threshold,
+		&conf.Fan2Min,
+		&conf.Fan2Max,
+		&conf.Pump1Threshold,
+		&conf.Pump1Min,
+		&conf.Pump1Max,
+		&conf.Pump2Threshold,
+		&conf.Pump2Min,
+		&conf.Pump2Max,
+		&conf.NpaMinValue,
+		&conf.NpaMaxValue,
+		&conf.NpaMinPressure,
+		&conf.NpaMaxPressure,
+		&conf.Stage,
+		&conf.OG,
+		&conf.BrewingStartTime,
+		&conf.PitchTime,
+		&conf.NpaTemperatureFilter,
+		&conf.NpaPressureFilter,
+		&conf.DsTemperatureFilter,
+		&conf.AdsValueFilter,
+		&conf.Name,
+		&conf.NpaCalibrationTemperature,
+		&conf.NpaReferenceTemperature,
+		&conf.NpaDrift,
+		&conf.SgModel[0],
+		&conf.SgModel[1],
+		&conf.SgModel[2])
+	if err != nil {
+		return nil, err
 	}
+	return conf, nil
 }
 
-func (hub *Hub) execDb(stmt string, f func(rows sql.Result)) {
-	if res, err := hub.db.Exec(stmt); err != nil {
-		log.Fatal(err)
-	} else {
-		if f != nil {
-			f(res)
-		}
+// sleep waits for d and reports false if ctx was done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
 	}
 }
-
 func query(name string) string {
 	if data, err := Asset("sql/" + name); err != nil {
 		panic(err)
@@ -469,60 +521,107 @@ func query(name string) string {
 	}
 }
 
-func (h *Hub) saveConfig() {
+// saveConfig stores the current configuration and the pending stage
+// events. On failure they stay in memory and the hub loop retries every
+// storageRetryPeriod.
+func (h *Hub) saveConfig() error {
 	h.dbLock.Lock()
 	defer h.dbLock.Unlock()
+	err := h.storeConfig()
+	h.configDirty = err != nil
+	return h.report(err)
+}
+
+func (h *Hub) storeConfig() error {
 	tx, err := h.db.Begin()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	stmt, err := tx.Prepare(query("updateLastConfig.sql"))
-	if err != nil {
-		panic(err)
-		log.Fatal(err)
+	if err := updateConfig(tx, h.Conf); err != nil {
+		tx.Rollback()
+		return err
 	}
-	defer stmt.Close()
+	for _, e := range h.pendingEvents {
+		if err := insertStageEvent(tx, e); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	h.pendingEvents = nil
+	return nil
+}
 
-	_, err = stmt.Exec(
-		h.Conf.FermenterSensor,
-		h.Conf.PresenceZero,
-		h.Conf.PresenceCalibration,
-		h.Conf.PresenceOnTimer,
-		h.Conf.PresenceEnabled,
-		int(h.Conf.PresenceTimeout/time.Second),
-		h.Conf.TemperatureScale,
-		h.Conf.TargetTemperature,
-		h.Conf.PidSlope,
-		h.Conf.NpaZero,
-		h.Conf.NpaCalibration,
-		h.Conf.Tec1Threshold,
-		h.Conf.Tec1Min,
-		h.Conf.Tec1Max,
-		h.Conf.Tec2Threshold,
-		h.Conf.Tec2Min,
-		h.Conf.Tec2Max,
-		h.Conf.Fan1Threshold,
-		h.Conf.Fan1Min,
-		h.Conf.Fan1Max,
-		h.Conf.Fan2Threshold,
-		h.Conf.Fan2Min,
-		h.Conf.Fan2Max,
-		h.Conf.Pump1Threshold,
-		h.Conf.Pump1Min,
-		h.Conf.Pump1Max,
-		h.Conf.Pump2Threshold,
-		h.Conf.Pump2Min,
-		h.Conf.Pump2Max,
-		h.Conf.NpaMinValue,
-		h.Conf.NpaMaxValue,
-		h.Conf.NpaMinPressure,
-		h.Conf.NpaMaxPressure,
-		h.Conf.Stage,
-		h.Conf.OG,
-		h.Conf.BrewingStartTime,
-		h.Conf.PitchTime)
-	if err != nil {
-		log.Fatal(err)
+// dirty reports whether the configuration or stage events wait to be
+// stored.
+func (h *Hub) dirty() bool {
+	h.dbLock.Lock()
+	defer h.dbLock.Unlock()
+	return h.configDirty
+}
+
+// current is a copy of the configuration in memory, or the stored one
+// before the hub has loaded it. The caller holds dbLock.
+func (h *Hub) current() (*config.Configuration, error) {
+	if h.Conf == nil {
+		return h.latestConfig()
 	}
-	tx.Commit()
+	conf := *h.Conf
+	return &conf, nil
+}
+
+func updateConfig(tx *sql.Tx, conf *config.Configuration) error {
+	_, err := tx.Exec(query("updateConfig.sql"),
+		conf.FermenterSensor,
+		conf.PresenceZero,
+		conf.PresenceCalibration,
+		conf.PresenceOnTimer,
+		conf.PresenceEnabled,
+		int(conf.PresenceTimeout/time.Second),
+		conf.TemperatureScale,
+		conf.TargetTemperature,
+		conf.PidSlope,
+		conf.NpaZero,
+		conf.NpaCalibration,
+		conf.Tec1Threshold,
+		conf.Tec1Min,
+		conf.Tec1Max,
+		conf.Tec2Threshold,
+		conf.Tec2Min,
+		conf.Tec2Max,
+		conf.Fan1Threshold,
+		conf.Fan1Min,
+		conf.Fan1Max,
+		conf.Fan2Threshold,
+		conf.Fan2Min,
+		conf.Fan2Max,
+		conf.Pump1Threshold,
+		conf.Pump1Min,
+		conf.Pump1Max,
+		conf.Pump2Threshold,
+		conf.Pump2Min,
+		conf.Pump2Max,
+		conf.NpaMinValue,
+		conf.NpaMaxValue,
+		conf.NpaMinPressure,
+		conf.NpaMaxPressure,
+		conf.Stage,
+		conf.OG,
+		conf.BrewingStartTime,
+		conf.PitchTime,
+		conf.NpaTemperatureFilter,
+		conf.NpaPressureFilter,
+		conf.DsTemperatureFilter,
+		conf.AdsValueFilter,
+		conf.Name,
+		conf.NpaCalibrationTemperature,
+		conf.NpaReferenceTemperature,
+		conf.NpaDrift,
+		conf.SgModel[0],
+		conf.SgModel[1],
+		conf.SgModel[2],
+		conf.Id)
+	return err
 }
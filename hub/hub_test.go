@@ -0,0 +1,35 @@
+package hub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/config"
+)
+
+func TestConfigurationSubscriberSendsBack(t *testing.T) {
+	h := newTestHub(t)
+	echo := h.Configuration.Subscribe()
+	defer echo.Close()
+	other := h.Configuration.Subscribe()
+	defer other.Close()
+
+	// echo sends back from its receive path, like the schedule runner, and
+	// other waits for it before it reads
+	sent := make(chan struct{})
+	go func() {
+		if c := <-echo.C; c.Id == 1 {
+			h.Configuration.Send(&config.Configuration{Id: 2})
+			close(sent)
+		}
+	}()
+	h.Configuration.Send(&config.Configuration{Id: 1})
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("the configuration topic is deadlocked")
+	}
+	assert.Equal(t, 1, (<-other.C).Id)
+	assert.Equal(t, 2, (<-other.C).Id)
+}
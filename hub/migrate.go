@@ -0,0 +1,183 @@
+package hub
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationPrefix names the embedded migration scripts, e.g.
+// sql/migration002ConfigFilters.sql is the script for schema version 2.
+const migrationPrefix = "sql/migration"
+
+type migration struct {
+	version int
+	name    string
+	script  string
+}
+
+// migrations returns the embedded migration scripts ordered by version.
+func migrations() []migration {
+	var ms []migration
+	for _, name := range AssetNames() {
+		if !strings.HasPrefix(name, migrationPrefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(name, migrationPrefix)
+		if len(rest) < 3 {
+			panic(fmt.Sprintf("migration %v has no version", name))
+		}
+		version, err := strconv.Atoi(rest[:3])
+		if err != nil {
+			panic(fmt.Sprintf("migration %v has no version: %v", name, err))
+		}
+		ms = append(ms, migration{version, name, query(strings.TrimPrefix(name, "sql/"))})
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].version < ms[j].version
+	})
+	return ms
+}
+
+// migrate brings the schema of db, stored in file, up to the latest
+// embedded migration.
+func migrate(db *sql.DB, file string) error {
+	return applyMigrations(db, file, migrations())
+}
+
+// applyMigrations runs every migration newer than the recorded schema
+// version, each in its own transaction. If the database already holds a
+// schema, it is backed up next to file before the first migration runs.
+func applyMigrations(db *sql.DB, file string, ms []migration) error {
+	if _, err := db.Exec(query("createSchemaVersionTable.sql")); err != nil {
+		return fmt.Errorf("can't create schema version table: %w", err)
+	}
+	version, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		if version, err = baseline(db); err != nil {
+			return err
+		}
+	}
+
+	var pending []migration
+	for _, m := range ms {
+		if m.version > version {
+			pending = append(pending, m)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if version > 0 {
+		if err := backup(db, fmt.Sprintf("%v.v%d.bak", file, version)); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Migrating database from version %d to %d\n", version, pending[len(pending)-1].version)
+	for _, m := range pending {
+		if err := apply(db, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func schemaVersion(db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRow(query("selectSchemaVersion.sql")).Scan(&version); err != nil {
+		return 0, fmt.Errorf("can't read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// baseline records the version of a database created before schema
+// versions were tracked, judging by the tables and columns it has.
+func baseline(db *sql.DB) (int, error) {
+	version := 0
+	if ok, err := exists(db, query("configTableExists.sql")); err != nil {
+		return 0, err
+	} else if ok {
+		version = 1
+	}
+	if ok, err := exists(db, query("configHasFilters.sql")); err != nil {
+		return 0, err
+	} else if ok {
+		version = 2
+	}
+	if version == 0 {
+		return 0, nil
+	}
+
+	log.Printf("Found database without schema version, recording it as version %d\n", version)
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	for v := 1; v <= version; v++ {
+		if _, err := tx.Exec(query("insertSchemaVersion.sql"), v, time.Now()); err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("can't record schema version %d: %w", v, err)
+		}
+	}
+	return version, tx.Commit()
+}
+
+func exists(db *sql.DB, stmt string) (bool, error) {
+	rows, err := db.Query(stmt)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	return rows.Next(), rows.Err()
+}
+
+// backup writes a consistent copy of db to file, replacing an older backup
+// of the same version.
+func backup(db *sql.DB, file string) error {
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("can't replace backup %v: %w", file, err)
+	}
+	if _, err := db.Exec("vacuum into ?", file); err != nil {
+		return fmt.Errorf("can't back up database to %v: %w", file, err)
+	}
+	log.Printf("Backed up database to %v\n", file)
+	return nil
+}
+
+func apply(db *sql.DB, m migration) error {
+	log.Printf("Applying migration %v\n", m.name)
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(m.script); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migration %v failed: %w", m.name, err)
+	}
+	if _, err := tx.Exec(query("insertSchemaVersion.sql"), m.version, time.Now()); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("can't record schema version %d: %w", m.version, err)
+	}
+	return tx.Commit()
+}
+
+// SchemaVersion returns the schema version of the database.
+func (h *Hub) SchemaVersion() (int, error) {
+	return schemaVersion(h.db)
+}
+
+// Backup writes a consistent copy of the database to file, replacing it if
+// it exists.
+func (h *Hub) Backup(file string) error {
+	return backup(h.db, file)
+}
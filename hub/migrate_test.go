@@ -0,0 +1,118 @@
+package hub
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestDb(t *testing.T) (*sql.DB, string) {
+	file := filepath.Join(t.TempDir(), "alcobot.db")
+	db, err := open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, file
+}
+
+func latest() int {
+	ms := migrations()
+	return ms[len(ms)-1].version
+}
+
+func TestMigrationsAreOrdered(t *testing.T) {
+	ms := migrations()
+	assert.NotEmpty(t, ms)
+	for i, m := range ms {
+		assert.Equal(t, i+1, m.version, m.name)
+	}
+}
+
+func TestMigrateCreatesSchema(t *testing.T) {
+	db, file := openTestDb(t)
+	assert.NoError(t, migrate(db, file))
+
+	version, err := schemaVersion(db)
+	assert.NoError(t, err)
+	assert.Equal(t, latest(), version)
+
+	var id int
+	var filter string
+	err = db.QueryRow("select id, AdsValueFilter from config").Scan(&id, &filter)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultAdsFilter, filter)
+
+	rows, err := db.Query(query("selectLatestConfig.sql"))
+	assert.NoError(t, err)
+	columns, _ := rows.Columns()
+	rows.Close()
+	assert.Len(t, columns, 49)
+
+	// a fresh database has nothing to back up
+	backups, _ := filepath.Glob(file + ".*.bak")
+	assert.Empty(t, backups)
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	db, file := openTestDb(t)
+	assert.NoError(t, migrate(db, file))
+	assert.NoError(t, migrate(db, file))
+
+	var count int
+	assert.NoError(t, db.QueryRow("select count(*) from config").Scan(&count))
+	assert.Equal(t, 1, count)
+	assert.NoError(t, db.QueryRow("select count(*) from schema_version").Scan(&count))
+	assert.Equal(t, latest(), count)
+}
+
+func TestMigrateUnversionedDatabase(t *testing.T) {
+	db, file := openTestDb(t)
+	// a database created before the filter columns and schema versions
+	_, err := db.Exec(query("migration001Initial.sql"))
+	assert.NoError(t, err)
+	_, err = db.Exec("update config set TargetTemperature = 18.5")
+	assert.NoError(t, err)
+
+	assert.NoError(t, migrate(db, file))
+
+	version, err := schemaVersion(db)
+	assert.NoError(t, err)
+	assert.Equal(t, latest(), version)
+
+	var temperature float64
+	var filter string
+	err = db.QueryRow("select TargetTemperature, DsTemperatureFilter from config").Scan(&temperature, &filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 18.5, temperature)
+	assert.Equal(t, defaultDsFilter, filter)
+
+	_, err = os.Stat(file + ".v1.bak")
+	assert.NoError(t, err)
+	backup, err := sql.Open("sqlite3", file+".v1.bak")
+	assert.NoError(t, err)
+	defer backup.Close()
+	ok, err := exists(backup, query("configHasFilters.sql"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestFailedMigrationIsRolledBack(t *testing.T) {
+	db, file := openTestDb(t)
+	assert.NoError(t, migrate(db, file))
+
+	broken := append(migrations(), migration{latest() + 1, "broken", "alter table config add column Broken text; select * from missing"})
+	assert.Error(t, applyMigrations(db, file, broken))
+
+	version, err := schemaVersion(db)
+	assert.NoError(t, err)
+	assert.Equal(t, latest(), version)
+	rows, err := db.Query("select Broken from config")
+	if err == nil {
+		rows.Close()
+	}
+	assert.Error(t, err)
+}
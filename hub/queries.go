@@ -1,14 +1,51 @@
 // Code generated by go-bindata.
 // sources:
+// sql/configHasFilters.sql
 // sql/configTableExists.sql
-// sql/createConfigTable.sql
-// sql/createDataTable.sql
-// sql/dataTableExists.sql
+// sql/createSchemaVersionTable.sql
+// sql/deleteCalibrationPoint.sql
+// sql/deleteControlSetpoints.sql
+// sql/deleteMinuteData.sql
+// sql/deleteRawData.sql
+// sql/deleteSchedule.sql
+// sql/insertCalibrationPoint.sql
+// sql/insertControlSetpoint.sql
+// sql/insertControlStats.sql
 // sql/insertDataPoint.sql
-// sql/insertDefaultConfig.sql
-// sql/selectDataPoints.sql
+// sql/insertNewBrew.sql
+// sql/insertScheduleStep.sql
+// sql/insertSchemaVersion.sql
+// sql/insertStageEvent.sql
+// sql/migration001Initial.sql
+// sql/migration002ConfigFilters.sql
+// sql/migration003Sessions.sql
+// sql/migration004StageEvents.sql
+// sql/migration005Rollups.sql
+// sql/migration006Schedule.sql
+// sql/migration007TemperatureCompensation.sql
+// sql/migration008Calibration.sql
+// sql/migration009ControlStats.sql
+// sql/migration010ControlSetpoints.sql
+// sql/rollupHours.sql
+// sql/rollupMinutes.sql
+// sql/schemaVersionTableExists.sql
+// sql/selectCalibrationPoints.sql
+// sql/selectConfig.sql
+// sql/selectControlSetpoints.sql
+// sql/selectControlStats.sql
+// sql/selectDataSG.sql
+// sql/selectHourSG.sql
 // sql/selectLatestConfig.sql
-// sql/updateLastConfig.sql
+// sql/selectMinuteSG.sql
+// sql/selectSchedule.sql
+// sql/selectSchemaVersion.sql
+// sql/selectSessionDataPoints.sql
+// sql/selectSessions.sql
+// sql/selectStageEvents.sql
+// sql/updateConfig.sql
+// sql/updateDataSG.sql
+// sql/updateHourSG.sql
+// sql/updateMinuteSG.sql
 // DO NOT EDIT!
 
 package hub
@@ -76,6 +113,26 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
+var _sqlConfighasfiltersSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x2b\x4e\xcd\x49\x4d\x2e\x51\xc8\x4b\xcc\x4d\x55\x48\x2b\xca\xcf\x55\x28\x28\x4a\x4c\xcf\x4d\x8c\x2f\x49\x4c\xca\x49\x8d\xcf\xcc\x4b\xcb\xd7\x50\x4f\xce\xcf\x4b\xcb\x4c\x57\xd7\x54\x28\xcf\x48\x2d\x4a\x85\xa8\xb5\x55\x50\x77\x4c\x29\x0e\x4b\xcc\x29\x4d\x75\xcb\xcc\x29\x49\x2d\x52\x07\x00\x74\xdd\x03\x61\x4a\x00\x00\x00")
+
+func sqlConfighasfiltersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlConfighasfiltersSql,
+		"sql/configHasFilters.sql",
+	)
+}
+
+func sqlConfighasfiltersSql() (*asset, error) {
+	bytes, err := sqlConfighasfiltersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/configHasFilters.sql", size: 74, mode: os.FileMode(420), modTime: time.Unix(1792410740, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _sqlConfigtableexistsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x0a\x76\xf5\x71\x75\x0e\x51\xc8\x4b\xcc\x4d\x55\x70\x0b\xf2\xf7\x55\x28\x2e\xcc\xc9\x2c\x49\x8d\xcf\x4d\x2c\x2e\x49\x2d\x52\x08\xf7\x70\x0d\x72\x55\x28\xa9\x2c\x48\xb5\x55\x2f\x49\x4c\xca\x49\x55\x57\x70\xf4\x73\x01\x2b\xb7\x55\x4f\xce\xcf\x4b\xcb\x4c\x57\xe7\x02\x04\x00\x00\xff\xff\x4d\x26\x89\x17\x44\x00\x00\x00")
 
 func sqlConfigtableexistsSqlBytes() ([]byte, error) {
@@ -96,62 +153,182 @@ func sqlConfigtableexistsSql() (*asset, error) {
 	return a, nil
 }
 
-var _sqlCreateconfigtableSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x8c\x94\xc1\x4e\xfb\x30\x0c\xc6\xcf\xdb\x53\xe4\xf8\xff\x4b\x5c\xd8\x23\x80\x18\x27\xd8\xa4\x56\x1c\xb8\x79\xad\xe9\x22\x52\xa7\xf2\x5c\xb1\xbd\x3d\x49\x27\x41\x17\x35\xc1\x91\x7a\x68\xfa\xcb\xf7\xe5\x73\x65\x37\x8c\x20\x68\x04\x0e\x0e\x4d\xe3\xe9\xc3\x76\xff\xd6\x26\x2c\xdb\x9a\xd5\xca\xcc\x96\x25\xc1\x0e\xd9\x0c\x6c\x7b\xe0\x8b\xf9\xc4\x8b\x81\x51\xbc\xa5\xa0\xd1\x23\xc9\xdd\x74\x6e\x8b\x1c\x5f\x90\x2b\xa4\x93\xe7\xe9\xa8\xe0\x59\x0c\xf9\xf0\x8c\xce\x5d\xb1\x3d\xe3\x09\xa9\xc1\x77\x64\x9f\x3a\x2c\x93\x8f\xe0\xec\x81\x41\xac\x27\x13\x2e\xed\x32\xd8\x8e\x6a\xdb\x23\x2b\x04\x9f\x28\x86\x6e\x15\x64\x54\xf4\xa3\x14\xc8\x1a\xfb\x01\xc3\xe5\x46\xc6\xaa\x81\x50\xca\x3c\x09\xdc\xa1\xcc\xf8\xb0\xb7\x14\xc7\xb6\x95\xf3\x03\xce\xff\xc0\x02\xf6\x3a\xc0\xbc\x82\x85\x1b\x06\x72\x5e\xc1\x9c\x60\x8d\xcd\x7d\x7d\x0c\xb9\x8f\xde\xb5\x45\xc1\x48\xbe\x58\x52\x58\x4f\x24\x9c\x75\xe4\x46\xed\xbe\x51\xbb\x6f\x74\xee\x5b\x20\x65\xf6\x48\xea\xdc\x27\x52\xeb\xae\xcc\x1e\x49\xb5\xbb\x32\xfb\x7e\xec\x87\x34\x7c\x81\x4c\xec\x4b\xe4\xad\x7d\x9e\x4c\xc3\x17\x48\xb5\x7b\x1a\x3e\xdb\x1a\x41\xf1\x0d\xdc\xf8\xdb\x6e\xcb\xbd\x16\xe4\x54\x98\xa5\x38\x3a\x4e\xd7\xee\x2e\xa9\xfd\x89\x55\x02\xdd\xcd\x10\xc8\xa6\xd8\x3d\x27\x03\x7b\x41\xed\x81\xf1\xcb\x52\x17\x44\x59\xe2\x50\x8b\x7b\x6d\x9c\xff\xe9\xf0\x91\xe6\x18\xbf\xff\xe8\xdd\x40\xeb\xff\xeb\xef\x00\x00\x00\xff\xff\x6b\x39\x28\xcf\x37\x06\x00\x00")
+var _sqlCreateschemaversiontableSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x55\x8d\xc1\x0a\x83\x30\x10\x05\xef\xf9\x8a\x77\xac\xe0\x3f\x95\xd5\x3c\xed\xd2\x18\xc3\x66\x15\xf3\xf7\x6d\x4a\x0f\xed\xdc\x06\x06\x66\x36\x8a\x13\x2e\x53\x22\x74\x41\xde\x1d\xbc\xb4\x7a\x45\x9d\x1f\xdc\xe4\x7e\xd2\xaa\xee\xf9\x16\xf0\xe6\x2b\xf8\x45\xb3\x73\xa5\xa1\x98\x6e\x62\x0d\x4f\xb6\xf1\x13\x4b\x29\x49\x19\xff\xe2\xd8\x6f\x7d\x92\x8f\x94\xc2\xf0\x02\x04\xc4\x95\x8e\x7f\x00\x00\x00")
 
-func sqlCreateconfigtableSqlBytes() ([]byte, error) {
+func sqlCreateschemaversiontableSqlBytes() ([]byte, error) {
 	return bindataRead(
-		_sqlCreateconfigtableSql,
-		"sql/createConfigTable.sql",
+		_sqlCreateschemaversiontableSql,
+		"sql/createSchemaVersionTable.sql",
 	)
 }
 
-func sqlCreateconfigtableSql() (*asset, error) {
-	bytes, err := sqlCreateconfigtableSqlBytes()
+func sqlCreateschemaversiontableSql() (*asset, error) {
+	bytes, err := sqlCreateschemaversiontableSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "sql/createConfigTable.sql", size: 1591, mode: os.FileMode(420), modTime: time.Unix(1457490398, 0)}
+	info := bindataFileInfo{name: "sql/createSchemaVersionTable.sql", size: 127, mode: os.FileMode(420), modTime: time.Unix(1792411123, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _sqlCreatedatatableSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x84\xcd\x41\x0a\xc2\x30\x10\x85\xe1\x75\x72\x8a\x59\xb6\xe0\x2d\x14\xc4\x9d\xd0\x5e\x20\x26\x2f\x21\x18\x27\x32\x4e\x11\x6f\x6f\x0a\x01\xa9\x08\xce\xf2\x9b\x07\xbf\x17\x38\x05\xa9\xbb\x14\x50\x70\xea\x06\x6b\x72\xa0\xcd\x65\x56\x24\x08\x71\x55\xe2\xa5\x94\x9d\x35\x93\xe2\xfe\x67\x32\x3b\x49\xd0\x19\xb7\x3e\x6c\xa1\x95\xf7\x8b\x08\xf8\xe3\x9d\xa7\xe3\xb6\xd9\xf9\x7c\x3a\xfc\xe4\xfa\x6c\xb1\x6f\xb6\x26\x56\x41\x4e\x4c\x57\xbc\x68\xc8\x61\x6c\x8f\x88\x96\xf3\x78\x90\xaf\x1c\x73\x5a\xd5\x8e\xf6\x1d\x00\x00\xff\xff\xfc\xe1\x63\x66\xf7\x00\x00\x00")
+var _sqlDeletecalibrationpointSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x4b\x49\xcd\x49\x2d\x49\x55\x48\x2b\xca\xcf\x55\x48\x4e\xcc\xc9\x4c\x2a\x4a\x2c\xc9\xcc\xcf\x53\x28\xcf\x48\x2d\x4a\x55\xc8\x4c\x51\xb0\x55\xb0\x57\x48\xcc\x4b\x51\x08\xc9\xcc\x4d\x05\x71\x00\x96\x5d\x3b\xae\x31\x00\x00\x00")
 
-func sqlCreatedatatableSqlBytes() ([]byte, error) {
+func sqlDeletecalibrationpointSqlBytes() ([]byte, error) {
 	return bindataRead(
-		_sqlCreatedatatableSql,
-		"sql/createDataTable.sql",
+		_sqlDeletecalibrationpointSql,
+		"sql/deleteCalibrationPoint.sql",
 	)
 }
 
-func sqlCreatedatatableSql() (*asset, error) {
-	bytes, err := sqlCreatedatatableSqlBytes()
+func sqlDeletecalibrationpointSql() (*asset, error) {
+	bytes, err := sqlDeletecalibrationpointSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "sql/createDataTable.sql", size: 247, mode: os.FileMode(420), modTime: time.Unix(1457491117, 0)}
+	info := bindataFileInfo{name: "sql/deleteCalibrationPoint.sql", size: 49, mode: os.FileMode(420), modTime: time.Unix(1792413846, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _sqlDatatableexistsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x0a\x76\xf5\x71\x75\x0e\x51\xc8\x4b\xcc\x4d\x55\x70\x0b\xf2\xf7\x55\x28\x2e\xcc\xc9\x2c\x49\x8d\xcf\x4d\x2c\x2e\x49\x2d\x52\x08\xf7\x70\x0d\x72\x55\x28\xa9\x2c\x48\xb5\x55\x2f\x49\x4c\xca\x49\x55\x57\x70\xf4\x73\x01\x2b\xb7\x55\x4f\x49\x2c\x49\x54\xe7\x02\x04\x00\x00\xff\xff\x66\x7d\xbd\x56\x42\x00\x00\x00")
+var _sqlDeletecontrolsetpointsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x4b\x49\xcd\x49\x2d\x49\x55\x48\x2b\xca\xcf\x55\x48\xce\xcf\x2b\x29\xca\xcf\x89\x2f\x4e\x2d\x29\xc8\xcf\xcc\x2b\x29\x56\x28\xcf\x48\x2d\x4a\x55\xc8\x4c\x51\xb0\x55\xb0\x07\x00\xc8\x5a\x54\x3b\x2a\x00\x00\x00")
 
-func sqlDatatableexistsSqlBytes() ([]byte, error) {
+func sqlDeletecontrolsetpointsSqlBytes() ([]byte, error) {
 	return bindataRead(
-		_sqlDatatableexistsSql,
-		"sql/dataTableExists.sql",
+		_sqlDeletecontrolsetpointsSql,
+		"sql/deleteControlSetpoints.sql",
 	)
 }
 
-func sqlDatatableexistsSql() (*asset, error) {
-	bytes, err := sqlDatatableexistsSqlBytes()
+func sqlDeletecontrolsetpointsSql() (*asset, error) {
+	bytes, err := sqlDeletecontrolsetpointsSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "sql/dataTableExists.sql", size: 66, mode: os.FileMode(420), modTime: time.Unix(1457490089, 0)}
+	info := bindataFileInfo{name: "sql/deleteControlSetpoints.sql", size: 42, mode: os.FileMode(420), modTime: time.Unix(1792416946, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlDeleteminutedataSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x4b\x49\xcd\x49\x2d\x49\x55\x48\x2b\xca\xcf\x55\x48\x49\x2c\x49\x8c\xcf\xcd\xcc\x2b\x05\x0a\x94\x67\xa4\x16\xa5\x2a\x64\xa6\x28\xd8\x2a\xd8\x2b\x24\xe6\xa5\x28\x38\x95\x26\x67\xa7\x96\x28\xd8\x28\xd8\x03\x00\x7c\xe8\x86\xf6\x33\x00\x00\x00")
+
+func sqlDeleteminutedataSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlDeleteminutedataSql,
+		"sql/deleteMinuteData.sql",
+	)
+}
+
+func sqlDeleteminutedataSql() (*asset, error) {
+	bytes, err := sqlDeleteminutedataSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/deleteMinuteData.sql", size: 51, mode: os.FileMode(420), modTime: time.Unix(1792411832, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlDeleterawdataSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x4b\x49\xcd\x49\x2d\x49\x55\x48\x2b\xca\xcf\x55\x48\x49\x2c\x49\x54\x28\xcf\x48\x2d\x4a\x55\xc8\x4c\x51\xb0\x55\xb0\x57\x48\xcc\x4b\x51\x08\x2e\x49\x2d\x50\xb0\x01\xf2\x00\x51\xdc\xa8\x4c\x2b\x00\x00\x00")
+
+func sqlDeleterawdataSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlDeleterawdataSql,
+		"sql/deleteRawData.sql",
+	)
+}
+
+func sqlDeleterawdataSql() (*asset, error) {
+	bytes, err := sqlDeleterawdataSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/deleteRawData.sql", size: 43, mode: os.FileMode(420), modTime: time.Unix(1792411832, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlDeletescheduleSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x4b\x49\xcd\x49\x2d\x49\x55\x48\x2b\xca\xcf\x55\x28\x4e\xce\x48\x4d\x29\xcd\x49\x55\x28\xcf\x48\x2d\x4a\x55\xc8\x4c\x51\xb0\x55\xb0\x07\x00\x58\xcb\x49\xed\x21\x00\x00\x00")
+
+func sqlDeletescheduleSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlDeletescheduleSql,
+		"sql/deleteSchedule.sql",
+	)
+}
+
+func sqlDeletescheduleSql() (*asset, error) {
+	bytes, err := sqlDeletescheduleSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/deleteSchedule.sql", size: 33, mode: os.FileMode(420), modTime: time.Unix(1792412303, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlInsertcalibrationpointSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xcb\xcc\x2b\x4e\x2d\x2a\x51\xc8\xcc\x2b\xc9\x57\x48\x4e\xcc\xc9\x4c\x2a\x4a\x2c\xc9\xcc\xcf\xd3\xc8\x4c\xd1\x51\x08\xc9\xcc\x4d\xd5\x51\x08\x28\x4a\x2d\x2e\x2e\x2d\x02\xb2\x42\x52\x73\x0b\x52\x81\xf2\x60\x4e\xb0\x3b\x10\xe7\x97\x16\x25\xa7\x6a\x2a\x94\x25\xe6\x94\xa6\x16\x2b\x68\xd8\xeb\x28\xa0\x20\x4d\x00\xcd\xd8\x60\x20\x5e\x00\x00\x00")
+
+func sqlInsertcalibrationpointSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlInsertcalibrationpointSql,
+		"sql/insertCalibrationPoint.sql",
+	)
+}
+
+func sqlInsertcalibrationpointSql() (*asset, error) {
+	bytes, err := sqlInsertcalibrationpointSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/insertCalibrationPoint.sql", size: 94, mode: os.FileMode(420), modTime: time.Unix(1792413846, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlInsertcontrolsetpointSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x55\x88\x41\x0a\xc0\x20\x0c\x04\xbf\x92\xa3\x82\x7f\xe8\x13\x7a\xa8\xf7\x22\x76\xb1\x01\x6b\xc4\xa4\xbe\xbf\x5e\x0b\x03\x33\x0c\x37\xc5\x30\xe2\x66\x42\x59\x9a\x0d\xa9\xa7\xc2\xba\xac\xa3\x8e\xaf\x40\x47\x96\x8e\x25\x43\x0f\x14\xd3\x28\xb0\x40\xfb\xc4\xd0\x5b\x64\xe5\x01\xb3\xca\xad\x44\x7e\xe0\x69\xa6\xfa\x42\xc9\x6d\x81\x7e\xf8\x0f\x60\x6f\xa8\x1a\x69\x00\x00\x00")
+
+func sqlInsertcontrolsetpointSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlInsertcontrolsetpointSql,
+		"sql/insertControlSetpoint.sql",
+	)
+}
+
+func sqlInsertcontrolsetpointSql() (*asset, error) {
+	bytes, err := sqlInsertcontrolsetpointSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/insertControlSetpoint.sql", size: 105, mode: os.FileMode(420), modTime: time.Unix(1792416946, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlInsertcontrolstatsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x85\x8c\x41\x0a\xc2\x40\x0c\x45\xaf\x92\x65\x0b\x73\x07\x51\xba\x71\x51\x94\xd6\x7d\x09\x63\xa8\x81\x31\x29\x99\x74\xf4\xf8\x4e\xa5\x0b\x77\x42\x16\xff\x7d\xde\x0f\x4b\x26\x73\x50\x03\xa3\x25\x61\x24\x60\x71\x85\xa8\xe2\xa6\x69\xca\x8e\x9e\x1b\xbe\x07\x18\xa3\x2e\x14\xa0\x5b\x0d\x9d\x55\x02\x0c\xfd\xd8\x51\xe1\x9d\x7a\x7c\xff\xd0\x59\x4e\x28\xdb\x88\x7c\xd1\xfa\x30\x07\xb8\x14\xb2\xfc\x50\xf5\x6f\xeb\x89\x65\xbe\xf1\x93\x76\xa2\x2a\x0f\xb4\x29\x98\xaa\x7c\xac\x09\x67\xba\xea\x8b\xac\x85\x82\x69\xa5\x0c\xcd\x21\xc0\x9f\x6b\x3f\xb3\x4a\xbf\x10\xcf\x00\x00\x00")
+
+func sqlInsertcontrolstatsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlInsertcontrolstatsSql,
+		"sql/insertControlStats.sql",
+	)
+}
+
+func sqlInsertcontrolstatsSql() (*asset, error) {
+	bytes, err := sqlInsertcontrolstatsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/insertControlStats.sql", size: 207, mode: os.FileMode(420), modTime: time.Unix(1792414563, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -176,47 +353,467 @@ func sqlInsertdatapointSql() (*asset, error) {
 	return a, nil
 }
 
-var _sqlInsertdefaultconfigSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x84\x93\x4f\x6f\xe2\x30\x10\xc5\xcf\xf0\x29\x2c\x4e\x8b\xb4\x8b\xc8\x1f\xfe\xec\x75\x57\xa5\xa7\x16\xa4\xa0\x1e\x7a\x33\x61\x1a\x2c\x25\xe3\xc8\x71\x5a\x3e\x7e\x63\x6c\x88\x6d\xd9\xaa\x2f\x33\x79\xbf\xcc\xe3\x8d\x88\x19\x76\x20\x24\x61\x28\x39\x29\x39\x7e\xb0\x8a\xfc\x9a\x92\xe1\xec\x40\x34\x80\x12\x44\x01\xd8\x71\xa1\x24\xf2\xfb\x46\x0e\x02\x3a\xc0\x12\xde\x41\x70\x62\x8e\x4b\xfe\xd3\x9a\x9d\x04\x95\x8c\xa3\x47\xf6\x78\x64\x0d\x84\xdc\x9e\x90\x9e\x6a\x38\x07\x88\x9a\xe0\xbd\xb4\xc8\x11\x9a\x16\x06\xff\x5e\x40\x51\xd2\x1a\x2c\x42\x45\x05\xd2\xe2\xa3\x1b\x3b\x17\x35\x6f\x81\x58\x47\x93\xd7\x96\xda\xab\xb8\xc4\x5e\xc5\x49\x50\x26\xc7\xcb\x10\xf0\xc2\xeb\x33\xf1\xc9\x0b\xc3\x80\xdb\x8d\xd0\x6b\x98\xa4\x51\xb7\x34\xea\x96\x86\xdd\x76\x14\x23\xd9\x14\x09\xbb\xdd\x48\xcc\x2d\x92\x4d\x91\xa8\x5b\x24\xdb\xa1\x6f\x5a\x3f\x9c\x45\x3c\x3b\x9b\xb8\x76\x23\xf1\xc3\x59\x24\xea\xe6\x87\x7b\xfc\xdb\xc3\xc4\x1b\xad\x7b\x08\x10\x7a\x8d\x11\x86\xea\x4b\xed\xf4\xc7\xe6\xcd\x84\x48\x21\x69\x05\x93\x89\x6b\xb4\x7f\x1e\x95\x51\xfd\x27\xe0\x8b\x61\x35\x4c\x08\xa9\xae\x81\xb5\x06\x93\xe5\x45\x49\xd3\x39\xf9\x54\xc1\x3a\x73\x6f\x67\x33\xfd\x42\x9e\x2c\x97\xba\x1b\x1a\xd3\xe5\x46\x30\x65\xa5\xab\x81\x69\xb2\x30\x20\x5d\xac\x5c\x14\x2c\x69\xf8\xa5\x87\x9c\xe9\xb2\xba\xeb\xd9\x0f\xba\xf9\xf1\xec\xae\x3f\xe2\x7b\xfa\xbd\x49\xd6\xd9\xd6\x74\xf9\x26\x37\x26\x7f\xd6\xdb\xbf\xf9\x62\xb3\xd6\x4f\xce\x43\x78\x97\xe9\xfc\x3b\x00\x00\xff\xff\xde\xe8\x08\xaf\x02\x05\x00\x00")
+var _sqlInsertnewbrewSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xed\x96\xb1\x6e\xc2\x30\x10\x86\x77\x9e\xe2\x46\x90\x3a\x80\xf7\xaa\x6a\x4b\xe9\x44\x41\x0d\xea\xd0\xcd\x24\x97\xe4\x24\xc7\x8e\x1c\x47\xf0\xf8\x75\x12\x08\x4e\xb0\x3b\xb5\x55\x07\x3c\xfa\xe3\xbe\xfc\x26\xbe\x53\x48\x56\xa8\x0d\x90\x34\x0a\x62\x25\x53\xca\x60\x3a\x01\xbb\x56\xa8\x0b\x94\x06\x75\x84\xb2\x52\xba\xd9\x82\xbb\x96\x6c\x35\x56\x28\x63\xfc\x44\xad\xe0\xb4\x86\xe4\x99\x0b\xda\x6b\x6e\x48\xc9\x11\xd9\xc8\x1d\x15\xe8\xb3\xbd\x48\xbe\x17\x98\x78\x48\x53\xa1\x6a\xe3\x90\x1d\x16\x25\x5a\x7f\xad\x31\x8a\xb9\x40\x87\x70\x9d\xa1\x71\xf8\xc5\x46\x49\x24\x54\x89\xe0\xac\x8e\xbc\x95\xdc\x3d\xca\x90\xb8\x47\x19\x24\x88\x17\xbb\xdc\x06\xcc\x95\x48\x60\x4c\xd6\x24\x3d\xb6\x96\xf0\xa3\x9f\xb0\xa0\x8d\x05\x6d\xcc\x6f\x5b\x71\x19\xc8\xd6\x10\xbf\xad\x25\x21\x1b\x0b\xda\x58\xd0\x16\xc8\xb6\xad\x8b\x72\x1c\xce\x21\x23\x9d\x4b\x86\xba\x0b\x61\x41\x1b\x0b\xda\x98\xd7\x66\xdf\xb6\xad\xf8\xe0\xa2\x46\x0f\xe1\xc7\x10\x21\xd9\xdc\xd4\xaa\xbb\x6c\xa3\x1a\x1f\x89\x0c\xcf\x06\xd7\xb0\x27\x9b\x57\xb8\x5a\x1d\x79\xd2\x78\x20\x99\xd9\x52\x6d\x9a\x7e\x70\xce\x43\x26\xce\xcf\x5b\xe3\x6c\x4e\x23\xac\x48\xd8\x66\xee\xc9\x39\x58\xb7\xdd\xd7\x2c\xab\xab\x92\x13\x79\x4c\xaa\xf6\x0f\xe8\x0b\x2e\xcf\xe1\x05\xfa\x53\x0f\xfb\xc7\x31\xf7\xf8\x1d\x53\xd4\x6d\x93\x7b\xe0\x52\x53\x6a\xae\xad\x51\xb6\x56\x09\x8a\x79\x90\x2c\x82\x84\x4d\x66\x50\xa1\xc0\xd8\xdc\x66\xdc\x6d\xc6\xdd\x66\xdc\xaf\xcd\xb8\x39\x84\x26\xd9\xcf\x92\xbf\x9a\x71\x0f\xdf\x25\xf8\x6f\x33\x2e\xd5\xaa\x38\x7f\xcc\x1d\x72\xfb\x6c\xa0\x04\xee\x61\xda\x4d\x3e\x28\xf8\x71\x4a\xc9\x0c\x9c\x9f\xcd\xbe\x00\x70\xbc\x08\x7e\x07\x0a\x00\x00")
 
-func sqlInsertdefaultconfigSqlBytes() ([]byte, error) {
+func sqlInsertnewbrewSqlBytes() ([]byte, error) {
 	return bindataRead(
-		_sqlInsertdefaultconfigSql,
-		"sql/insertDefaultConfig.sql",
+		_sqlInsertnewbrewSql,
+		"sql/insertNewBrew.sql",
 	)
 }
 
-func sqlInsertdefaultconfigSql() (*asset, error) {
-	bytes, err := sqlInsertdefaultconfigSqlBytes()
+func sqlInsertnewbrewSql() (*asset, error) {
+	bytes, err := sqlInsertnewbrewSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "sql/insertDefaultConfig.sql", size: 1282, mode: os.FileMode(420), modTime: time.Unix(1457900834, 0)}
+	info := bindataFileInfo{name: "sql/insertNewBrew.sql", size: 2567, mode: os.FileMode(420), modTime: time.Unix(1792413846, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _sqlSelectdatapointsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x2c\xca\xc1\x0d\x80\x20\x0c\x05\xd0\x55\xfe\x11\xdc\xc1\x61\x90\x7e\xb4\x89\x58\x53\x9a\xa8\xdb\x7b\xd0\xf3\x7b\x83\x3b\x6b\x60\x42\x73\xeb\x90\x12\x05\xd7\x46\x27\x54\x30\x23\xfd\xdc\xcb\x9d\x54\xf2\x97\xaa\x1d\x4d\xd7\x0c\x73\xa1\x63\x79\x30\x82\xe7\x1b\x00\x00\xff\xff\x57\x2f\xd8\x05\x48\x00\x00\x00")
+var _sqlInsertschedulestepSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xcb\xcc\x2b\x4e\x2d\x2a\x51\xc8\xcc\x2b\xc9\x57\x28\x4e\xce\x48\x4d\x29\xcd\x49\xd5\xc8\x4c\xd1\x51\x08\xc8\x2f\xce\x2c\xc9\xcc\xcf\xd3\x51\xf0\x4b\xcc\x4d\xd5\x51\x08\x49\xcd\x2d\x48\x2d\x4a\x2c\x29\x2d\x02\x72\x5c\x4a\x81\x2c\xa0\xa4\xa6\x42\x59\x62\x4e\x69\x6a\xb1\x82\x86\xbd\x8e\x02\x02\x69\x02\x00\xbd\xef\xec\xb2\x56\x00\x00\x00")
 
-func sqlSelectdatapointsSqlBytes() ([]byte, error) {
+func sqlInsertschedulestepSqlBytes() ([]byte, error) {
 	return bindataRead(
-		_sqlSelectdatapointsSql,
-		"sql/selectDataPoints.sql",
+		_sqlInsertschedulestepSql,
+		"sql/insertScheduleStep.sql",
 	)
 }
 
-func sqlSelectdatapointsSql() (*asset, error) {
-	bytes, err := sqlSelectdatapointsSqlBytes()
+func sqlInsertschedulestepSql() (*asset, error) {
+	bytes, err := sqlInsertschedulestepSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "sql/selectDataPoints.sql", size: 72, mode: os.FileMode(420), modTime: time.Unix(1457491117, 0)}
+	info := bindataFileInfo{name: "sql/insertScheduleStep.sql", size: 86, mode: os.FileMode(420), modTime: time.Unix(1792412303, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _sqlSelectlatestconfigSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x2a\x4e\xcd\x49\x4d\x2e\x51\xd0\x52\x48\x2b\xca\xcf\x55\x48\xce\xcf\x4b\xcb\x4c\x57\x28\xcf\x48\x2d\x4a\x55\xc8\x4c\x51\xb0\x55\xd0\x80\x2a\xc8\x4d\xac\xd0\xc8\x4c\xd1\x44\x56\xa6\x09\x08\x00\x00\xff\xff\x4e\xf7\x0c\x8a\x3c\x00\x00\x00")
+var _sqlInsertschemaversionSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xcb\xcc\x2b\x4e\x2d\x2a\x51\xc8\xcc\x2b\xc9\x57\x28\x4e\xce\x48\xcd\x4d\x8c\x2f\x4b\x2d\x2a\xce\xcc\xcf\xd3\x80\xd2\x3a\x0a\x89\x05\x05\x39\x99\xa9\x29\x9a\x0a\x65\x89\x39\xa5\xa9\xc5\x0a\x1a\xf6\x3a\x0a\xf6\x9a\x00\x19\x84\x26\x65\x3a\x00\x00\x00")
+
+func sqlInsertschemaversionSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlInsertschemaversionSql,
+		"sql/insertSchemaVersion.sql",
+	)
+}
+
+func sqlInsertschemaversionSql() (*asset, error) {
+	bytes, err := sqlInsertschemaversionSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/insertSchemaVersion.sql", size: 58, mode: os.FileMode(420), modTime: time.Unix(1792411123, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlInsertstageeventSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xcb\xcc\x2b\x4e\x2d\x2a\x51\xc8\xcc\x2b\xc9\x57\x28\x2e\x49\x4c\x4f\x8d\x4f\x2d\x4b\xcd\x2b\x29\xd6\xc8\x4c\xd1\x51\x08\xc9\xcc\x4d\xd5\x51\x70\x05\x09\xe8\x28\xb8\x15\xe5\xe7\x06\x83\x54\x00\xc5\xf3\xc1\x0c\x4d\x85\xb2\xc4\x9c\xd2\xd4\x62\x05\x0d\x7b\x1d\x05\x04\xd2\x04\x00\x83\xe8\x62\xe7\x54\x00\x00\x00")
+
+func sqlInsertstageeventSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlInsertstageeventSql,
+		"sql/insertStageEvent.sql",
+	)
+}
+
+func sqlInsertstageeventSql() (*asset, error) {
+	bytes, err := sqlInsertstageeventSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/insertStageEvent.sql", size: 84, mode: os.FileMode(420), modTime: time.Unix(1792411425, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlMigration001initialSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x8d\x55\x4d\x8f\x9b\x30\x10\x3d\x87\x5f\xe1\xdb\x26\x52\x1b\x6d\xc8\xc7\x6e\xb5\xb7\xdd\x76\x57\x3d\xb4\x1b\x89\xa8\x87\xde\xbc\x30\x21\x56\xc1\x46\x83\x51\xb2\xff\xbe\x36\x38\x09\x20\x86\x38\x17\x9b\x37\x8f\x79\x1e\x47\xbc\x17\x23\x70\x0d\x4c\xf3\x8f\x0c\x98\xd8\x33\xa9\x34\x83\x93\x28\x75\xc9\x62\x25\xf7\x22\x9d\x06\xcc\xfc\x44\xc2\x26\x13\xd6\xfa\x09\xa9\x21\x05\x64\x05\x8a\x9c\xe3\x27\xfb\x07\x9f\x8c\x57\x5a\x09\x19\x23\xe4\x20\xf5\x97\xfa\xbd\x57\x40\xfb\x00\x18\x81\x2c\x15\xd6\xaf\x6a\x38\xe9\x5a\x47\x56\x59\xd6\xd0\xb6\x08\x25\xc8\x18\xfe\x02\xaa\xbe\xc2\x30\xf3\x85\x67\xe2\x03\xb9\x16\x4a\x32\x33\x42\x46\xd0\xde\xe5\x4e\xe4\x80\x1e\x0d\x7f\x48\x7b\x05\x89\x07\xd3\x76\x54\x95\x1e\x61\xee\x20\x2f\xc0\x1c\xae\x42\x88\x62\x6e\x2e\x96\x66\x72\x4c\x41\xb7\xf8\x06\x1b\x1a\x47\x24\x51\xa6\x0a\x68\xff\x03\x03\xb4\xdf\x05\x6f\xdf\xe0\xc8\x09\x0d\xb3\x7d\x83\x54\xc3\x1d\xc4\x8b\xdd\xc1\xcc\x7d\x50\x59\x32\xda\xd0\x32\x7f\x09\xe9\x21\x5d\x33\xf9\xc9\x8f\x19\x7a\xab\x87\xde\xea\xa1\x9f\xfa\x2b\x97\x9e\xb3\x5b\xa6\x9f\x7a\xcd\xf4\x55\x0f\xbd\xd5\x43\x6f\x75\xcf\xd9\xb7\x55\x5e\xf4\x87\x1f\x61\xf6\xe4\xc7\x98\x5d\x79\x9a\x19\x7a\xab\x87\xde\xea\xa1\x97\xba\xf9\x34\x4c\xc7\x3f\x3c\xab\xe0\xc6\xb7\x66\xda\x79\xd1\x84\xb4\xd6\x51\x36\x5f\xf7\x58\xb7\x9b\xb4\x48\xf3\xb4\x63\x02\xe4\x14\xef\x6f\x3d\xc3\x1e\xe8\xf6\x8c\x70\x14\x32\x35\x4d\x51\x5b\x53\xb3\x58\x62\xd3\xa0\x6f\x3e\x3a\x3e\xd8\xfa\xa5\x5f\x87\x14\xcc\x9e\x82\x20\xa6\x63\xc4\x90\xf9\x34\x98\x88\xe4\xd6\xb1\x27\x91\x86\xe2\x06\xe5\xea\x97\xd7\xa9\x0c\xfc\x52\x21\x9a\x98\xb9\xe0\x0e\x8e\xde\xba\x9a\x0e\xde\xfe\xfc\x3e\x08\xab\xa3\x4b\x8a\x36\x1c\x4c\xf6\x0a\x41\xa4\xb2\x8e\xb8\xa9\x48\x66\xa6\xb0\x07\xb4\x49\x70\xc9\x48\x83\xd6\xd7\x20\x64\x09\xa8\xed\xc1\x95\x2b\xb1\x29\x99\x83\x74\xf4\xd1\x51\x47\xa7\x1b\x9d\x66\x74\x7a\xd1\x69\x45\xa7\x13\x1d\x48\x74\x06\xd1\x99\x43\xc7\x0c\x1d\x2b\x74\x8c\xd0\xb1\x41\xc7\x04\x1d\x0b\x74\x0c\xd0\xb6\x4f\xdb\x3c\x6d\xeb\xb4\x8d\xd3\xb6\x4d\xdb\x34\x6d\xcb\xb4\x0d\xd3\xb6\x4b\xdb\x2c\x6d\xab\xb4\x8d\xd2\xce\x49\x9b\x25\xed\x8f\x2d\x4b\xbc\x5a\xdd\xd9\xfd\xba\xe6\x47\xfb\x5d\xcf\xe2\x82\x19\x2b\x21\x83\x58\xd7\xf0\xdd\x5d\x53\x5e\x2d\xee\xef\x9b\x9d\xd9\xb8\xdd\xca\x01\x6e\x59\x37\xab\x2b\x86\x8b\xb9\x2b\x84\xf3\x75\xb7\x34\xb8\x84\xc3\xa4\x0b\xbc\x6c\x96\xf5\x19\x5f\xde\xc0\x9d\xf8\xf2\x8c\x5f\x8e\xdf\xc3\xcf\x9b\xc5\x66\xf9\xe8\x76\xab\x87\x95\x6b\xf2\x75\xf3\xf8\x6d\x35\x7f\xd8\x34\x4f\x9d\x87\xe1\x59\x82\xe3\xc1\xf8\x62\xdb\xfb\xa7\xcd\x65\xb2\x05\xdb\xa3\xca\x9d\x25\xce\xfe\x03\xd7\x4c\x64\xe1\x74\x0c\x00\x00")
+
+func sqlMigration001initialSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlMigration001initialSql,
+		"sql/migration001Initial.sql",
+	)
+}
+
+func sqlMigration001initialSql() (*asset, error) {
+	bytes, err := sqlMigration001initialSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/migration001Initial.sql", size: 3188, mode: os.FileMode(420), modTime: time.Unix(1792411123, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlMigration002configfiltersSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x4b\xcc\x29\x49\x2d\x52\x28\x49\x4c\xca\x49\x55\x48\xce\xcf\x4b\xcb\x4c\x57\x48\x4c\x49\x01\x32\x73\x4a\x73\xf3\x14\xfc\x0a\x12\x43\x52\x73\x0b\x52\x8b\x12\x4b\x4a\x8b\x52\xdd\x32\x21\x8a\x53\x2b\x4a\x14\xf2\xf2\x81\xb8\x34\x27\x47\x21\x25\x35\x2d\xb1\x34\xa7\x44\x41\xbd\xa4\x28\x33\x37\x37\x35\xc5\xca\xd0\xc0\x40\xc7\xc8\x40\xdd\x9a\x2b\x91\x90\xd1\x01\x45\xa9\xc5\xc5\xd4\x36\xd7\xa5\x98\x64\x17\x1b\x1b\xe8\x18\x12\x36\xd8\x31\xa5\x38\x2c\x31\xa7\x94\x34\xd7\x02\x00\x6a\x84\xf4\xcc\x5f\x01\x00\x00")
+
+func sqlMigration002configfiltersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlMigration002configfiltersSql,
+		"sql/migration002ConfigFilters.sql",
+	)
+}
+
+func sqlMigration002configfiltersSql() (*asset, error) {
+	bytes, err := sqlMigration002configfiltersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/migration002ConfigFilters.sql", size: 351, mode: os.FileMode(420), modTime: time.Unix(1792410740, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlMigration003sessionsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x1d\x8a\x41\x0a\xc3\x30\x0c\x04\xef\x7d\xc5\xde\xd2\x40\x7f\xd0\x3f\xf4\x92\x17\x28\xd1\xba\x08\x14\xb9\xc4\x32\xf8\xf9\x75\x0b\x3b\x30\x03\x2b\x9e\xbc\x90\xb2\x3b\x71\xd4\x28\xf6\x86\xa8\x4e\xf5\x7e\x06\x5e\x72\x12\xc9\x91\x88\x3a\xe9\xee\x50\x16\xe9\x9e\x58\x96\xe7\xed\xb8\x28\x49\x58\x28\x07\xac\xfc\x4f\x1c\xd6\xb2\x41\x25\x65\x63\x6b\x56\x03\x73\xbf\xbc\x9b\x3e\xb0\x25\x3f\xeb\x17\xbb\x33\x05\xcc\x75\x00\x00\x00")
+
+func sqlMigration003sessionsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlMigration003sessionsSql,
+		"sql/migration003Sessions.sql",
+	)
+}
+
+func sqlMigration003sessionsSql() (*asset, error) {
+	bytes, err := sqlMigration003sessionsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/migration003Sessions.sql", size: 117, mode: os.FileMode(420), modTime: time.Unix(1792411230, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlMigration004stageeventsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x85\xce\x31\x0e\xc2\x30\x0c\x05\xd0\xbd\xa7\xf0\xd8\x4a\x5c\x03\x2e\x00\x3b\x0a\xc9\x4f\x64\x91\x3a\x92\x63\x50\xb9\x3d\x4d\x36\x02\x12\x7f\xb4\x9e\xfd\xed\x15\xce\x40\xe6\x6e\x19\xc4\x91\xa4\x18\x61\xe3\x6a\x95\xaa\xb9\x84\x2b\x9e\x10\xab\xf3\x44\x7b\x38\xd0\x57\x58\x0c\x09\xda\x17\xe5\x91\xf3\xa1\xcb\x0b\xaf\x18\x65\x68\x45\x9f\xec\xd8\x8e\x0f\xcc\xb0\xd9\xc0\x4e\x5a\xd6\x73\xfb\xe6\x7f\x6f\x19\xdd\x2f\xd9\x69\x2c\x0a\x4e\x42\x77\xbc\x68\xe6\xb0\x90\x22\x42\x21\x1e\x95\x7c\x91\xc8\xa9\x4d\xa7\xe5\x0d\xb4\x37\xf8\x26\x22\x01\x00\x00")
+
+func sqlMigration004stageeventsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlMigration004stageeventsSql,
+		"sql/migration004StageEvents.sql",
+	)
+}
+
+func sqlMigration004stageeventsSql() (*asset, error) {
+	bytes, err := sqlMigration004stageeventsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/migration004StageEvents.sql", size: 290, mode: os.FileMode(420), modTime: time.Unix(1792411425, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlMigration005rollupsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xed\x92\xc1\x6e\xc2\x30\x0c\x86\xef\x7d\x0a\x1f\xa9\xc4\x1b\x70\x62\x4c\x9a\x76\x40\x42\xa2\xf7\x29\xa4\x6e\x66\xd1\x26\x95\xeb\x6c\xf0\xf6\xcb\x0a\xac\x6b\x52\x01\x47\x0e\xf8\x98\xcf\xff\x27\x2b\xfa\x35\xa3\x12\x04\x51\xbb\x1a\x81\x2a\xb0\x4e\x00\x0f\xd4\x49\x07\xa5\x12\xf5\xd1\x90\xf5\x82\xb3\x0c\xc2\x50\x09\xc9\x90\x15\x34\xc8\x7d\xce\xfa\xba\x9e\xf7\x9b\x2f\x5e\xef\x51\xee\xd9\xdc\xaa\xa6\xad\xb1\xbb\x63\xb3\x50\x6c\x50\x0a\x6c\xda\x35\xd9\xf3\x66\x38\x3e\xa1\xcb\x2f\x73\x85\xae\xd5\x21\xa1\x2b\xcf\x8c\x76\xac\x9e\xa4\x7f\xea\xe9\xec\x45\x3d\xd0\xed\xdb\x70\x2b\x40\x4a\x87\x5b\xa7\xe8\x70\x6b\x4a\x37\xef\xaf\x89\x7a\x44\x13\xf5\x38\x1b\xab\xff\x51\xf7\x8d\x1c\xb9\x23\x1a\xb9\xe3\xec\xd8\x7d\xa2\x3d\x6e\x99\x1a\xc5\x47\xd8\xe3\x11\x66\x54\xce\xcf\x3d\xc9\x4f\xe1\xca\x31\x92\xb1\x17\x9a\x87\x64\x85\xe1\x73\x75\xa8\x87\x76\xb6\x22\xf3\xfb\x9a\xe5\x8b\x4c\xdf\x28\xed\xa7\xf3\xfc\xac\xec\xb3\xb2\x0f\x53\xd9\x1f\xa9\xdb\xd8\x74\x66\x05\x00\x00")
+
+func sqlMigration005rollupsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlMigration005rollupsSql,
+		"sql/migration005Rollups.sql",
+	)
+}
+
+func sqlMigration005rollupsSql() (*asset, error) {
+	bytes, err := sqlMigration005rollupsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/migration005Rollups.sql", size: 1382, mode: os.FileMode(420), modTime: time.Unix(1792411832, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlMigration006scheduleSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x85\x8e\x3d\x0e\xc2\x30\x0c\x85\xf7\x9e\xc2\x63\x2b\xf5\x18\xcc\x88\x81\x0b\x84\xf4\xa5\x58\xa4\x4e\xe5\x38\x52\x7b\x7b\x42\x11\x20\x95\xa1\x6f\xb4\xbf\xf7\xe3\x15\xce\x40\xe6\x6e\x11\xc4\x81\x24\x19\x61\xe1\x6c\x99\xb2\xbf\x63\x28\x11\x6d\x43\x55\x3c\xd0\x9f\x58\x0c\x23\x74\x33\x49\x89\xb1\xdf\xc8\x4b\xca\x6c\x9c\xe4\x98\x3c\xbb\x09\xfb\x4c\xc3\x62\x3b\xec\x8a\x69\x86\x3a\x2b\xfa\xa3\xeb\xec\xb8\xc3\x4e\xa5\x32\x87\xbd\x1b\x3a\x2b\x4f\x4e\x57\x7a\x60\xa5\x96\x87\xfe\xbb\xb9\x7b\x47\x85\xa4\xe0\x51\x3e\xff\xae\xd6\x05\x28\xc4\x23\x93\x4f\x12\x78\x7c\x5d\x9b\xee\x09\x73\xc2\x4a\x25\x3e\x01\x00\x00")
+
+func sqlMigration006scheduleSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlMigration006scheduleSql,
+		"sql/migration006Schedule.sql",
+	)
+}
+
+func sqlMigration006scheduleSql() (*asset, error) {
+	bytes, err := sqlMigration006scheduleSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/migration006Schedule.sql", size: 318, mode: os.FileMode(420), modTime: time.Unix(1792412303, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlMigration007temperaturecompensationSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xad\xcc\x31\x0e\x83\x30\x10\x44\xd1\x3e\xa7\x98\x23\xa4\xa7\x84\x3a\x45\x94\x0b\x0c\x78\x8c\x2c\x2d\x6b\xb4\x5a\xdf\x1f\x2e\x10\xa5\x49\xf7\x9a\xff\x69\xa9\x40\x72\x35\x61\xeb\x5e\xdb\x0e\x96\x72\xd3\xc6\xe1\x78\x9d\x9c\x69\x6d\x0d\x66\xeb\xfe\xd1\x71\xea\xe6\x08\x21\x44\x83\xf7\x84\x0f\x33\x14\x55\x0e\x4b\x3c\xa7\x07\x7f\x0d\xdf\xaa\x0a\xf9\xa6\xff\xec\x96\x68\x35\xbf\xf5\x17\x33\xd5\xb9\xe6\xde\x00\x00\x00")
+
+func sqlMigration007temperaturecompensationSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlMigration007temperaturecompensationSql,
+		"sql/migration007TemperatureCompensation.sql",
+	)
+}
+
+func sqlMigration007temperaturecompensationSql() (*asset, error) {
+	bytes, err := sqlMigration007temperaturecompensationSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/migration007TemperatureCompensation.sql", size: 222, mode: os.FileMode(420), modTime: time.Unix(1792413644, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlMigration008calibrationSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xa5\x90\x41\x8e\xc2\x30\x0c\x45\xf7\x3d\x85\x97\x54\x62\x01\xb3\x9d\x03\xb0\x42\x1a\xa9\x5c\xc0\x24\x4e\x65\x8d\x9b\x20\xc7\x91\xe0\xf6\xa4\xa5\x48\x14\x18\x09\x69\xbc\x8a\xf2\xdf\xff\xfe\x09\x8a\x91\x82\xe1\x51\x08\x5c\x8a\x81\x7b\x40\xef\xeb\x51\xca\x10\xa1\xeb\xf7\xc9\x93\x6c\x40\x09\x05\x62\x32\x88\x45\x04\x3c\x05\x2c\x62\xb0\xf9\x6e\xf0\x13\xff\xf6\x9f\xfe\xaf\xbf\xfd\xae\x2a\x46\x73\x00\x87\x89\xa1\x33\x67\xcb\xe0\x50\xf8\xa8\x68\x9c\xe2\xaa\x81\x3a\xec\xe1\x65\x38\x1a\xf5\xb5\xc1\x3d\x7b\x3d\x91\x07\x1e\xe8\x99\xf4\xe3\x9e\x25\xf6\xa3\x94\x73\xd1\x05\xba\x68\x3a\xa7\xd1\x70\xa2\x5a\xe4\x91\x1c\xb1\x9b\xda\xed\x5e\x5b\xbd\x09\xe9\x52\x51\xf7\x54\xca\xe8\x6c\x0f\xd8\xc4\x9d\x94\x07\xd4\x0b\xfc\xd2\x05\x56\xec\xd7\xd3\x5b\xda\x5b\x46\x48\x4a\xdc\xc7\xbb\xd6\xd6\x3d\x81\x94\xa2\xa3\x3c\x7f\xfe\x78\xdb\xb4\x57\x93\xff\xe0\x39\x15\x02\x00\x00")
+
+func sqlMigration008calibrationSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlMigration008calibrationSql,
+		"sql/migration008Calibration.sql",
+	)
+}
+
+func sqlMigration008calibrationSql() (*asset, error) {
+	bytes, err := sqlMigration008calibrationSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/migration008Calibration.sql", size: 533, mode: os.FileMode(420), modTime: time.Unix(1792413846, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlMigration009controlstatsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x8d\x92\xc1\x6e\xc2\x30\x10\x44\xef\x7c\xc5\x1e\x89\xc4\x4f\xb4\xe2\xd2\x03\xa2\x82\xde\xab\x6d\x32\x71\x57\x35\xbb\x91\xbd\xd0\xf0\xf7\x35\x41\x15\x22\x20\x25\x73\xdc\x7d\x9e\x19\xcb\xae\x13\xd8\x41\xce\x5f\x11\x24\x2d\xa9\x39\xa1\x97\xec\x99\x6a\x53\x4f\x16\x3f\xb3\xb3\xe7\xe5\x82\x8a\xa4\xa1\x07\x89\x3a\x02\xd2\x70\x52\x8f\x31\xae\x06\x72\x5f\x5b\x87\x11\xe9\xe8\x7d\x84\xad\x8f\x89\x5d\x4c\xa7\x0d\x77\x9b\xfd\x1a\x27\xb9\xa3\x4b\xf7\x38\xc2\x36\xdc\xcf\xc1\xde\xf4\x95\xb5\x99\x75\x11\x78\x67\x65\x95\x27\xc9\xed\x09\x29\x7f\x5b\x99\xdd\xf4\x24\xba\x18\x7a\x14\x0d\x1f\x72\xc0\x64\xb4\x47\x34\x33\x4a\xee\x70\xc9\xe6\x38\x5d\xf2\xa5\x80\x1c\xf0\x6e\xbf\x65\xf5\xbc\xe4\xc0\x75\x49\x0e\x9c\xce\xf4\x83\x33\x2d\xa5\x59\x5d\x1f\xb4\xba\x9a\xb4\x96\x20\x41\xff\x97\x55\x71\x68\x91\xa0\x35\x86\x5f\xd3\x4a\xb8\x4c\x17\xd5\x1f\xdb\x6b\xb1\x37\x5d\x02\x00\x00")
+
+func sqlMigration009controlstatsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlMigration009controlstatsSql,
+		"sql/migration009ControlStats.sql",
+	)
+}
+
+func sqlMigration009controlstatsSql() (*asset, error) {
+	bytes, err := sqlMigration009controlstatsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/migration009ControlStats.sql", size: 605, mode: os.FileMode(420), modTime: time.Unix(1792414563, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlMigration010controlsetpointsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x8d\x90\xb1\x0e\xc2\x30\x0c\x44\xf7\x7e\x85\xc7\x56\xca\xbf\x30\xb4\x3b\x0a\xe9\x35\x58\xa4\x49\xe4\x18\xd4\xfe\x3d\x25\x15\x03\x45\x42\x78\xb4\x9f\xef\xce\x76\x02\xab\x20\xb5\x97\x00\xe2\x89\x62\x52\xc2\xc2\x45\x0b\xb9\x14\x55\x52\x38\x17\x68\x4e\x1c\xb5\xb4\x0d\x6d\xc5\x23\x7d\xd5\x36\x85\x87\xd4\xed\x78\x0f\xc1\x54\xb2\x77\x29\xe3\x40\x2a\x16\x3d\x62\x8a\xfc\x9f\xe0\x60\xc5\x43\x3f\xc9\x2d\x7f\x38\x60\xa7\x07\xa4\x5c\x53\xd2\xdf\x58\x0f\xd5\xc0\xd1\x0f\x3c\xe3\xe0\x6b\x9a\x4a\x64\xe1\xd9\xca\x4a\x37\xac\xd4\xf2\x68\xf6\x8b\x4c\x4d\xdc\xed\x22\x53\x12\xb0\x8f\x6f\xa4\xdb\x8c\x26\x08\xa2\x43\x7d\xe0\xc4\xfe\xd5\x6d\xba\x27\xf4\x66\x34\xb2\x68\x01\x00\x00")
+
+func sqlMigration010controlsetpointsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlMigration010controlsetpointsSql,
+		"sql/migration010ControlSetpoints.sql",
+	)
+}
+
+func sqlMigration010controlsetpointsSql() (*asset, error) {
+	bytes, err := sqlMigration010controlsetpointsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/migration010ControlSetpoints.sql", size: 360, mode: os.FileMode(420), modTime: time.Unix(1792416946, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlRolluphoursSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x9d\x96\x4b\x8f\xd3\x30\x10\x80\xef\xfd\x15\x73\x8c\x21\x62\x0b\x07\x4e\x54\x2b\x76\x91\x2a\x0e\x2b\x21\x75\xef\xc8\x24\xde\x6e\x44\xe2\x54\x89\xc3\x96\x7f\x8f\xdf\xf1\x23\x8e\x53\x7a\x69\x5d\xcf\xcb\xf3\x8d\x3d\xd3\xd0\x91\x0c\x0c\x1a\xca\x7a\xa8\x31\xc3\x3f\x5f\xfb\x69\x28\x9a\xba\x84\x87\xa9\xfa\x4d\x58\x09\x27\xdc\x5d\x5a\x32\x96\x3b\xe0\x9f\x67\x3c\x9c\x09\x7b\x26\xdd\xe5\xa9\xa1\xa5\xb3\xfc\xfa\xe7\xec\x2e\x9f\xf0\x55\x29\x3c\x4e\xc3\x40\xe8\xac\xe1\xac\xa5\x8a\xbb\x6f\x74\x4e\x47\x29\x7a\x3a\x4a\x09\xbe\x32\x1b\x3f\xbe\x7f\x93\x3b\xfc\x5b\x6e\x89\xb5\xdd\xeb\xdf\xc8\xa0\x76\xc5\x2f\xb5\x2f\xff\xc3\x57\xb4\x1b\x49\x4b\x2a\x7e\x4c\x7b\x2e\xb8\x83\xcf\xfb\x12\xc6\xa9\x2b\xf4\x01\x91\xb2\xd3\x35\xb4\xf0\x4e\x89\x94\x94\x77\x54\x78\x67\xd2\x82\xb8\x21\xb1\x5d\xe1\x91\xc0\xdb\x2b\xa1\x7e\x4e\xa0\x19\x81\xf6\x0c\xe8\xd4\xb6\xc0\xc4\xb6\xd6\x03\x42\x6b\x6e\xb9\xc3\xd7\xc2\xcb\x9a\x13\x85\x9f\x3a\x1d\x86\x9f\xbf\xb5\x38\x02\xc9\x0d\x81\xf8\x2c\x9c\x48\x24\x10\x1d\x80\xa4\xb2\xe6\x57\x09\x6c\x70\x27\xc1\x3a\x5e\x14\x5d\xed\x46\x21\x5e\xf3\xa3\x25\x36\x38\x52\x65\xe2\x7a\xd2\xb5\x62\x7c\xe9\x82\x59\xf5\x66\x64\xb6\xf8\xb3\x65\xf7\x32\xf4\x9d\xba\x56\xdc\xed\xc4\xa4\xad\x81\xf0\x32\x84\x03\xdc\x7f\x04\x4c\x6b\x53\x8e\x5f\xe0\xfe\xd3\xee\x3c\xf4\xd3\x05\x7e\xfd\x0d\xeb\x74\xd7\x53\xa8\x7a\xfa\xd2\x36\x15\x73\xee\x26\x82\xba\x87\xe9\xc2\xed\x13\x18\x09\x53\x77\x47\x47\x73\xb0\xbf\xde\x03\xb9\x56\xed\x54\x93\xfa\x43\xfa\x2a\x73\x79\x91\x98\xaa\xc7\x7c\xbf\x22\x45\x70\xcf\xad\x85\xf0\x66\x58\x85\x65\x89\xd2\xf7\x82\x50\xe8\x5a\x64\xf4\x00\x4b\x7e\x3d\x1e\x25\xec\x11\x3f\xc8\x9a\x37\x25\x1f\x1d\x95\x2b\x22\xe9\x53\x7c\xee\xe0\x3f\xee\x69\xcb\x15\xf6\x82\xae\x08\xc0\x6a\x27\x22\x88\xcc\x84\x01\x39\xf6\xa2\x64\xf0\x92\x11\x1c\x78\x05\x2d\x71\xe0\x0f\xdd\x22\x07\x51\xd9\xeb\x1c\x84\xa6\xaf\x80\x96\x1e\xe7\xb0\x06\xc2\xa7\xdb\x9a\x8e\x1e\xa6\xd8\xfb\xca\xb3\xef\xd4\x41\xf0\x44\xb9\x85\x90\x7a\xe7\x92\x95\x10\x29\xdc\x50\x0a\x9b\x9f\xca\x4c\x2d\xe4\xec\xe4\x8b\xc1\x7f\x82\xc3\x6a\x08\x9a\xe5\x32\x91\x44\x3d\x84\xba\x81\x0a\x72\x5a\x6f\x58\x08\xba\x1f\xcf\xd1\x1f\x53\xd8\x6d\xe7\x76\x20\xab\x7e\xe0\xb2\x0d\x5a\x48\x12\xa9\x91\xbb\x81\x64\xae\xf9\x64\x00\x26\xd4\xf3\xdc\x64\x2f\x0b\x71\xa9\xc9\xc5\xcb\x5b\x02\x8e\x96\x54\x02\xc8\x1d\x76\x42\x16\x66\x04\xb2\xaa\xb6\x6b\xc6\x56\x9d\x71\xc9\xe1\xa1\xfb\xa6\x0b\x24\x6c\xb6\x49\x22\x56\xf0\x06\x24\xd9\x3e\x9d\x61\x92\xd2\xcf\x43\x51\x7d\x3f\xa4\xa2\x87\x46\x3f\x83\x09\x2e\x46\x56\x8b\x20\x7f\xd4\x8c\xd8\xd8\x11\x74\x36\x30\x4f\x1a\x0b\xd6\xfd\x91\xd5\x65\x64\xa6\x0d\x8f\x52\x34\xa6\xa4\x39\xcd\xa2\xb7\x90\xca\xcf\x38\x39\x56\x49\x0b\x1b\x68\xe9\xa9\x29\xe2\xa5\xff\x8f\xb2\x9a\x62\x66\xe5\xad\x18\xfa\x07\xbb\x83\xd4\x96\xe6\x0c\x00\x00")
+
+func sqlRolluphoursSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlRolluphoursSql,
+		"sql/rollupHours.sql",
+	)
+}
+
+func sqlRolluphoursSql() (*asset, error) {
+	bytes, err := sqlRolluphoursSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/rollupHours.sql", size: 3302, mode: os.FileMode(420), modTime: time.Unix(1792416811, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlRollupminutesSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x9d\x56\x4d\x6f\x9c\x30\x10\xbd\xf3\x2b\xe6\x88\x53\xda\x6c\x7a\xe8\xa9\x55\xd5\x0f\x69\xd5\x43\xa4\x4a\xe4\x5e\xb9\x30\xd9\xa2\x82\x41\x60\x92\xed\xbf\xef\xf8\x03\x63\x1b\x08\xd9\xdd\xcb\xda\x33\xef\xcd\xd8\xf3\x06\xdb\x95\x18\xb0\x97\x50\x09\xd9\x42\xc9\x25\xff\xd5\x54\x62\x94\x98\x56\x65\x06\x5f\xc7\xe2\x2f\xca\x0c\x72\xde\x74\x35\x0e\x59\x02\xf4\x7b\xe0\xfd\x09\xe5\x03\x36\xdd\x7d\x25\x32\x6f\xfa\xe5\xe9\xe4\x4f\xef\xf9\xd9\x10\xbe\x8d\x7d\x8f\x62\x66\x78\x73\x4d\xf1\xfd\x13\x27\x3f\x6a\x68\x7e\xd4\x08\x9a\x4d\x8e\x9f\x3f\xbe\x6b\x0f\xfd\x6b\x97\x9a\x3b\x5f\xfb\x8c\xbd\xf1\xaa\x91\xf1\x6b\x1b\x3f\xb3\x64\xc0\x1a\x0b\xda\x28\xed\x2b\xcd\x25\x76\xf0\x16\xee\x18\xdc\xc2\x87\x43\x06\x45\x3b\x0a\x99\xde\x30\x13\x87\x2a\x90\xce\xfb\x60\x19\xf0\xa7\x53\x68\x68\xf8\x39\x30\x38\x9a\xb7\x17\xcb\x0b\x2d\x8a\x18\x58\x1c\x33\x3f\x5a\x82\x1e\x28\x9c\x1a\x38\x37\xed\xd3\xfa\xcd\x48\x01\xf4\x68\x46\xa8\x9d\x4e\x18\x3b\xd6\x28\x3d\x4e\x1e\xfb\xb6\xd1\x02\xc3\xf3\x1f\xec\x91\xea\x00\x9f\xe0\xf3\x1d\x70\x51\x82\x2e\xc7\x47\x9a\xbe\x4f\x4e\x7d\x3b\x76\xf0\xfb\xdf\x5a\x9d\x92\x56\x50\xa5\xc4\x63\x5d\x15\xd2\xeb\x0f\x06\x65\x0b\x63\x47\xb1\x11\x06\x94\x46\x3f\xd3\x31\x94\x62\x1a\xbd\x01\x3c\x17\xf5\x58\x62\xf9\x6e\xbb\x9d\x08\xaf\xb6\x52\xb4\x9c\xfc\x05\xa6\x51\xaf\xb9\x08\x81\x9d\x29\xfd\x2c\x61\x1d\x91\x85\x59\x18\x8b\x53\x53\xab\x50\xea\xb5\xbc\xca\x73\xe3\x3e\x00\x38\x30\xda\xc8\x4b\xd9\x0c\x7e\xb1\x55\x22\x32\x9d\x53\xfd\x6e\x29\x13\x1f\x50\x09\x21\xa2\x45\x54\x03\x88\x56\x82\x18\xeb\x1a\xa4\x72\x4f\xe5\xc3\x9a\x08\x07\x40\x52\x8b\x16\xe0\xd8\x1b\x2b\x58\x84\x89\x17\xe4\xc5\x5b\x14\x83\xbe\x16\xa5\x03\xb5\xce\x9a\x0e\xf4\xb1\xad\xea\x40\x9f\xd8\x8e\x0e\x8a\x19\x12\xd8\xda\x01\x11\xf7\x40\x7c\x7c\xb8\xd0\xa1\x63\x35\xfb\x0b\x47\x8f\xd7\x07\xe1\x81\x14\x34\x42\xe4\xda\xef\x84\x05\xe1\x82\x56\x88\xb8\x57\xf7\xc2\x5e\x9c\xfd\x66\x08\x8f\xe4\xb8\x1b\xa2\x03\x7b\x5d\x91\x8d\x7e\x88\xb9\x11\x85\x79\xc7\x7f\xdc\x08\xf6\x4e\x98\x57\x7f\xdc\x92\xdd\xdd\x1e\x9e\xc8\xfa\x2a\x09\xb4\x35\x96\x7d\x49\x27\xdc\x05\x4a\x1a\xca\xd5\x02\x6e\xd0\xf7\x75\xd3\x17\x65\x2c\x97\xb9\x3d\x83\xba\x6d\x88\x63\x91\x06\xc0\xfc\x0b\x37\xd6\x62\xba\x86\x1d\xd5\x18\x56\xa3\x7a\x57\xb6\xa7\x87\xb9\xc0\x03\x41\xac\x69\x5f\x11\x07\xbc\x40\x12\xcb\xb9\x5a\x93\x2d\xfe\xbe\x28\xe6\x89\x12\xab\x62\x1f\x2e\x61\x05\x37\x74\x99\xb0\x16\xc2\xc2\xe7\xce\x42\x1b\xf7\x0c\x9a\x03\x58\xd3\x7a\xf4\xf0\xd9\xe4\x6b\x64\x9f\x51\xa1\x4a\x93\xf1\x15\x3a\xcd\xd0\x4b\x94\x9a\x58\xd7\x6b\xb5\x19\xe1\x15\x6a\xd9\x07\xe3\x42\x2f\x6b\x5f\x54\x75\x4b\x33\x87\x77\x30\xf6\x1f\xb5\x6c\xd5\x8c\x6c\x0b\x00\x00")
+
+func sqlRollupminutesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlRollupminutesSql,
+		"sql/rollupMinutes.sql",
+	)
+}
+
+func sqlRollupminutesSql() (*asset, error) {
+	bytes, err := sqlRollupminutesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/rollupMinutes.sql", size: 2924, mode: os.FileMode(420), modTime: time.Unix(1792416811, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSchemaversiontableexistsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x0b\x76\xf5\x71\x75\x0e\x51\xc8\x4b\xcc\x4d\x55\x70\x0b\xf2\xf7\x55\x28\x2e\xcc\xc9\x2c\x49\x8d\xcf\x4d\x2c\x2e\x49\x2d\x52\x08\xf7\x70\x0d\x72\x55\x28\xa9\x2c\x48\xb5\x55\x2f\x49\x4c\xca\x49\x55\x57\x70\xf4\x73\x01\x2b\xb7\x55\x2f\x4e\xce\x48\xcd\x4d\x8c\x2f\x4b\x2d\x2a\xce\xcc\xcf\x53\x07\x00\xf0\xe7\x15\xd6\x4b\x00\x00\x00")
+
+func sqlSchemaversiontableexistsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSchemaversiontableexistsSql,
+		"sql/schemaVersionTableExists.sql",
+	)
+}
+
+func sqlSchemaversiontableexistsSql() (*asset, error) {
+	bytes, err := sqlSchemaversiontableexistsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/schemaVersionTableExists.sql", size: 75, mode: os.FileMode(420), modTime: time.Unix(1792412797, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelectcalibrationpointsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x1d\x8c\xcb\x09\x80\x30\x10\x44\x5b\x99\x02\x6c\x41\x3c\x7a\x15\x4c\x03\xf9\x8c\xb8\x90\xb8\xb2\x31\x88\xdd\x1b\x72\x18\x78\x8f\x07\x53\x99\x19\x1f\x38\x29\x9c\xb0\x19\x6b\x6d\xd6\xc9\xb1\xdc\x34\xff\x0c\xd9\xd7\x3e\x6d\x16\x89\xc3\xb4\x20\xfa\x2c\xa1\x47\xd1\x0b\xef\x49\x23\x24\x61\xc6\x02\xb5\x44\x43\xf8\xc6\xdd\x0f\x15\x33\x2e\x8b\x5a\x00\x00\x00")
+
+func sqlSelectcalibrationpointsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSelectcalibrationpointsSql,
+		"sql/selectCalibrationPoints.sql",
+	)
+}
+
+func sqlSelectcalibrationpointsSql() (*asset, error) {
+	bytes, err := sqlSelectcalibrationpointsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/selectCalibrationPoints.sql", size: 90, mode: os.FileMode(420), modTime: time.Unix(1792413846, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelectconfigSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x75\x53\xcd\x4e\x84\x30\x10\xbe\xef\x53\xf0\x00\x1e\xdc\xde\x8d\x51\x57\x3c\xe1\x6e\x84\x78\xf0\xd6\x2d\x03\x34\x29\x2d\x99\x96\xac\x8f\xef\xf0\xdb\x16\x56\x2e\xe5\xfb\xe1\x9b\x49\x67\xb0\xa0\x40\xb8\x43\x42\x8f\x2c\x1f\xc6\x33\x05\x6c\x41\x3b\xc0\x1c\xb4\x35\x38\x91\x17\x04\x0b\x5a\xc0\x0f\xa0\x89\x99\x37\xae\xe4\x15\xb9\x93\x46\xc7\xc2\x59\x17\xb2\x85\xcd\xf7\xef\x9a\x5f\x15\x94\x31\x39\xf8\x4c\xef\x26\xb2\x80\xb6\x03\xca\xeb\x11\x72\xc1\x15\xcc\x2c\xc7\x1a\x5c\xa0\xcd\x09\xb2\xcc\x95\xe9\x66\xf4\xd9\x71\xdf\x1f\x81\x5d\x6b\x05\x88\x63\xd1\x50\xd5\xc6\xa8\xd2\x53\x99\x0c\xf4\x8c\xff\xae\x80\xed\xcd\x2c\x34\xb3\xd5\x9c\x72\xbd\x4d\x1e\xa8\xd5\x3c\x82\xc0\xcc\xf6\x66\x16\x9a\x7d\xf2\xa5\x6f\xbb\x6d\xf4\xc8\xad\xf6\x09\x85\x7e\x76\xc7\xcf\x22\xbf\xcf\xa7\x7b\x22\xe5\x9b\xab\xde\xdf\x22\x89\x1b\x42\xea\x61\x58\x76\xbd\xf8\xc9\x14\x73\xb9\xe3\xf5\xfc\x7a\xfe\x98\xce\x57\x84\x9b\xd4\x35\x29\xe8\x86\x29\x2f\x53\x73\xa2\xf1\x90\xb2\x82\xc1\xa6\x52\xb9\x65\x6b\x48\x59\x4a\x84\xf4\xc9\xfe\xe3\x7f\x29\xed\xd8\x77\x94\xc1\x83\x3a\xc1\x46\xec\x76\x89\xe4\x2f\xa8\x00\xc7\x8d\xbc\x23\x9e\x50\x56\xf3\x8e\xe6\x75\x66\x4a\x50\x8f\x11\x3a\x46\x88\x1d\x2a\x34\x6d\x22\x8c\xae\x64\x9d\xdc\x1a\xca\xa5\x3f\x2c\x79\x4a\x9e\xff\x00\xe4\xd7\x00\x1b\x73\x03\x00\x00")
+
+func sqlSelectconfigSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSelectconfigSql,
+		"sql/selectConfig.sql",
+	)
+}
+
+func sqlSelectconfigSql() (*asset, error) {
+	bytes, err := sqlSelectconfigSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/selectConfig.sql", size: 883, mode: os.FileMode(420), modTime: time.Unix(1792413846, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelectcontrolsetpointsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x4d\xcc\xb1\x0d\x80\x20\x10\x05\xd0\x55\xfe\x00\xac\x60\x1c\xc1\x02\x7a\xa3\xf0\x55\x12\xe4\xc8\x71\xd1\xb8\xbd\x96\x76\xaf\x7a\x9d\x85\xd1\xe0\xa3\x34\x3a\x78\x63\x73\x08\x8b\xee\x34\x87\xe9\xa2\xf6\x43\xe4\xa3\xa7\x59\xc9\x75\x0f\xf9\x24\x36\x95\x13\x51\xaa\xa9\x94\xb9\xd3\x9a\xe4\x6a\x1d\xf7\x41\x25\x72\xc2\x80\x11\xa2\x89\x8a\xf5\xf9\xcf\x2f\xd0\xa0\x4f\xbd\x6c\x00\x00\x00")
+
+func sqlSelectcontrolsetpointsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSelectcontrolsetpointsSql,
+		"sql/selectControlSetpoints.sql",
+	)
+}
+
+func sqlSelectcontrolsetpointsSql() (*asset, error) {
+	bytes, err := sqlSelectcontrolsetpointsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/selectControlSetpoints.sql", size: 108, mode: os.FileMode(420), modTime: time.Unix(1792416946, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelectcontrolstatsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x4d\xca\x41\x0a\xc2\x30\x10\x85\xe1\xab\xbc\x03\xe4\x0a\x22\x4a\x37\x2e\x8a\xd2\xba\x97\xd8\x8c\xed\x40\x9a\x29\x93\xb1\xd1\xdb\x1b\xa5\x0b\x77\xef\x7f\x7c\x99\x22\x0d\x86\x7e\x90\x85\x1c\x9a\xa7\x7a\x63\x49\x0e\x5d\xdb\x37\xb4\xf2\x56\xad\x7f\xfd\xd5\x29\x1d\x7d\x0a\x0e\x3d\xd9\x22\x9c\x2c\x3b\x9c\x57\xd2\x3c\x89\xd8\xef\xb5\xc8\x69\xbc\xf2\x4c\x5b\x51\xc5\x1d\x7d\x89\x8f\x15\x1f\xea\xf2\x23\x5d\xa4\x90\xe2\xa1\x32\x63\x90\x64\x2a\xf1\x96\xcd\x5b\x46\x99\x48\x09\x1c\xb0\xc3\x1e\xa2\xa1\xaa\xfb\x1b\x2a\x85\xc3\x07\x82\xdd\xdf\x73\xaf\x00\x00\x00")
+
+func sqlSelectcontrolstatsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSelectcontrolstatsSql,
+		"sql/selectControlStats.sql",
+	)
+}
+
+func sqlSelectcontrolstatsSql() (*asset, error) {
+	bytes, err := sqlSelectcontrolstatsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/selectControlStats.sql", size: 175, mode: os.FileMode(420), modTime: time.Unix(1792414563, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelectdatasgSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x2b\x4e\xcd\x49\x4d\x2e\x51\x28\xca\x2f\xcf\x4c\xd1\x51\x08\x76\x47\x60\xe7\xd2\xa2\xa2\xd4\xbc\x92\x90\xd4\xdc\x02\x85\xb4\xa2\xfc\x5c\x85\x94\xc4\x92\x44\x85\xf2\x8c\xd4\xa2\x54\x85\xcc\x14\x05\x5b\x05\x7b\x85\xc4\xbc\x14\xa0\x52\x85\xcc\x62\x85\xbc\xfc\x12\x85\xbc\xd2\x9c\x1c\x00\x4a\x5c\x8a\x78\x4f\x00\x00\x00")
+
+func sqlSelectdatasgSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSelectdatasgSql,
+		"sql/selectDataSG.sql",
+	)
+}
+
+func sqlSelectdatasgSql() (*asset, error) {
+	bytes, err := sqlSelectdatasgSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/selectDataSG.sql", size: 79, mode: os.FileMode(420), modTime: time.Unix(1792416730, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelecthoursgSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x25\x8b\xcd\x0e\x40\x30\x10\x06\x5f\xe5\x7b\x00\xaf\x20\x82\x83\x93\x13\x77\x69\x74\xd1\x68\xb7\xb2\xb6\x78\x7c\x7f\xa7\xc9\x4c\x32\x3b\x79\x1a\x15\x55\x1a\x57\xd2\x0c\x5d\xd3\x3a\x7e\x51\x1e\xf3\x67\xe6\xca\x50\x27\x11\x62\xed\x29\x6c\x4f\xc6\x24\x31\xc0\x1a\x35\xc3\x12\x93\xe0\x5c\x48\x08\xce\x22\x47\x01\xc3\xf6\x9f\xe1\x76\x70\x54\x70\xf2\xfe\x06\x79\x15\x10\x09\x64\x00\x00\x00")
+
+func sqlSelecthoursgSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSelecthoursgSql,
+		"sql/selectHourSG.sql",
+	)
+}
+
+func sqlSelecthoursgSql() (*asset, error) {
+	bytes, err := sqlSelecthoursgSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/selectHourSG.sql", size: 100, mode: os.FileMode(420), modTime: time.Unix(1792416730, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelectlatestconfigSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x75\x93\x3f\x6f\x83\x30\x10\xc5\xf7\x7c\x0a\x8f\x89\xd4\xa1\xf1\xde\xa1\x6d\x9a\x4e\x34\x51\x41\x1d\xba\x39\xf8\x00\x4b\xc6\x46\x67\xa3\xe4\xe3\xf7\xf8\x13\xb0\x21\x65\x31\xf7\xde\x8f\x67\xcb\x77\x38\xd0\x90\xfb\x0d\xa3\x47\xc9\xa7\x7e\x3d\x02\xd6\x60\x3c\x60\x0a\xc6\x59\x1c\xc4\x33\x82\x03\x93\xc3\x2f\xa0\x8d\x95\x77\xa1\xd5\x05\x85\x57\xd6\xc4\xc6\xc9\x64\xaa\x86\xc5\xf7\x1f\x46\x5c\x34\xc8\x58\xec\x38\xdb\xfa\x41\xcc\xa0\x6e\x80\xf2\x5a\x84\x34\x17\x1a\x46\x55\x60\x09\x3e\xf0\xc6\x04\x25\x53\x6d\x9b\xb1\xfa\x6a\xc4\x7c\x3e\x2a\x56\x47\xcb\x20\xdf\x67\x15\xed\x5a\x59\x2d\x67\x29\x51\x81\x9f\x88\xdb\x54\xf0\x35\xcc\x43\x98\x4f\xf0\x51\x98\x65\x72\x27\x4d\x70\x5f\x04\x30\x5f\xc3\x3c\x84\xe7\xe4\x73\x5b\x37\xcb\xe8\x5e\x9b\xf0\xa1\x0a\x79\xfe\x80\xe7\x11\x3f\xe7\xd3\x3d\x91\xf3\x23\x74\x3b\xdf\x22\x99\x0b\x41\x99\xae\x59\x6e\xba\xf8\x01\x8a\xb5\xd4\x8b\x72\x7c\x3d\x7d\x0e\xeb\x1b\xc2\x55\x99\x92\x1c\xf4\x5d\x97\xef\x5d\xf3\x79\x35\x97\x94\x15\x34\xf6\xa8\xb4\xbf\x4f\x0d\x39\xf7\x2d\x42\xf9\xe0\xfe\xe1\x5f\xa5\xeb\xcf\x1d\x65\x88\x60\x9f\x60\x22\x56\xb3\x44\xf6\x37\x14\x80\xfd\x44\x3e\x30\x0f\xa8\x8a\x71\x46\xd3\x32\xb1\x12\xf4\x73\x54\xed\xa3\x8a\x6f\x0a\xb4\x35\xcb\xad\x29\x54\xc9\xae\x15\xe5\xd2\x1f\xc6\x5e\xd8\xd6\xf5\xbf\x1c\xab\xc5\x6d\xab\xe4\x8e\x05\xd8\xee\x0f\x31\x7f\x7b\xb3\x8e\x03\x00\x00")
 
 func sqlSelectlatestconfigSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -231,27 +828,207 @@ func sqlSelectlatestconfigSql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "sql/selectLatestConfig.sql", size: 60, mode: os.FileMode(420), modTime: time.Unix(1457422864, 0)}
+	info := bindataFileInfo{name: "sql/selectLatestConfig.sql", size: 910, mode: os.FileMode(420), modTime: time.Unix(1792413846, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelectminutesgSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x25\x8b\x3b\x0e\x80\x20\x10\x05\xaf\xf2\x0e\xc0\x15\x8c\x51\x0b\x2b\x2b\xed\x0d\x91\x55\x89\xb0\x18\x58\xd4\xe3\xfb\xab\x26\x33\xc9\x24\x72\x34\x09\xea\x3c\x6d\x24\x0a\x7d\xdb\x59\x7e\x51\x1d\xcb\x67\xfa\x52\x68\x72\x8c\xc4\x32\x90\xdf\x9f\x8c\x39\x06\x0f\xa3\x45\x8f\xde\x72\x16\xc2\xb9\x52\x24\x58\x83\x02\x25\x34\x9b\x7f\x87\x4d\xe0\x20\xe0\xec\xdc\x0d\x34\xce\xed\xd9\x66\x00\x00\x00")
+
+func sqlSelectminutesgSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSelectminutesgSql,
+		"sql/selectMinuteSG.sql",
+	)
+}
+
+func sqlSelectminutesgSql() (*asset, error) {
+	bytes, err := sqlSelectminutesgSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/selectMinuteSG.sql", size: 102, mode: os.FileMode(420), modTime: time.Unix(1792416730, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelectscheduleSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x2b\x4e\xcd\x49\x4d\x2e\x51\xf0\x4b\xcc\x4d\xd5\x51\x08\x49\xcd\x2d\x48\x2d\x4a\x2c\x29\x2d\x02\x72\x5c\x4a\x81\xac\xcc\xfc\x3c\x85\xb4\xa2\xfc\x5c\x85\xe2\xe4\x8c\xd4\x94\xd2\x9c\x54\x85\xf2\x8c\xd4\xa2\x54\x85\xcc\x14\x05\x5b\x05\x7b\x85\xfc\xa2\x94\xd4\x22\x85\xa4\x4a\x85\x80\xfc\xe2\x4c\x90\x62\x00\x56\x4b\x6c\xc1\x4f\x00\x00\x00")
+
+func sqlSelectscheduleSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSelectscheduleSql,
+		"sql/selectSchedule.sql",
+	)
+}
+
+func sqlSelectscheduleSql() (*asset, error) {
+	bytes, err := sqlSelectscheduleSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/selectSchedule.sql", size: 79, mode: os.FileMode(420), modTime: time.Unix(1792412303, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelectschemaversionSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x2b\x4e\xcd\x49\x4d\x2e\x51\x48\xce\x4f\xcc\x49\x2d\x4e\x4e\xd5\xc8\x4d\xac\xd0\x28\x4b\x2d\x2a\xce\xcc\xcf\xd3\xd4\x51\x30\xd0\x54\x48\x2b\xca\xcf\x55\x28\x4e\xce\x48\xcd\x4d\x8c\x87\x4a\x00\x00\x2e\x6d\x3d\xeb\x34\x00\x00\x00")
+
+func sqlSelectschemaversionSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSelectschemaversionSql,
+		"sql/selectSchemaVersion.sql",
+	)
+}
+
+func sqlSelectschemaversionSql() (*asset, error) {
+	bytes, err := sqlSelectschemaversionSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/selectSchemaVersion.sql", size: 52, mode: os.FileMode(420), modTime: time.Unix(1792411123, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelectsessiondatapointsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x95\x91\xcb\x0a\xc2\x30\x10\x45\xf7\x7e\xc5\x5d\x5a\xcd\xa2\x22\x74\x27\xe2\x03\xc4\x9d\xd0\xee\x25\xb6\xa3\x16\xdb\x44\xd2\xc4\xe2\xdf\x9b\xc6\x94\x6a\x45\xc4\x59\x84\x79\x64\xe6\x9e\x61\x2a\x2a\x28\xd5\xc8\x33\x86\x58\xd3\x95\x21\xe1\xea\x44\x3a\xa1\xd2\xfa\x2b\xa3\x14\x09\x1f\xc4\x1b\x86\xdd\x76\x6d\x1f\x59\x93\xc2\x51\xc9\x12\xc3\x01\xac\x55\xdd\x8c\xe1\xd2\xa4\x17\xd2\x18\x63\x12\x60\x84\x69\x14\x86\xe0\xd5\xc7\xe8\xc5\xed\xd4\xa4\xbf\x68\xf9\xea\xab\xba\xd3\x69\x2c\xde\xf8\xaa\xc7\xf1\x51\x07\xd6\x26\x1a\xdf\x75\x39\xd0\x8c\x6b\xbe\x3f\x4b\xa3\x50\x9f\x49\x91\x65\xc5\x0c\xf3\x89\xfb\x60\x44\x2e\x05\x78\x51\xfc\x58\x26\x0a\x7b\x2b\xf4\xa1\xd9\x93\xae\xe5\xea\x80\x7a\x1c\x65\x2e\x8c\xa6\x7f\x48\xfe\x3a\xcd\xbb\x5a\x4f\x26\x80\x54\x99\x3d\xdf\xe1\xee\x86\x3e\x00\x79\x4c\x09\xf5\xff\x01\x00\x00")
+
+func sqlSelectsessiondatapointsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSelectsessiondatapointsSql,
+		"sql/selectSessionDataPoints.sql",
+	)
+}
+
+func sqlSelectsessiondatapointsSql() (*asset, error) {
+	bytes, err := sqlSelectsessiondatapointsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/selectSessionDataPoints.sql", size: 511, mode: os.FileMode(420), modTime: time.Unix(1792411832, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelectsessionsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xb5\x90\x3f\x4f\xc3\x30\x10\xc5\xf7\x7c\x8a\x37\xc6\x80\xaa\x22\xa4\x6e\x0c\x74\xc9\x06\x48\x65\x47\xc6\xb9\x26\xa7\xfa\x4f\xe4\x5c\x08\x7c\x7b\x9c\xb8\x6d\x40\x42\x74\xc2\x83\x75\x3e\xff\xde\xbb\xa7\x1b\x59\x5a\x74\x81\xbd\xf4\x25\xd7\x37\xd8\x09\x75\xe9\xae\x14\x74\x8f\xb2\x40\x3a\x3d\x59\x32\x82\xe9\xb7\xdc\x0e\xe6\x40\x82\x6b\xdc\x2a\x5c\xe1\x6e\xb3\x5e\x4f\xf0\xc3\x7b\x83\x7d\x0c\x0e\xb5\x16\xfd\xda\x86\x21\xce\xc2\xc1\x73\xf0\xd0\xd6\x5e\xb0\xd9\xfc\x62\xe2\xd8\x0f\x42\x7f\xdb\x9c\xb2\x2e\xb2\x42\x15\xf9\x7f\x46\xcd\x2a\x51\xc7\xea\x51\x3b\x3a\xd5\x3b\xd1\xcd\xf9\xb1\x8d\x34\xb2\x6f\x52\x2f\xca\x0b\x2f\xd0\x33\x8b\x69\xbf\x37\x9e\xaa\x5c\x95\xc7\x04\x4e\x7f\x94\xdd\x6a\xca\xa0\x72\x80\xbc\x45\x74\x18\x5b\x8a\x84\x2e\x4d\xc7\xfd\x1c\x42\xfd\x54\x26\x55\x75\x49\x02\xed\xeb\x0c\x72\x0f\x1f\x04\x7e\xb0\x16\x21\xd6\x14\xf1\xf6\x89\x3c\x18\x96\x1d\x4b\x5a\xe2\x7f\xf9\xd7\xd4\x9b\xf3\x90\x62\xb6\x34\xc1\xef\xb9\x81\x59\xd8\xd9\x6e\x22\xbf\x00\x8b\x56\xec\xd1\x4c\x02\x00\x00")
+
+func sqlSelectsessionsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSelectsessionsSql,
+		"sql/selectSessions.sql",
+	)
+}
+
+func sqlSelectsessionsSql() (*asset, error) {
+	bytes, err := sqlSelectsessionsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/selectSessions.sql", size: 588, mode: os.FileMode(420), modTime: time.Unix(1792411832, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlSelectstageeventsSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x2b\x4e\xcd\x49\x4d\x2e\x51\xc8\x4c\xd1\x51\x08\xc9\xcc\x4d\xd5\x51\x70\x2d\x4b\xcd\x2b\xd1\x51\x70\x2b\xca\xcf\x0d\x2e\x49\x4c\x07\x8a\x84\xe4\x83\x19\x0a\x69\x40\x21\x85\x62\x10\x33\x3e\x15\xa4\xa8\x58\xa1\x3c\x23\xb5\x28\x15\xa8\x57\xc1\x56\xc1\x5e\x21\xbf\x28\x25\xb5\x48\x21\xa9\x12\x6c\x0e\x00\x8e\x30\x04\xcb\x57\x00\x00\x00")
+
+func sqlSelectstageeventsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlSelectstageeventsSql,
+		"sql/selectStageEvents.sql",
+	)
+}
+
+func sqlSelectstageeventsSql() (*asset, error) {
+	bytes, err := sqlSelectstageeventsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/selectStageEvents.sql", size: 87, mode: os.FileMode(420), modTime: time.Unix(1792411425, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlUpdateconfigSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x75\x93\xc1\x6e\x83\x30\x0c\x86\xcf\xf4\x29\xf2\x00\x3b\xac\xb9\x4f\xd3\xb6\xae\x3b\x75\xad\x46\xb5\xc3\x6e\x2e\x71\x21\x52\x48\x50\x08\x6a\x1f\x7f\x01\x4a\x09\x69\xec\x1b\xf9\xe4\x2f\xbf\x45\xdc\x35\x02\x1c\xb2\xc2\xe8\xb3\x2c\x59\x8b\x6e\x95\x6d\xd1\xd6\xa8\x1d\xda\x1c\x75\x6b\x2c\xeb\xeb\x85\xbd\x3e\xad\xb2\x83\xc5\x16\x75\x81\x7f\x68\x0d\xbb\xd5\x92\x7c\x80\x92\x27\x0b\x4e\x1a\x1d\x91\xbd\x3e\xca\x1a\x53\xb6\x4f\x0d\x27\x85\x22\x41\xfa\x0e\xd3\xb9\x80\x1c\xb1\x6e\xd0\xfb\x3b\x8b\x79\x01\x0a\x03\x02\xb6\x44\x17\xf0\xd9\x26\x45\xae\x4c\x83\x2c\xa8\x91\x7c\x37\x10\x8e\xb2\x24\xe1\x28\x8b\x04\xc5\xfa\x58\xf9\x80\x95\x51\x82\xc5\x64\x27\x75\xc2\x36\x10\xb8\xa6\x09\x27\x6d\x9c\xb4\xf1\xb4\x6d\x0b\x9a\xc8\xd6\x93\xb4\x6d\x20\x94\x8d\x93\x36\x4e\xda\x88\x6c\x87\xae\x6e\xe2\x70\x01\x89\x74\x21\x59\xea\x66\xc2\x49\x1b\x27\x6d\x3c\x69\xf3\x7f\xdb\x77\xfc\x82\xea\x30\x41\xe0\x4a\x11\xa9\xfb\x97\xda\x8e\x8f\x2d\xea\x49\x91\xdc\x41\x89\x2c\xcb\x96\xa6\xfd\x17\x7b\xa8\x91\xbc\x5b\xbc\x48\x5d\xfa\x36\xeb\xfa\x5d\x08\x66\x91\xae\xa8\xa6\xa3\x38\x57\xb0\x04\x5b\xa9\xfc\x22\xcf\x68\x4a\x75\x3b\x9f\x9a\x36\x2d\xd5\xf3\x26\xda\x61\xfa\x7b\xc3\x7c\x11\x2c\x6e\x27\x97\x27\x5c\xc9\x3b\xff\xc1\x33\xda\x61\xc5\x53\x74\x63\xe5\xd9\x3d\x7a\xf3\x72\x67\x04\xaa\x67\x92\xac\x49\xc2\x23\xb2\xca\x2e\x95\x0f\xc0\xa4\xe8\xbf\xfe\x01\xa3\x6d\xf7\x20\x05\x05\x00\x00")
+
+func sqlUpdateconfigSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlUpdateconfigSql,
+		"sql/updateConfig.sql",
+	)
+}
+
+func sqlUpdateconfigSql() (*asset, error) {
+	bytes, err := sqlUpdateconfigSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/updateConfig.sql", size: 1285, mode: os.FileMode(420), modTime: time.Unix(1792413846, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlUpdatedatasgSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x2b\x2d\x48\x49\x2c\x49\x55\x00\x12\x89\x0a\xc5\xa9\x25\x0a\xc1\xee\x0a\xb6\x0a\xf6\x46\x0a\xe5\x19\xa9\x45\xa9\x0a\x45\xf9\xe5\x99\x29\x20\x01\x13\x85\xc4\xbc\x14\x05\x08\xdb\x14\x00\x61\xe6\x83\xbc\x34\x00\x00\x00")
+
+func sqlUpdatedatasgSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlUpdatedatasgSql,
+		"sql/updateDataSG.sql",
+	)
+}
+
+func sqlUpdatedatasgSql() (*asset, error) {
+	bytes, err := sqlUpdatedatasgSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/updateDataSG.sql", size: 52, mode: os.FileMode(420), modTime: time.Unix(1792413880, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _sqlUpdatehoursgSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x2b\x2d\x48\x49\x2c\x49\x55\x00\x12\x89\xf1\x19\xf9\xa5\x45\x0a\xc5\xa9\x25\x0a\xc1\xee\xbe\x99\x79\x0a\xb6\x0a\xf6\x86\x3a\x40\xb6\x63\x59\x3a\x88\x6d\x04\x62\xfb\x26\x56\x80\xd8\xc6\x0a\xe5\x19\xa9\x45\xa9\x0a\x4e\xa5\xc9\xd9\x40\xf5\x40\x11\x13\x85\xc4\xbc\x14\x85\xcc\x14\x10\xdb\x14\x00\x9e\xb5\xb1\xfa\x55\x00\x00\x00")
+
+func sqlUpdatehoursgSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_sqlUpdatehoursgSql,
+		"sql/updateHourSG.sql",
+	)
+}
+
+func sqlUpdatehoursgSql() (*asset, error) {
+	bytes, err := sqlUpdatehoursgSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "sql/updateHourSG.sql", size: 85, mode: os.FileMode(420), modTime: time.Unix(1792413880, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _sqlUpdatelastconfigSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x74\x93\xbd\x4e\xc3\x30\x14\x85\xe7\xf4\x29\x3c\xb6\x12\x0b\xdd\x11\x12\x88\x32\x41\x2b\xa5\x62\x60\xbb\xb5\x4f\x13\x4b\xfe\x89\x1c\x47\xed\xe3\x63\xb7\x85\x3a\xc6\xbe\x5b\xf2\xe9\x7c\x39\x57\xb1\xa7\x41\x90\x07\xe3\xd6\x1c\x65\xc7\x46\xf8\x45\xb3\x81\xd3\x30\x1e\xae\x85\x19\xad\x63\x71\x9e\xd8\xf3\xc3\xa2\xd9\x39\x8c\x30\x1c\xdf\x70\x96\xdd\x66\x4e\x5e\x49\xc9\x83\x23\x2f\xad\xc9\xc8\xd6\xec\xa5\x46\xc9\xf6\x66\xe8\xa0\x20\x0a\x24\x26\xec\xe4\x13\xb2\x87\x1e\x10\xfc\x93\x43\xcb\x49\x21\x21\xe4\x3a\xf8\x84\xdf\x6d\x52\xb4\xca\x0e\x60\xc9\x5c\xc9\xe7\x40\xe9\x2a\x73\x92\xae\x32\x6b\xc0\x1f\xf7\x7d\x28\xd8\x5b\x25\x58\x4e\x3e\xa4\x29\xd8\x2e\x84\xce\x65\xb2\xae\xda\xd6\x55\xdb\xba\x6c\xdb\x90\xa9\x74\x8b\xa4\x6c\xbb\x90\x9a\xad\xd2\x2d\x92\xaa\xad\xd2\x6d\x37\xe9\x21\x2f\x97\x90\x4c\x97\x92\xb9\xee\x4e\xf2\x72\x09\xa9\xda\xf2\x72\x7f\x7f\x3b\x24\xbe\x48\x4d\x28\x10\x3a\xd7\x88\x34\xf1\xa4\x8e\xd7\xc3\x96\x65\x4a\xa4\xf5\xd4\x81\x35\xcd\xdc\xb4\x7d\x67\xff\xe6\x4a\x5e\x1c\x4e\xd2\x74\x21\xe6\x7c\xbc\x0b\xc9\x2e\xd2\xf3\xfe\xf7\xd5\x3d\xb3\x68\x4e\x3d\xc2\x37\xa5\x08\x4f\xcb\x11\x0a\xdc\x33\x4d\xe7\xa5\x14\x2b\x76\x74\x56\xdf\x2e\xfa\xea\x27\x00\x00\xff\xff\xac\x18\x0d\xe7\xf7\x03\x00\x00")
+var _sqlUpdateminutesgSql = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x2b\x2d\x48\x49\x2c\x49\x55\x00\x12\x89\xf1\xb9\x99\x79\xa5\x40\x76\x71\x6a\x89\x42\xb0\xbb\x6f\x66\x9e\x82\xad\x82\xbd\xa1\x0e\x90\xed\x58\x96\x0e\x62\x1b\x81\xd8\xbe\x89\x15\x20\xb6\xb1\x42\x79\x46\x6a\x51\xaa\x82\x53\x69\x72\x36\x50\x3d\x50\xc4\x44\x21\x31\x2f\x45\x21\x33\x05\xc4\x36\x05\x00\x39\xb1\xe6\xf5\x57\x00\x00\x00")
 
-func sqlUpdatelastconfigSqlBytes() ([]byte, error) {
+func sqlUpdateminutesgSqlBytes() ([]byte, error) {
 	return bindataRead(
-		_sqlUpdatelastconfigSql,
-		"sql/updateLastConfig.sql",
+		_sqlUpdateminutesgSql,
+		"sql/updateMinuteSG.sql",
 	)
 }
 
-func sqlUpdatelastconfigSql() (*asset, error) {
-	bytes, err := sqlUpdatelastconfigSqlBytes()
+func sqlUpdateminutesgSql() (*asset, error) {
+	bytes, err := sqlUpdateminutesgSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "sql/updateLastConfig.sql", size: 1015, mode: os.FileMode(420), modTime: time.Unix(1457489134, 0)}
+	info := bindataFileInfo{name: "sql/updateMinuteSG.sql", size: 87, mode: os.FileMode(420), modTime: time.Unix(1792413880, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -308,15 +1085,52 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"sql/configTableExists.sql":   sqlConfigtableexistsSql,
-	"sql/createConfigTable.sql":   sqlCreateconfigtableSql,
-	"sql/createDataTable.sql":     sqlCreatedatatableSql,
-	"sql/dataTableExists.sql":     sqlDatatableexistsSql,
-	"sql/insertDataPoint.sql":     sqlInsertdatapointSql,
-	"sql/insertDefaultConfig.sql": sqlInsertdefaultconfigSql,
-	"sql/selectDataPoints.sql":    sqlSelectdatapointsSql,
-	"sql/selectLatestConfig.sql":  sqlSelectlatestconfigSql,
-	"sql/updateLastConfig.sql":    sqlUpdatelastconfigSql,
+	"sql/configHasFilters.sql":                    sqlConfighasfiltersSql,
+	"sql/configTableExists.sql":                   sqlConfigtableexistsSql,
+	"sql/createSchemaVersionTable.sql":            sqlCreateschemaversiontableSql,
+	"sql/deleteCalibrationPoint.sql":              sqlDeletecalibrationpointSql,
+	"sql/deleteControlSetpoints.sql":              sqlDeletecontrolsetpointsSql,
+	"sql/deleteMinuteData.sql":                    sqlDeleteminutedataSql,
+	"sql/deleteRawData.sql":                       sqlDeleterawdataSql,
+	"sql/deleteSchedule.sql":                      sqlDeletescheduleSql,
+	"sql/insertCalibrationPoint.sql":              sqlInsertcalibrationpointSql,
+	"sql/insertControlSetpoint.sql":               sqlInsertcontrolsetpointSql,
+	"sql/insertControlStats.sql":                  sqlInsertcontrolstatsSql,
+	"sql/insertDataPoint.sql":                     sqlInsertdatapointSql,
+	"sql/insertNewBrew.sql":                       sqlInsertnewbrewSql,
+	"sql/insertScheduleStep.sql":                  sqlInsertschedulestepSql,
+	"sql/insertSchemaVersion.sql":                 sqlInsertschemaversionSql,
+	"sql/insertStageEvent.sql":                    sqlInsertstageeventSql,
+	"sql/migration001Initial.sql":                 sqlMigration001initialSql,
+	"sql/migration002ConfigFilters.sql":           sqlMigration002configfiltersSql,
+	"sql/migration003Sessions.sql":                sqlMigration003sessionsSql,
+	"sql/migration004StageEvents.sql":             sqlMigration004stageeventsSql,
+	"sql/migration005Rollups.sql":                 sqlMigration005rollupsSql,
+	"sql/migration006Schedule.sql":                sqlMigration006scheduleSql,
+	"sql/migration007TemperatureCompensation.sql": sqlMigration007temperaturecompensationSql,
+	"sql/migration008Calibration.sql":             sqlMigration008calibrationSql,
+	"sql/migration009ControlStats.sql":            sqlMigration009controlstatsSql,
+	"sql/migration010ControlSetpoints.sql":        sqlMigration010controlsetpointsSql,
+	"sql/rollupHours.sql":                         sqlRolluphoursSql,
+	"sql/rollupMinutes.sql":                       sqlRollupminutesSql,
+	"sql/schemaVersionTableExists.sql":            sqlSchemaversiontableexistsSql,
+	"sql/selectCalibrationPoints.sql":             sqlSelectcalibrationpointsSql,
+	"sql/selectConfig.sql":                        sqlSelectconfigSql,
+	"sql/selectControlSetpoints.sql":              sqlSelectcontrolsetpointsSql,
+	"sql/selectControlStats.sql":                  sqlSelectcontrolstatsSql,
+	"sql/selectDataSG.sql":                        sqlSelectdatasgSql,
+	"sql/selectHourSG.sql":                        sqlSelecthoursgSql,
+	"sql/selectLatestConfig.sql":                  sqlSelectlatestconfigSql,
+	"sql/selectMinuteSG.sql":                      sqlSelectminutesgSql,
+	"sql/selectSchedule.sql":                      sqlSelectscheduleSql,
+	"sql/selectSchemaVersion.sql":                 sqlSelectschemaversionSql,
+	"sql/selectSessionDataPoints.sql":             sqlSelectsessiondatapointsSql,
+	"sql/selectSessions.sql":                      sqlSelectsessionsSql,
+	"sql/selectStageEvents.sql":                   sqlSelectstageeventsSql,
+	"sql/updateConfig.sql":                        sqlUpdateconfigSql,
+	"sql/updateDataSG.sql":                        sqlUpdatedatasgSql,
+	"sql/updateHourSG.sql":                        sqlUpdatehoursgSql,
+	"sql/updateMinuteSG.sql":                      sqlUpdateminutesgSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -361,15 +1175,52 @@ type bintree struct {
 
 var _bintree = &bintree{nil, map[string]*bintree{
 	"sql": &bintree{nil, map[string]*bintree{
-		"configTableExists.sql":   &bintree{sqlConfigtableexistsSql, map[string]*bintree{}},
-		"createConfigTable.sql":   &bintree{sqlCreateconfigtableSql, map[string]*bintree{}},
-		"createDataTable.sql":     &bintree{sqlCreatedatatableSql, map[string]*bintree{}},
-		"dataTableExists.sql":     &bintree{sqlDatatableexistsSql, map[string]*bintree{}},
-		"insertDataPoint.sql":     &bintree{sqlInsertdatapointSql, map[string]*bintree{}},
-		"insertDefaultConfig.sql": &bintree{sqlInsertdefaultconfigSql, map[string]*bintree{}},
-		"selectDataPoints.sql":    &bintree{sqlSelectdatapointsSql, map[string]*bintree{}},
-		"selectLatestConfig.sql":  &bintree{sqlSelectlatestconfigSql, map[string]*bintree{}},
-		"updateLastConfig.sql":    &bintree{sqlUpdatelastconfigSql, map[string]*bintree{}},
+		"configHasFilters.sql":                    &bintree{sqlConfighasfiltersSql, map[string]*bintree{}},
+		"configTableExists.sql":                   &bintree{sqlConfigtableexistsSql, map[string]*bintree{}},
+		"createSchemaVersionTable.sql":            &bintree{sqlCreateschemaversiontableSql, map[string]*bintree{}},
+		"deleteCalibrationPoint.sql":              &bintree{sqlDeletecalibrationpointSql, map[string]*bintree{}},
+		"deleteControlSetpoints.sql":              &bintree{sqlDeletecontrolsetpointsSql, map[string]*bintree{}},
+		"deleteMinuteData.sql":                    &bintree{sqlDeleteminutedataSql, map[string]*bintree{}},
+		"deleteRawData.sql":                       &bintree{sqlDeleterawdataSql, map[string]*bintree{}},
+		"deleteSchedule.sql":                      &bintree{sqlDeletescheduleSql, map[string]*bintree{}},
+		"insertCalibrationPoint.sql":              &bintree{sqlInsertcalibrationpointSql, map[string]*bintree{}},
+		"insertControlSetpoint.sql":               &bintree{sqlInsertcontrolsetpointSql, map[string]*bintree{}},
+		"insertControlStats.sql":                  &bintree{sqlInsertcontrolstatsSql, map[string]*bintree{}},
+		"insertDataPoint.sql":                     &bintree{sqlInsertdatapointSql, map[string]*bintree{}},
+		"insertNewBrew.sql":                       &bintree{sqlInsertnewbrewSql, map[string]*bintree{}},
+		"insertScheduleStep.sql":                  &bintree{sqlInsertschedulestepSql, map[string]*bintree{}},
+		"insertSchemaVersion.sql":                 &bintree{sqlInsertschemaversionSql, map[string]*bintree{}},
+		"insertStageEvent.sql":                    &bintree{sqlInsertstageeventSql, map[string]*bintree{}},
+		"migration001Initial.sql":                 &bintree{sqlMigration001initialSql, map[string]*bintree{}},
+		"migration002ConfigFilters.sql":           &bintree{sqlMigration002configfiltersSql, map[string]*bintree{}},
+		"migration003Sessions.sql":                &bintree{sqlMigration003sessionsSql, map[string]*bintree{}},
+		"migration004StageEvents.sql":             &bintree{sqlMigration004stageeventsSql, map[string]*bintree{}},
+		"migration005Rollups.sql":                 &bintree{sqlMigration005rollupsSql, map[string]*bintree{}},
+		"migration006Schedule.sql":                &bintree{sqlMigration006scheduleSql, map[string]*bintree{}},
+		"migration007TemperatureCompensation.sql": &bintree{sqlMigration007temperaturecompensationSql, map[string]*bintree{}},
+		"migration008Calibration.sql":             &bintree{sqlMigration008calibrationSql, map[string]*bintree{}},
+		"migration009ControlStats.sql":            &bintree{sqlMigration009controlstatsSql, map[string]*bintree{}},
+		"migration010ControlSetpoints.sql":        &bintree{sqlMigration010controlsetpointsSql, map[string]*bintree{}},
+		"rollupHours.sql":                         &bintree{sqlRolluphoursSql, map[string]*bintree{}},
+		"rollupMinutes.sql":                       &bintree{sqlRollupminutesSql, map[string]*bintree{}},
+		"schemaVersionTableExists.sql":            &bintree{sqlSchemaversiontableexistsSql, map[string]*bintree{}},
+		"selectCalibrationPoints.sql":             &bintree{sqlSelectcalibrationpointsSql, map[string]*bintree{}},
+		"selectConfig.sql":                        &bintree{sqlSelectconfigSql, map[string]*bintree{}},
+		"selectControlSetpoints.sql":              &bintree{sqlSelectcontrolsetpointsSql, map[string]*bintree{}},
+		"selectControlStats.sql":                  &bintree{sqlSelectcontrolstatsSql, map[string]*bintree{}},
+		"selectDataSG.sql":                        &bintree{sqlSelectdatasgSql, map[string]*bintree{}},
+		"selectHourSG.sql":                        &bintree{sqlSelecthoursgSql, map[string]*bintree{}},
+		"selectLatestConfig.sql":                  &bintree{sqlSelectlatestconfigSql, map[string]*bintree{}},
+		"selectMinuteSG.sql":                      &bintree{sqlSelectminutesgSql, map[string]*bintree{}},
+		"selectSchedule.sql":                      &bintree{sqlSelectscheduleSql, map[string]*bintree{}},
+		"selectSchemaVersion.sql":                 &bintree{sqlSelectschemaversionSql, map[string]*bintree{}},
+		"selectSessionDataPoints.sql":             &bintree{sqlSelectsessiondatapointsSql, map[string]*bintree{}},
+		"selectSessions.sql":                      &bintree{sqlSelectsessionsSql, map[string]*bintree{}},
+		"selectStageEvents.sql":                   &bintree{sqlSelectstageeventsSql, map[string]*bintree{}},
+		"updateConfig.sql":                        &bintree{sqlUpdateconfigSql, map[string]*bintree{}},
+		"updateDataSG.sql":                        &bintree{sqlUpdatedatasgSql, map[string]*bintree{}},
+		"updateHourSG.sql":                        &bintree{sqlUpdatehoursgSql, map[string]*bintree{}},
+		"updateMinuteSG.sql":                      &bintree{sqlUpdateminutesgSql, map[string]*bintree{}},
 	}},
 }}
 
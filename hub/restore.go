@@ -0,0 +1,148 @@
+package hub
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+const (
+	// stagedSuffix names the backup waiting to replace the database at the
+	// next start, e.g. alcobot.db.restore.
+	stagedSuffix = ".restore"
+	// replacedSuffix names the database a restore replaced, kept in case
+	// the backup turns out to be the wrong one.
+	replacedSuffix = ".replaced"
+)
+
+// databaseFiles are the suffixes of the files SQLite keeps a database in.
+var databaseFiles = []string{"", "-wal", "-shm"}
+
+// ValidateBackup checks that file is an intact database with a schema this
+// version of the bot can migrate, and returns its schema version.
+func ValidateBackup(file string) (int, error) {
+	if _, err := os.Stat(file); err != nil {
+		return 0, err
+	}
+	db, err := sql.Open("sqlite3", "file:"+file+"?mode=ro")
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var check string
+	if err := db.QueryRow("pragma quick_check").Scan(&check); err != nil {
+		return 0, fmt.Errorf("%v is not a database: %w", file, err)
+	}
+	if check != "ok" {
+		return 0, fmt.Errorf("%v is corrupted: %v", file, check)
+	}
+	if ok, err := exists(db, query("schemaVersionTableExists.sql")); err != nil {
+		return 0, err
+	} else if !ok {
+		return 0, fmt.Errorf("%v has no schema version", file)
+	}
+	version, err := schemaVersion(db)
+	if err != nil {
+		return 0, err
+	}
+	ms := migrations()
+	if latest := ms[len(ms)-1].version; version < 1 || version > latest {
+		return 0, fmt.Errorf("%v has schema version %d, this version of the bot supports 1 to %d", file, version, latest)
+	}
+	return version, nil
+}
+
+// Restore replaces the database in file with the backup in src once it is
+// validated; the bot must not be running. The replaced database is kept
+// next to file. It returns the schema version of the backup, which is
+// migrated when the database is next opened.
+func Restore(src, file string) (int, error) {
+	version, err := ValidateBackup(src)
+	if err != nil {
+		return 0, err
+	}
+	tmp := file + ".tmp"
+	if err := copyFile(src, tmp); err != nil {
+		return 0, fmt.Errorf("can't copy backup %v: %w", src, err)
+	}
+	for _, suffix := range databaseFiles {
+		err := os.Rename(file+suffix, file+replacedSuffix+suffix)
+		if err != nil && !os.IsNotExist(err) {
+			os.Remove(tmp)
+			return 0, fmt.Errorf("can't keep the replaced database: %w", err)
+		}
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return 0, err
+	}
+	log.Printf("Restored %v from %v at schema version %d, the replaced database is %v\n", file, src, version, file+replacedSuffix)
+	return version, nil
+}
+
+// StageRestore validates the backup read from r and stages it to replace
+// the database the next time the bot starts. It returns the schema version
+// of the backup.
+func (h *Hub) StageRestore(r io.Reader) (int, error) {
+	if h.file == "" {
+		return 0, errors.New("the database has no file to restore")
+	}
+	staged := h.file + stagedSuffix
+	tmp := staged + ".tmp"
+	if err := writeFile(tmp, r); err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	version, err := ValidateBackup(tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	if err := os.Rename(tmp, staged); err != nil {
+		return 0, err
+	}
+	log.Printf("Staged backup at schema version %d to restore at the next start\n", version)
+	return version, nil
+}
+
+// restoreStaged restores the backup staged for file, if there is one.
+// The staged backup is removed either way.
+func restoreStaged(file string) error {
+	staged := file + stagedSuffix
+	if _, err := os.Stat(staged); os.IsNotExist(err) {
+		return nil
+	}
+	defer os.Remove(staged)
+	_, err := Restore(staged, file)
+	return err
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return writeFile(dst, in)
+}
+
+// writeFile writes r to file and syncs it, so a power cut doesn't leave a
+// half written database behind a rename.
+func writeFile(file string, r io.Reader) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,103 @@
+package hub
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBackup(t *testing.T) {
+	h := newTestHub(t)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "backup.db")
+	assert.NoError(t, h.Backup(file))
+	version, err := ValidateBackup(file)
+	assert.NoError(t, err)
+	assert.Equal(t, latest(), version)
+
+	junk := filepath.Join(dir, "junk.db")
+	assert.NoError(t, os.WriteFile(junk, []byte("not a database"), 0644))
+	_, err = ValidateBackup(junk)
+	assert.Error(t, err)
+	_, err = ValidateBackup(filepath.Join(dir, "missing.db"))
+	assert.Error(t, err)
+
+	other := filepath.Join(dir, "other.db")
+	db, err := open(other)
+	assert.NoError(t, err)
+	_, err = db.Exec("create table beers(name text)")
+	assert.NoError(t, err)
+	db.Close()
+	_, err = ValidateBackup(other)
+	assert.ErrorContains(t, err, "no schema version")
+
+	_, err = h.db.Exec(query("insertSchemaVersion.sql"), latest()+1, "2026-03-01")
+	assert.NoError(t, err)
+	newer := filepath.Join(dir, "newer.db")
+	assert.NoError(t, h.Backup(newer))
+	_, err = ValidateBackup(newer)
+	assert.ErrorContains(t, err, "supports")
+}
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "alcobot.db")
+	h, err := Open(file)
+	assert.NoError(t, err)
+	assert.NoError(t, h.Plan("Backed up", 0, nil))
+	backup := filepath.Join(dir, "backup.db")
+	assert.NoError(t, h.Backup(backup))
+	assert.NoError(t, h.Plan("Lost", 0, nil))
+	h.Close()
+
+	version, err := Restore(backup, file)
+	assert.NoError(t, err)
+	assert.Equal(t, latest(), version)
+	assert.FileExists(t, file+replacedSuffix)
+
+	h, err = Open(file)
+	assert.NoError(t, err)
+	defer h.Close()
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "Backed up", conf.Name)
+
+	_, err = Restore(file+replacedSuffix+"-nothing", file)
+	assert.Error(t, err)
+}
+
+func TestStageRestore(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "alcobot.db")
+	h, err := New(file)
+	assert.NoError(t, err)
+	assert.NoError(t, h.Plan("Staged", 0, nil))
+	backup := filepath.Join(dir, "backup.db")
+	assert.NoError(t, h.Backup(backup))
+	assert.NoError(t, h.Plan("Replaced", 0, nil))
+
+	_, err = h.StageRestore(strings.NewReader("not a database"))
+	assert.Error(t, err)
+	assert.NoFileExists(t, file+stagedSuffix)
+
+	data, err := os.ReadFile(backup)
+	assert.NoError(t, err)
+	version, err := h.StageRestore(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, latest(), version)
+	assert.FileExists(t, file+stagedSuffix)
+	h.Close()
+
+	h, err = New(file)
+	assert.NoError(t, err)
+	defer h.Close()
+	assert.NoFileExists(t, file+stagedSuffix)
+	assert.False(t, h.StorageStatus().Degraded)
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "Staged", conf.Name)
+}
@@ -0,0 +1,91 @@
+package hub
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+const (
+	// rawRetention is how long data points are kept at full resolution
+	// before they are rolled up into per-minute buckets.
+	rawRetention = time.Hour * 24
+	// minuteRetention is how long per-minute buckets are kept before they
+	// are rolled up into per-hour buckets, which are kept forever.
+	minuteRetention = time.Hour * 24 * 30
+	// retentionPeriod is how often the retention pass runs.
+	retentionPeriod = time.Minute * 10
+)
+
+// retention rolls up old data points every retentionPeriod until ctx is done.
+func (h *Hub) retention(ctx context.Context) {
+	t := time.NewTicker(retentionPeriod)
+	defer t.Stop()
+	for {
+		select {
+		case now := <-t.C:
+			h.compact(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// compact rolls up the data points of every brew recorded more than
+// rawRetention before now into per-minute min/avg/max buckets, and the
+// buckets older than minuteRetention into per-hour ones. Only complete
+// buckets are rolled up and the rolled up rows are deleted, so it can run
+// any number of times. Rows that arrive after their bucket was rolled up
+// are merged into it.
+func (h *Hub) compact(now time.Time) {
+	sessions, err := h.Sessions()
+	if err != nil {
+		log.Printf("Can't list brews for data retention: %v\n", h.report(err))
+		return
+	}
+	h.dbLock.Lock()
+	defer h.dbLock.Unlock()
+	for _, s := range sessions {
+		if !s.Started() {
+			continue
+		}
+		age := now.Sub(s.Start)
+		// minute bucket m holds steps 60m+1 to 60(m+1)
+		if step := int((age-rawRetention)/time.Minute) * 60; step > 0 {
+			if err := h.report(h.rollup(s.Id, step, "rollupMinutes.sql", "deleteRawData.sql")); err != nil {
+				log.Printf("Can't roll up data points of brew %d: %v\n", s.Id, err)
+				continue
+			}
+		}
+		// hour bucket b holds minute buckets 60b to 60b+59
+		if bucket := int((age-minuteRetention)/time.Hour) * 60; bucket > 0 {
+			if err := h.report(h.rollup(s.Id, bucket, "rollupHours.sql", "deleteMinuteData.sql")); err != nil {
+				log.Printf("Can't roll up minute buckets of brew %d: %v\n", s.Id, err)
+			}
+		}
+	}
+}
+
+// rollup aggregates the rows of brew id up to limit with the rollup query
+// and deletes them with the delete query in one transaction.
+func (h *Hub) rollup(id, limit int, rollup, delete string) error {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := exec(tx, rollup, id, limit); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := exec(tx, delete, id, limit); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func exec(tx *sql.Tx, name string, args ...interface{}) error {
+	_, err := tx.Exec(query(name), args...)
+	return err
+}
@@ -0,0 +1,123 @@
+package hub
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactRollsUpOldDataPoints(t *testing.T) {
+	h := newTestHub(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	// 150 minutes recorded, the first two hours older than minuteRetention
+	conf.BrewingStartTime = now.Add(-minuteRetention - 150*time.Minute)
+	h.Conf = conf
+	h.saveConfig()
+	var points []*DataPoint
+	for step := 1; step <= 9000; step++ {
+		sg := 1.050
+		if step <= 60 {
+			sg = math.NaN()
+		}
+		points = append(points, &DataPoint{Id: conf.Id, Step: step, TargetTemp: float64(step), CurrentTemp: 200, SG: sg, Span: 1})
+	}
+	assert.NoError(t, h.SaveDataPoints(points))
+
+	for i := 0; i < 2; i++ {
+		h.compact(now)
+
+		dps, err := h.SessionDataPoints(conf.Id)
+		assert.NoError(t, err)
+		assert.Len(t, dps, 2+30)
+		assert.Equal(t, 3600, dps[0].Step)
+		assert.Equal(t, 3600, dps[0].Span)
+		assert.Equal(t, 1800.5, dps[0].TargetTemp)
+		assert.InDelta(t, 1.050, dps[0].SG, 1e-9)
+		assert.Equal(t, 7200, dps[1].Step)
+		assert.Equal(t, 7260, dps[2].Step)
+		assert.Equal(t, 60, dps[2].Span)
+		assert.Equal(t, 9000, dps[31].Step)
+	}
+
+	var min, max float64
+	var samples int
+	assert.NoError(t, h.db.QueryRow("select TargetTempMin, TargetTempMax, Samples from data_hour where id = ? and Bucket = 1", conf.Id).Scan(&min, &max, &samples))
+	assert.Equal(t, 3601., min)
+	assert.Equal(t, 7200., max)
+	assert.Equal(t, 3600, samples)
+
+	sessions, err := h.Sessions()
+	assert.NoError(t, err)
+	assert.Equal(t, 9000*time.Second, sessions[0].Duration)
+	assert.InDelta(t, 1.050, sessions[0].FG, 1e-9)
+}
+
+func TestCompactKeepsRecentDataPoints(t *testing.T) {
+	h := newTestHub(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	conf.BrewingStartTime = now.Add(-rawRetention - 200*time.Second)
+	h.Conf = conf
+	h.saveConfig()
+	var points []*DataPoint
+	for step := 1; step <= 200; step++ {
+		points = append(points, &DataPoint{Id: conf.Id, Step: step, TargetTemp: 20, CurrentTemp: float64(step), SG: math.NaN(), Span: 1})
+	}
+	assert.NoError(t, h.SaveDataPoints(points))
+
+	h.compact(now)
+
+	dps, err := h.SessionDataPoints(conf.Id)
+	assert.NoError(t, err)
+	// three complete minutes are rolled up, the rest stays at full resolution
+	assert.Len(t, dps, 3+20)
+	assert.Equal(t, 180, dps[2].Step)
+	assert.Equal(t, 150.5, dps[2].CurrentTemp)
+	assert.True(t, math.IsNaN(dps[2].SG))
+	assert.Equal(t, 181, dps[3].Step)
+	assert.Equal(t, 1, dps[3].Span)
+}
+
+func TestCompactMergesLateDataPoints(t *testing.T) {
+	h := newTestHub(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	conf.BrewingStartTime = now.Add(-rawRetention - 200*time.Second)
+	h.Conf = conf
+	h.saveConfig()
+	var points []*DataPoint
+	for step := 1; step <= 200; step++ {
+		if step == 30 {
+			continue
+		}
+		points = append(points, &DataPoint{Id: conf.Id, Step: step, TargetTemp: 20, CurrentTemp: 100, SG: math.NaN(), Span: 1})
+	}
+	assert.NoError(t, h.SaveDataPoints(points))
+	h.compact(now)
+
+	// step 30 arrives after its minute was rolled up
+	assert.NoError(t, h.SaveDataPoints([]*DataPoint{{Id: conf.Id, Step: 30, TargetTemp: 20, CurrentTemp: 690, SG: 1.050, Span: 1}}))
+	h.compact(now)
+	assert.False(t, h.StorageStatus().Degraded)
+
+	var samples int
+	var min, avg, max, sg float64
+	assert.NoError(t, h.db.QueryRow("select Samples, CurrentTempMin, CurrentTempAvg, CurrentTempMax, SGAvg from data_minute where id = ? and Bucket = 0", conf.Id).Scan(&samples, &min, &avg, &max, &sg))
+	assert.Equal(t, 60, samples)
+	assert.Equal(t, 100., min)
+	assert.InDelta(t, (59*100+690)/60., avg, 1e-9)
+	assert.Equal(t, 690., max)
+	assert.InDelta(t, 1.050, sg, 1e-9)
+	var raw int
+	assert.NoError(t, h.db.QueryRow("select count(*) from data where id = ? and Step <= 180", conf.Id).Scan(&raw))
+	assert.Equal(t, 0, raw)
+}
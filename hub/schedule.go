@@ -0,0 +1,177 @@
+package hub
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+)
+
+// ScheduleStep is a step of the fermentation schedule of a brew: hold
+// Temperature (ºC) for Duration after the previous step. A Duration of 0
+// holds it until the brew is finished.
+type ScheduleStep struct {
+	Name        string
+	Temperature float64
+	Duration    time.Duration
+}
+
+// Plan sets the name, OG and fermentation schedule of the brew in setup,
+// e.g. from an imported recipe. The target temperature is set to the first
+// step of the schedule. An OG of 0 leaves it unset.
+// The brew is the one in memory, which may be ahead of the stored one while
+// storage is degraded.
+func (h *Hub) Plan(name string, og float64, steps []ScheduleStep) error {
+	h.dbLock.Lock()
+	conf, err := h.plan(name, og, steps)
+	h.dbLock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Planned brew %v (%d) with %d schedule steps\n", conf.Name, conf.Id, len(steps))
+	h.Configuration.Send(conf)
+	return nil
+}
+
+func (h *Hub) plan(name string, og float64, steps []ScheduleStep) (*config.Configuration, error) {
+	conf, err := h.current()
+	if err != nil {
+		return nil, err
+	}
+	if conf.Stage != config.SETUP {
+		return nil, fmt.Errorf("%w: can't plan a brew in %v", ErrTransition, conf.Stage)
+	}
+	if name != "" {
+		conf.Name = name
+	}
+	if og > 0 {
+		conf.OG = og
+	}
+	if len(steps) > 0 {
+		conf.TargetTemperature = steps[0].Temperature
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := updateConfig(tx, conf); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := replaceSchedule(tx, conf.Id, steps); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	h.Conf = conf
+	return conf, nil
+}
+
+func replaceSchedule(tx *sql.Tx, id int, steps []ScheduleStep) error {
+	if _, err := tx.Exec(query("deleteSchedule.sql"), id); err != nil {
+		return err
+	}
+	for i, s := range steps {
+		if _, err := tx.Exec(query("insertScheduleStep.sql"), id, i, s.Name, s.Temperature, int(s.Duration/time.Second)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EndScheduleStep ends the schedule step in progress at now, so the next one
+// starts, e.g. once fermentation is complete. The configuration is sent
+// again for the schedule runner to pick the change up.
+func (h *Hub) EndScheduleStep(now time.Time) error {
+	h.dbLock.Lock()
+	conf, step, err := h.endScheduleStep(now)
+	h.dbLock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Brew %d: ended schedule step %d early\n", conf.Id, step+1)
+	h.Configuration.Send(conf)
+	return nil
+}
+
+func (h *Hub) endScheduleStep(now time.Time) (*config.Configuration, int, error) {
+	conf, err := h.current()
+	if err != nil {
+		return nil, 0, err
+	}
+	if conf.Stage != config.BREWING {
+		return nil, 0, fmt.Errorf("%w: no schedule in %v", ErrTransition, conf.Stage)
+	}
+	steps, err := h.Schedule(conf.Id)
+	if err != nil {
+		return nil, 0, err
+	}
+	elapsed := now.Sub(conf.PitchTime)
+	i := CurrentStep(steps, elapsed)
+	if i < 0 || i == len(steps)-1 {
+		return nil, 0, errors.New("no next schedule step")
+	}
+	for _, s := range steps[:i] {
+		elapsed -= s.Duration
+	}
+	steps[i].Duration = elapsed.Truncate(time.Second)
+	if steps[i].Duration < time.Second {
+		steps[i].Duration = time.Second
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := replaceSchedule(tx, conf.Id, steps); err != nil {
+		tx.Rollback()
+		return nil, 0, err
+	}
+	return conf, i, tx.Commit()
+}
+
+// CurrentStep returns the index of the step in progress elapsed after the
+// start of the schedule, or -1 without steps. The last step lasts forever,
+// and so does any step with a Duration of 0.
+func CurrentStep(steps []ScheduleStep, elapsed time.Duration) int {
+	end := time.Duration(0)
+	for i, s := range steps {
+		if s.Duration == 0 {
+			return i
+		}
+		end += s.Duration
+		if elapsed < end {
+			return i
+		}
+	}
+	return len(steps) - 1
+}
+
+// Schedule loads the fermentation schedule of a brew.
+func (h *Hub) Schedule(id int) ([]ScheduleStep, error) {
+	rows, err := h.db.Query(query("selectSchedule.sql"), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var steps []ScheduleStep
+	for rows.Next() {
+		var s ScheduleStep
+		var seconds int
+		if err := rows.Scan(&s.Name, &s.Temperature, &seconds); err != nil {
+			return nil, err
+		}
+		s.Duration = time.Duration(seconds) * time.Second
+		steps = append(steps, s)
+	}
+	return steps, rows.Err()
+}
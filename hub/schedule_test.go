@@ -0,0 +1,110 @@
+package hub
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/config"
+)
+
+func TestPlan(t *testing.T) {
+	h := newTestHub(t)
+	configs := h.Configuration.Subscribe()
+	defer configs.Close()
+
+	steps := []ScheduleStep{
+		{"Primary", 18, 10 * 24 * time.Hour},
+		{"Diacetyl rest", 20, 2 * 24 * time.Hour},
+		{"Conditioning", 2, 0},
+	}
+	assert.NoError(t, h.Plan("Helles", 1.048, steps))
+	conf := <-configs.C
+	assert.Equal(t, "Helles", conf.Name)
+	assert.Equal(t, 1.048, conf.OG)
+	assert.Equal(t, 18., conf.TargetTemperature)
+
+	saved, err := h.Schedule(conf.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, steps, saved)
+
+	// planning again replaces the schedule
+	assert.NoError(t, h.Plan("", 0, steps[:1]))
+	conf = <-configs.C
+	assert.Equal(t, "Helles", conf.Name)
+	assert.Equal(t, 1.048, conf.OG)
+	saved, err = h.Schedule(conf.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, steps[:1], saved)
+}
+
+func TestPlanOnlyInSetup(t *testing.T) {
+	h := newTestHub(t)
+	assert.NoError(t, h.Transition(Prepare))
+	err := h.Plan("Late", 1.050, nil)
+	assert.True(t, errors.Is(err, ErrTransition))
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, config.PREPARATION, conf.Stage)
+	assert.NotEqual(t, "Late", conf.Name)
+}
+
+func TestEndScheduleStep(t *testing.T) {
+	h := newTestHub(t)
+	day := 24 * time.Hour
+	steps := []ScheduleStep{
+		{"Primary", 18, 10 * day},
+		{"Diacetyl rest", 20, 2 * day},
+		{"Conditioning", 2, 0},
+	}
+	assert.NoError(t, h.Plan("Helles", 1.048, steps))
+	assert.True(t, errors.Is(h.EndScheduleStep(time.Now()), ErrTransition))
+	assert.NoError(t, h.Transition(Prepare))
+	assert.NoError(t, h.Transition(Pitch))
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+
+	_, step, err := h.endScheduleStep(conf.PitchTime.Add(6*day + time.Hour + 1500*time.Millisecond))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, step)
+	saved, err := h.Schedule(conf.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 6*day+time.Hour+time.Second, saved[0].Duration)
+	assert.Equal(t, steps[1:], saved[1:])
+
+	_, step, err = h.endScheduleStep(conf.PitchTime.Add(7 * day))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, step)
+	saved, err = h.Schedule(conf.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, day-time.Hour-time.Second, saved[1].Duration)
+	assert.Equal(t, 2, CurrentStep(saved, 7*day))
+
+	_, _, err = h.endScheduleStep(conf.PitchTime.Add(8 * day))
+	assert.Error(t, err, "the last step holds until the brew is finished")
+}
+
+func TestScheduleFollowsTheStageInMemory(t *testing.T) {
+	h := newTestHub(t)
+	h.db.SetMaxOpenConns(1)
+	day := 24 * time.Hour
+	steps := []ScheduleStep{{"Primary", 18, 10 * day}, {"Conditioning", 2, 0}}
+	assert.NoError(t, h.Plan("Helles", 1.048, steps))
+
+	// the transitions aren't stored while the database refuses writes
+	_, err := h.db.Exec("pragma query_only = 1")
+	assert.NoError(t, err)
+	assert.NoError(t, h.Transition(Prepare))
+	assert.NoError(t, h.Transition(Pitch))
+	_, err = h.db.Exec("pragma query_only = 0")
+	assert.NoError(t, err)
+	stored, err := h.latestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, config.SETUP, stored.Stage)
+
+	assert.True(t, errors.Is(h.Plan("Late", 1.050, nil), ErrTransition))
+	_, step, err := h.endScheduleStep(h.Conf.PitchTime.Add(day))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, step)
+}
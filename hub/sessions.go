@@ -0,0 +1,177 @@
+package hub
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+)
+
+// Session is a brew, current or archived. Every brew has its own row in
+// the config table and its data points are keyed by that row's id.
+type Session struct {
+	Id       int
+	Name     string
+	Stage    config.Stage
+	Start    time.Time
+	Pitch    time.Time
+	End      time.Time
+	Duration time.Duration
+	OG       float64
+	FG       float64
+}
+
+// Started reports whether the session got past setup.
+func (s Session) Started() bool {
+	return s.Start.Unix() > 0
+}
+
+// Sessions lists all brews, the current one first.
+func (h *Hub) Sessions() ([]Session, error) {
+	rows, err := h.db.Query(query("selectSessions.sql"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		var steps sql.NullInt64
+		var first, last sql.NullFloat64
+		if err := rows.Scan(&s.Id, &s.Name, &s.Stage, &s.Start, &s.Pitch, &s.OG, &steps, &first, &last); err != nil {
+			return nil, err
+		}
+		// a data point is recorded every second
+		s.Duration = time.Duration(steps.Int64) * time.Second
+		s.End = s.Start.Add(s.Duration)
+		if s.OG == 0 {
+			s.OG = nullable(first)
+		}
+		s.FG = nullable(last)
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// SessionConfig loads the configuration of a brew.
+func (h *Hub) SessionConfig(id int) (*config.Configuration, error) {
+	return scanConfig(h.db.QueryRow(query("selectConfig.sql"), id))
+}
+
+// SessionDataPoints loads the data points of a brew in order: the hourly
+// and per-minute averages of the rolled-up data followed by the recent full
+// resolution points, see compact. The alcohol is derived from the current
+// OG of the brew.
+func (h *Hub) SessionDataPoints(id int) ([]*DataPoint, error) {
+	conf, err := h.SessionConfig(id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := h.db.Query(query("selectSessionDataPoints.sql"), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dps []*DataPoint
+	last := 0
+	for rows.Next() {
+		dp := &DataPoint{}
+		var targetTemp, currentTemp, sg, pid, power sql.NullFloat64
+		if err := rows.Scan(&dp.Id, &dp.Step, &targetTemp, &currentTemp, &sg, &pid, &power); err != nil {
+			return nil, err
+		}
+		dp.TargetTemp = nullable(targetTemp)
+		dp.CurrentTemp = nullable(currentTemp)
+		dp.SG = nullable(sg)
+		dp.PID = nullable(pid)
+		dp.Power = nullable(power)
+		dp.Alcohol = NewAlcohol(conf.OG, dp.SG)
+		dp.Span = dp.Step - last
+		last = dp.Step
+		dps = append(dps, dp)
+	}
+	return dps, rows.Err()
+}
+
+// NewBrew archives the finished brew and starts a new one in setup,
+// carrying over the hardware settings and calibration. An empty name is
+// replaced by a default one.
+func (h *Hub) NewBrew(name string) error {
+	now := time.Now()
+	if name == "" {
+		name = fmt.Sprintf("Brew %v", now.Format("2006-01-02"))
+	}
+
+	h.dbLock.Lock()
+	conf, err := h.newBrew(name, now)
+	h.dbLock.Unlock()
+	if errors.Is(err, ErrTransition) {
+		return err
+	}
+	if h.report(err) != nil {
+		return err
+	}
+
+	log.Printf("Started new brew %v (%d)\n", conf.Name, conf.Id)
+	h.Configuration.Send(conf)
+	h.ScreenChange.Send(config.SETUP_SCREEN)
+	return nil
+}
+
+func (h *Hub) newBrew(name string, now time.Time) (*config.Configuration, error) {
+	old, err := h.current()
+	if err != nil {
+		return nil, err
+	}
+	if !Reset.Allowed(old.Stage) {
+		return nil, fmt.Errorf("%w: %v from %v", ErrTransition, Reset, old.Stage)
+	}
+	// the brew is archived as it is in memory
+	if h.configDirty {
+		if err := h.storeConfig(); err != nil {
+			return nil, err
+		}
+		h.configDirty = false
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	res, err := tx.Exec(query("insertNewBrew.sql"), name)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := insertStageEvent(tx, StageEvent{int(id), now, Reset, old.Stage, config.SETUP}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	conf, err := h.latestConfig()
+	if err != nil {
+		return nil, err
+	}
+	h.Conf = conf
+	return conf, nil
+}
+
+func nullable(x sql.NullFloat64) float64 {
+	if x.Valid {
+		return x.Float64
+	}
+	return math.NaN()
+}
@@ -0,0 +1,108 @@
+package hub
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/bus"
+	"github.com/zlowred/alcobot/config"
+)
+
+func newTestHub(t *testing.T) *Hub {
+	db, file := openTestDb(t)
+	if err := migrate(db, file); err != nil {
+		t.Fatal(err)
+	}
+	b := bus.New()
+	t.Cleanup(b.Close)
+	return &Hub{
+		Bus: b, db: db, file: file,
+		Configuration: bus.MustRegister[*config.Configuration](b, ConfigurationTopic, configurationDefaults...),
+		ScreenChange:  bus.MustRegister[config.Screen](b, ScreenChangeTopic),
+		Storage:       bus.MustRegister[StorageStatus](b, StorageTopic),
+		Alarm:         bus.MustRegister[Alarm](b, AlarmTopic),
+	}
+}
+
+func TestNewBrewArchivesCurrentBrew(t *testing.T) {
+	h := newTestHub(t)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	conf.Name = "Pale ale"
+	conf.NpaCalibration = 1234.5
+	conf.Stage = config.DONE
+	conf.BrewingStartTime = start
+	h.Conf = conf
+	h.saveConfig()
+	assert.NoError(t, h.SaveDataPoints([]*DataPoint{
+		{Id: conf.Id, Step: 1, TargetTemp: 20, CurrentTemp: 200, SG: math.NaN(), Span: 1},
+		{Id: conf.Id, Step: 2, TargetTemp: 20, CurrentTemp: 200, SG: 1.050, Span: 1},
+		{Id: conf.Id, Step: 3, TargetTemp: 20, CurrentTemp: 200, SG: 1.012, Span: 1},
+		{Id: conf.Id, Step: 4, TargetTemp: 20, CurrentTemp: 200, SG: math.NaN(), Span: 1},
+	}))
+
+	configs := h.Configuration.Subscribe()
+	defer configs.Close()
+	screens := h.ScreenChange.Subscribe()
+	defer screens.Close()
+
+	assert.NoError(t, h.NewBrew("Stout"))
+	next := <-configs.C
+	assert.Equal(t, conf.Id+1, next.Id)
+	assert.Equal(t, "Stout", next.Name)
+	assert.Equal(t, config.SETUP, next.Stage)
+	assert.Equal(t, 1234.5, next.NpaCalibration)
+	assert.Equal(t, config.SETUP_SCREEN, <-screens.C)
+
+	sessions, err := h.Sessions()
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	assert.Equal(t, "Stout", sessions[0].Name)
+	assert.False(t, sessions[0].Started())
+	assert.Equal(t, time.Duration(0), sessions[0].Duration)
+
+	archived := sessions[1]
+	assert.Equal(t, conf.Id, archived.Id)
+	assert.Equal(t, "Pale ale", archived.Name)
+	assert.Equal(t, config.DONE, archived.Stage)
+	assert.True(t, archived.Started())
+	assert.True(t, start.Equal(archived.Start))
+	assert.Equal(t, 4*time.Second, archived.Duration)
+	assert.True(t, start.Add(4*time.Second).Equal(archived.End))
+	assert.Equal(t, 1.050, archived.OG)
+	assert.Equal(t, 1.012, archived.FG)
+
+	dps, err := h.SessionDataPoints(archived.Id)
+	assert.NoError(t, err)
+	assert.Len(t, dps, 4)
+	assert.Equal(t, 3, dps[2].Step)
+	assert.True(t, math.IsNaN(dps[0].SG))
+	dps, err = h.SessionDataPoints(sessions[0].Id)
+	assert.NoError(t, err)
+	assert.Empty(t, dps)
+}
+
+func TestSaveConfigOnlyTouchesItsOwnBrew(t *testing.T) {
+	h := newTestHub(t)
+	old, err := h.latestConfig()
+	assert.NoError(t, err)
+	old.Stage = config.DONE
+	h.Conf = old
+	h.saveConfig()
+	assert.NoError(t, h.NewBrew("Second"))
+
+	// a late update of the archived brew must not leak into the new one
+	old.TargetTemperature = 30
+	h.Conf = old
+	h.saveConfig()
+
+	current, err := h.latestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "Second", current.Name)
+	assert.NotEqual(t, 30., current.TargetTemperature)
+}
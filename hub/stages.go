@@ -0,0 +1,152 @@
+package hub
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+)
+
+// Transition moves the current brew from one stage to another.
+type Transition string
+
+const (
+	// Prepare starts cooling the wort down to the pitching temperature.
+	Prepare Transition = "prepare"
+	// Pitch starts fermentation.
+	Pitch Transition = "pitch"
+	// Finish ends fermentation; the outputs are turned off and nothing more
+	// is recorded.
+	Finish Transition = "finish"
+	// Abort ends the brew early, during preparation or fermentation.
+	Abort Transition = "abort"
+	// Reset archives a finished brew and returns to setup for a new batch.
+	Reset Transition = "reset"
+)
+
+var transitions = map[Transition]struct {
+	from []config.Stage
+	to   config.Stage
+}{
+	Prepare: {[]config.Stage{config.SETUP}, config.PREPARATION},
+	Pitch:   {[]config.Stage{config.PREPARATION}, config.BREWING},
+	Finish:  {[]config.Stage{config.BREWING}, config.DONE},
+	Abort:   {[]config.Stage{config.PREPARATION, config.BREWING}, config.DONE},
+	Reset:   {[]config.Stage{config.DONE}, config.SETUP},
+}
+
+// ErrTransition is returned for a transition that is not allowed from the
+// current stage.
+var ErrTransition = errors.New("transition not allowed")
+
+// ErrUnknownTransition is returned for a transition that doesn't exist.
+var ErrUnknownTransition = errors.New("unknown transition")
+
+// StageEvent records a transition of a brew.
+type StageEvent struct {
+	Id         int
+	Time       time.Time
+	Transition Transition
+	From       config.Stage
+	To         config.Stage
+}
+
+// Allowed reports whether t can be applied to a brew in stage.
+func (t Transition) Allowed(stage config.Stage) bool {
+	for _, from := range transitions[t].from {
+		if from == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition applies t to the current brew, publishes the new
+// configuration and records it in the stage events. The stage changes in
+// memory first, so the outputs follow it while storage is degraded; the
+// configuration and the event are then stored like any configuration
+// change, retried by the hub until they are. Reset starts a new brew with
+// a default name, see NewBrew.
+func (h *Hub) Transition(t Transition) error {
+	if _, ok := transitions[t]; !ok {
+		return fmt.Errorf("%w %q", ErrUnknownTransition, t)
+	}
+	if t == Reset {
+		return h.NewBrew("")
+	}
+
+	h.dbLock.Lock()
+	conf, err := h.transition(t, time.Now())
+	h.dbLock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	h.Configuration.Send(conf)
+	if t == Pitch {
+		h.ScreenChange.Send(config.BREWING_SCREEN)
+	}
+	if err := h.saveConfig(); err != nil {
+		log.Printf("Brew %d: %v kept in memory until it can be stored: %v\n", conf.Id, t, err)
+	}
+	return nil
+}
+
+// transition applies t to the configuration in memory and queues its stage
+// event. The caller holds dbLock.
+func (h *Hub) transition(t Transition, now time.Time) (*config.Configuration, error) {
+	conf, err := h.current()
+	if err != nil {
+		return nil, h.report(err)
+	}
+	from := conf.Stage
+	if !t.Allowed(from) {
+		return nil, fmt.Errorf("%w: %v from %v", ErrTransition, t, from)
+	}
+	conf.Stage = transitions[t].to
+	switch t {
+	case Prepare:
+		conf.BrewingStartTime = now
+	case Pitch:
+		conf.PitchTime = now
+		// the measured OG replaces the planned one; SetOG overrides it
+		if og, ok := h.pitchOG(now); ok {
+			log.Printf("Brew %d: OG %.4f at pitch time\n", conf.Id, og)
+			conf.OG = og
+		}
+	}
+
+	h.Conf = conf
+	h.pendingEvents = append(h.pendingEvents, StageEvent{conf.Id, now, t, from, conf.Stage})
+	log.Printf("Brew %d: %v, %v -> %v\n", conf.Id, t, from, conf.Stage)
+	return conf, nil
+}
+
+func insertStageEvent(tx *sql.Tx, e StageEvent) error {
+	_, err := tx.Exec(query("insertStageEvent.sql"), e.Id, e.Time, string(e.Transition), e.From, e.To)
+	return err
+}
+
+// StageEvents lists the transitions of a brew in order.
+func (h *Hub) StageEvents(id int) ([]StageEvent, error) {
+	rows, err := h.db.Query(query("selectStageEvents.sql"), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []StageEvent
+	for rows.Next() {
+		var e StageEvent
+		var t string
+		if err := rows.Scan(&e.Id, &e.Time, &t, &e.From, &e.To); err != nil {
+			return nil, err
+		}
+		e.Transition = Transition(t)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,129 @@
+package hub
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/config"
+)
+
+func TestStageLifecycle(t *testing.T) {
+	h := newTestHub(t)
+	configs := h.Configuration.Subscribe()
+	defer configs.Close()
+	screens := h.ScreenChange.Subscribe()
+	defer screens.Close()
+
+	assert.NoError(t, h.Transition(Prepare))
+	conf := <-configs.C
+	assert.Equal(t, config.PREPARATION, conf.Stage)
+	assert.False(t, conf.BrewingStartTime.IsZero())
+
+	assert.NoError(t, h.Transition(Pitch))
+	conf = <-configs.C
+	assert.Equal(t, config.BREWING, conf.Stage)
+	assert.False(t, conf.PitchTime.IsZero())
+	assert.Equal(t, config.BREWING_SCREEN, <-screens.C)
+
+	assert.NoError(t, h.Transition(Finish))
+	conf = <-configs.C
+	assert.Equal(t, config.DONE, conf.Stage)
+	first := conf.Id
+
+	assert.NoError(t, h.Transition(Reset))
+	conf = <-configs.C
+	assert.Equal(t, config.SETUP, conf.Stage)
+	assert.NotEqual(t, first, conf.Id)
+	assert.NotEmpty(t, conf.Name)
+	assert.Equal(t, config.SETUP_SCREEN, <-screens.C)
+
+	events, err := h.StageEvents(first)
+	assert.NoError(t, err)
+	var got []Transition
+	for _, e := range events {
+		got = append(got, e.Transition)
+	}
+	assert.Equal(t, []Transition{Prepare, Pitch, Finish}, got)
+	assert.Equal(t, config.BREWING, events[2].From)
+	assert.Equal(t, config.DONE, events[2].To)
+
+	events, err = h.StageEvents(conf.Id)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, Reset, events[0].Transition)
+	assert.Equal(t, config.DONE, events[0].From)
+}
+
+func TestAbort(t *testing.T) {
+	h := newTestHub(t)
+	configs := h.Configuration.Subscribe()
+	defer configs.Close()
+
+	assert.NoError(t, h.Transition(Prepare))
+	<-configs.C
+	assert.NoError(t, h.Transition(Abort))
+	conf := <-configs.C
+	assert.Equal(t, config.DONE, conf.Stage)
+
+	events, err := h.StageEvents(conf.Id)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, config.PREPARATION, events[1].From)
+}
+
+func TestTransitionNotAllowed(t *testing.T) {
+	h := newTestHub(t)
+
+	for _, tr := range []Transition{Pitch, Finish, Abort, Reset} {
+		err := h.Transition(tr)
+		assert.True(t, errors.Is(err, ErrTransition), "%v: %v", tr, err)
+	}
+	assert.True(t, errors.Is(h.Transition("explode"), ErrUnknownTransition))
+
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, config.SETUP, conf.Stage)
+	events, err := h.StageEvents(conf.Id)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestTransitionWhileStorageIsDegraded(t *testing.T) {
+	h := newTestHub(t)
+	h.db.SetMaxOpenConns(1)
+	configs := h.Configuration.Subscribe()
+	defer configs.Close()
+
+	_, err := h.db.Exec("pragma query_only = 1")
+	assert.NoError(t, err)
+	assert.NoError(t, h.Transition(Prepare))
+	assert.Equal(t, config.PREPARATION, (<-configs.C).Stage)
+	assert.True(t, h.StorageStatus().Degraded)
+	assert.True(t, h.dirty())
+	// the stage follows the transition in memory
+	assert.NoError(t, h.Transition(Pitch))
+	assert.Equal(t, config.BREWING, (<-configs.C).Stage)
+
+	_, err = h.db.Exec("pragma query_only = 0")
+	assert.NoError(t, err)
+	assert.NoError(t, h.saveConfig())
+	assert.False(t, h.StorageStatus().Degraded)
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, config.BREWING, conf.Stage)
+	events, err := h.StageEvents(conf.Id)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, Prepare, events[0].Transition)
+		assert.Equal(t, Pitch, events[1].Transition)
+	}
+}
+
+func TestStageScreen(t *testing.T) {
+	assert.Equal(t, config.SETUP_SCREEN, stageScreen(config.SETUP))
+	assert.Equal(t, config.PREPARATION_SCREEN, stageScreen(config.PREPARATION))
+	assert.Equal(t, config.BREWING_SCREEN, stageScreen(config.BREWING))
+	// a finished brew is shown where it ended after a restart
+	assert.Equal(t, config.BREWING_SCREEN, stageScreen(config.DONE))
+}
@@ -0,0 +1,115 @@
+package hub
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// storageRetryPeriod is how often a configuration that couldn't be saved is
+// written again.
+const storageRetryPeriod = time.Second * 30
+
+// StorageStatus reports whether the database accepts writes. While it is
+// degraded the brew keeps running from memory and the failed writes are
+// retried.
+type StorageStatus struct {
+	Degraded bool
+	Err      string
+	Since    time.Time
+}
+
+type storageHealth struct {
+	sync.Mutex
+	status StorageStatus
+	// memory is set while the hub runs from an in-memory database; storage
+	// stays degraded however its writes go.
+	memory bool
+}
+
+// StorageStatus returns the current storage status.
+func (h *Hub) StorageStatus() StorageStatus {
+	h.storage.Lock()
+	defer h.storage.Unlock()
+	return h.storage.status
+}
+
+// report records the outcome of a write and publishes the storage status
+// and raises or clears the storage alarm when it changes. It returns err.
+func (h *Hub) report(err error) error {
+	h.storage.Lock()
+	old := h.storage.status
+	switch {
+	case err != nil && !old.Degraded:
+		h.storage.status = StorageStatus{true, err.Error(), time.Now()}
+		log.Printf("Storage degraded: %v\n", err)
+	case err != nil:
+		h.storage.status.Err = err.Error()
+	case h.storage.memory:
+	case old.Degraded:
+		h.storage.status = StorageStatus{false, "", time.Now()}
+		log.Printf("Storage recovered after %v\n", time.Since(old.Since))
+	}
+	status := h.storage.status
+	h.storage.Unlock()
+
+	if status.Degraded != old.Degraded {
+		if h.Storage != nil {
+			h.Storage.Send(status)
+		}
+		if status.Degraded {
+			h.Raise(StorageAlarm, "Storage degraded: "+status.Err)
+		} else {
+			h.Clear(StorageAlarm)
+		}
+	}
+	return err
+}
+
+// runFromMemory marks storage degraded by err until the database file can
+// be opened again.
+func (h *Hub) runFromMemory(err error) {
+	h.storage.Lock()
+	h.storage.memory = true
+	h.storage.Unlock()
+	h.report(err)
+}
+
+func (h *Hub) inMemory() bool {
+	h.storage.Lock()
+	defer h.storage.Unlock()
+	return h.storage.memory
+}
+
+// reopen tries the database file again while the hub runs from memory. Once
+// it opens, the hub switches to it and loads the brew stored there; what
+// was recorded in memory is dropped.
+func (h *Hub) reopen() {
+	db, err := openMigrated(h.file)
+	if err != nil {
+		h.report(err)
+		return
+	}
+	h.dbLock.Lock()
+	old := h.db
+	h.db = db
+	conf, err := h.latestConfig()
+	if err != nil {
+		h.db = old
+		h.dbLock.Unlock()
+		db.Close()
+		h.report(err)
+		return
+	}
+	h.Conf, h.configDirty, h.pendingEvents = conf, false, nil
+	h.dbLock.Unlock()
+	old.Close()
+
+	h.storage.Lock()
+	h.storage.memory = false
+	h.storage.Unlock()
+	h.report(nil)
+	log.Printf("Database %v opened, leaving memory\n", h.file)
+	h.Configuration.Send(conf)
+	h.ScreenChange.Send(stageScreen(conf.Stage))
+}
@@ -0,0 +1,73 @@
+package hub
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageDegradesAndRecovers(t *testing.T) {
+	h := newTestHub(t)
+	// a single connection, so the pragma below applies to every write
+	h.db.SetMaxOpenConns(1)
+	conf, err := h.latestConfig()
+	assert.NoError(t, err)
+	h.Conf = conf
+	statuses := h.Storage.Subscribe()
+	defer statuses.Close()
+
+	_, err = h.db.Exec("pragma query_only = 1")
+	assert.NoError(t, err)
+	h.Conf.TargetTemperature = 12
+	assert.Error(t, h.saveConfig())
+	assert.Error(t, h.SaveDataPoints([]*DataPoint{{Id: conf.Id, Step: 1, TargetTemp: 12, CurrentTemp: 200, SG: math.NaN(), Span: 1}}))
+	status := <-statuses.C
+	assert.True(t, status.Degraded)
+	assert.NotEmpty(t, status.Err)
+	assert.Equal(t, status, h.StorageStatus())
+	if assert.Len(t, h.Alarms(), 1) {
+		assert.Equal(t, StorageAlarm, h.Alarms()[0].Rule)
+	}
+
+	_, err = h.db.Exec("pragma query_only = 0")
+	assert.NoError(t, err)
+	assert.NoError(t, h.saveConfig())
+	status = <-statuses.C
+	assert.False(t, status.Degraded)
+	assert.False(t, h.StorageStatus().Degraded)
+	assert.Empty(t, h.Alarms())
+
+	saved, err := h.latestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 12., saved.TargetTemperature)
+}
+
+func TestRunFromMemoryUntilTheFileOpens(t *testing.T) {
+	// a directory in place of the database can't be opened
+	file := filepath.Join(t.TempDir(), "alcobot.db")
+	assert.NoError(t, os.Mkdir(file, 0755))
+	h, err := New(file)
+	assert.NoError(t, err)
+	defer h.Close()
+	assert.True(t, h.StorageStatus().Degraded)
+
+	// writes to memory don't hide that nothing is kept
+	h.Conf, err = h.latestConfig()
+	assert.NoError(t, err)
+	assert.NoError(t, h.saveConfig())
+	assert.True(t, h.StorageStatus().Degraded)
+	h.reopen()
+	assert.True(t, h.inMemory())
+
+	assert.NoError(t, os.Remove(file))
+	h.reopen()
+	assert.False(t, h.inMemory())
+	assert.False(t, h.StorageStatus().Degraded)
+	assert.Empty(t, h.Alarms())
+	assert.NoError(t, h.saveConfig())
+	_, err = os.Stat(file)
+	assert.NoError(t, err)
+}
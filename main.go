@@ -10,67 +10,176 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/zlowred/goqt/ui"
 
+	"github.com/zlowred/alcobot/alarm"
 	"github.com/zlowred/alcobot/backlight"
+	"github.com/zlowred/alcobot/backup"
+	"github.com/zlowred/alcobot/cli"
+	"github.com/zlowred/alcobot/completion"
+	"github.com/zlowred/alcobot/control"
 	"github.com/zlowred/alcobot/flightrecorder"
+	"github.com/zlowred/alcobot/gravity"
 	"github.com/zlowred/alcobot/gui"
 	"github.com/zlowred/alcobot/hal"
 	"github.com/zlowred/alcobot/heatpump"
 	"github.com/zlowred/alcobot/hub"
 	"github.com/zlowred/alcobot/pid"
+	"github.com/zlowred/alcobot/schedule"
 	"github.com/zlowred/alcobot/service"
+	"github.com/zlowred/alcobot/supervisor"
+	"github.com/zlowred/alcobot/uploader"
 )
 
+// shutdownTimeout is how long each shutdown phase may take before the
+// components still running are reported and left behind.
+const shutdownTimeout = time.Second * 5
+
+// hubRetryPeriod is how often the hub is started again when it can't be.
+const hubRetryPeriod = time.Second * 30
+
 func main() {
-	if h, err := hub.New(); err != nil {
-		panic(err)
+	opts, err := cli.Parse(os.Args[1:], os.Getenv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	opts.SetupLog()
+
+	if opts.Command != cli.Run {
+		if err := cli.Maintain(opts, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%v: %v\n", opts.Command, err)
+			os.Exit(1)
+		}
+		return
+	}
+	run(opts)
+}
+
+func run(opts *cli.Options) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	sup := supervisor.New(ctx)
+
+	if err := os.MkdirAll(opts.DataDir, 0755); err != nil {
+		log.Printf("Can't create data directory %v: %v\n", opts.DataDir, err)
+	}
+	// the hub falls back to an in-memory database, so this only fails when
+	// there is no storage at all; nothing is driven until it starts
+	h, err := hub.New(opts.DBFile())
+	for err != nil {
+		log.Printf("Can't start the hub, retrying in %v: %v\n", hubRetryPeriod, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(hubRetryPeriod):
+		}
+		h, err = hub.New(opts.DBFile())
+	}
+	sup.Go(supervisor.Storage, "hub", h.Run)
+
+	// without the PID or the hardware the bot keeps running with the
+	// outputs off and an alarm raised
+	p, err := pid.New(100, 0.35, 0.3, -255, 255, h)
+	if err != nil {
+		log.Printf("Invalid PID settings, running with the outputs off: %v\n", err)
+		h.Raise(hub.ControlAlarm, "Temperature control off: "+err.Error())
 	} else {
-		if pid, err := pid.New(100, 0.35, 0.3, -255, 255, h); err != nil {
+		sup.Go(supervisor.Control, "pid", p.Run)
+	}
+	sup.Go(supervisor.Control, "heatpump", heatpump.New(h).Run)
+	sup.Go(supervisor.Control, "schedule", schedule.New(h).Run)
+	var hw *hal.Hal
+	if opts.Hal == cli.SimHal {
+		hw = hal.NewSimulated(h, opts.Plant)
+	} else if hw, err = hal.New(h); err != nil {
+		log.Printf("Can't open the hardware, running without it: %v\n", err)
+		h.Raise(hub.HardwareAlarm, "No hardware: "+err.Error())
+	}
+	if hw != nil {
+		sup.Go(supervisor.Hardware, "hal", hw.Run)
+	}
+	sup.Go(supervisor.Recording, "flightrecorder", flightrecorder.New(h, opts.FlushInterval).Run)
+	sup.Go(supervisor.Recording, "gravity", gravity.NewTracker(h).Run)
+	sup.Go(supervisor.Recording, "completion", completion.New(h, opts.Completion).Run)
+	sup.Go(supervisor.Recording, "alarm", alarm.New(h, opts.Alarms).Run)
+	sup.Go(supervisor.Recording, "control", control.New(h).Run)
+	if opts.BackupInterval > 0 {
+		sup.Go(supervisor.Recording, "backup", backup.New(h, opts.BackupConfig()).Run)
+	}
+	sup.Go(supervisor.Interface, "backlight", backlight.New(h).Run)
+	if opts.UploadURL != "" {
+		conf, err := opts.UploadConfig()
+		if err != nil {
+			log.Fatalf("Can't start the uploader: %v\n", err)
+		}
+		sup.Go(supervisor.Interface, "uploader", uploader.New(h, conf).Run)
+	}
+
+	service.NewDebugPage(h)
+	service.NewStageService(h)
+	service.NewExportService(h)
+	service.NewRecipeService(h)
+	service.NewBackupService(h)
+	service.NewOGService(h)
+	service.NewAlarmService(h)
+	service.NewDriftService(h)
+	service.NewCalibrationService(h)
+	go func() {
+		time.Sleep(time.Second)
+		if p != nil {
+			p.Enable()
+		}
+	}()
+
+	if opts.Headless {
+		serve(sup, opts)
+		wait(sup)
+		return
+	}
+
+	ui.Run(func() {
+		w, err := gui.NewRootScreen(h, hw, sup.Stop)
+		if err != nil {
 			panic(err)
-		} else {
-			hl := hal.New(h)
-			go func() {
-				sigchan := make(chan os.Signal, 10)
-				signal.Notify(sigchan)
-				<-sigchan
-				for i := 0; i < 10; i++ {
-					hl.ResetI2C()
-				}
-				h.Quit <- true
-			}()
-			defer func() {
-				for i := 0; i < 10; i++ {
-					hl.ResetI2C()
-				}
-			}()
-			heatpump.New(h)
-			backlight.New(h)
-			flightrecorder.New(h)
-
-			ui.Run(func() {
-				w, err := gui.NewRootScreen(h)
-				if err != nil {
-					panic(err)
-				}
-
-				w.Show()
-
-				go func() {
-					<-h.Quit
-					w.Close()
-				}()
-				go func() {
-					time.Sleep(time.Second)
-					pid.Enable()
-					service.NewScreenshoter(w.QWidget)
-				}()
-			})
 		}
 
+		w.Show()
+		sup.Go(supervisor.Interface, "gui", w.Run)
+
+		service.NewScreenshoter(w.QWidget)
+		serve(sup, opts)
+
+		go func() {
+			wait(sup)
+			ui.Async(func() {
+				w.Close()
+			})
+		}()
+	})
+}
+
+// serve starts the HTTP server unless it is disabled.
+func serve(sup *supervisor.Supervisor, opts *cli.Options) {
+	if opts.Listen != "" {
+		sup.Go(supervisor.Interface, "http", service.NewServer(opts.Listen).Run)
+	}
+}
+
+func wait(sup *supervisor.Supervisor) {
+	if stuck := sup.Wait(shutdownTimeout); len(stuck) > 0 {
+		log.Printf("Components still running at exit: %v\n", stuck)
 	}
 }
@@ -1,6 +1,7 @@
 package pid
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -43,8 +44,6 @@ func New(kP float64, kI float64, kD float64, bottomLimit float64, topLimit float
 		return nil, err
 	}
 
-	go pid.loop()
-
 	return pid, nil
 }
 
@@ -109,16 +108,18 @@ func (p *PID) SetTunings(kP float64, kI float64, kD float64) error {
 	return nil
 }
 
-func (p *PID) loop() {
-	tempCh := hub.JoinInt16Group(p.hub.DsTemperatureFiltered)
+// Run publishes the controller output every second until ctx is done.
+func (p *PID) Run(ctx context.Context) {
+	tempCh := p.hub.DsTemperatureFiltered.JoinContext(ctx)
 	timer := time.NewTimer(time.Second * 1)
-	configCh := hub.JoinConfigGroup(p.hub.Configuration)
+	defer timer.Stop()
+	configCh := p.hub.Configuration.JoinContext(ctx)
 
 	for {
 		select {
 		case val := <-tempCh:
-			p.Input = conv.DsToC(val)
-		case <-p.hub.Quit:
+			p.Input = conv.DsToC(val.Value)
+		case <-ctx.Done():
 			return
 		case <-timer.C:
 			timer = time.NewTimer(time.Second * 1)
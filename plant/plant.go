@@ -0,0 +1,224 @@
+// Package plant identifies a first order plus dead time model of the
+// fermenter from a recorded brew, suggests PID gains for it and simulates
+// it for the emulated hardware.
+package plant
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+)
+
+const (
+	// sample is the period the data points are averaged over before the
+	// model is fitted; hourly buckets are too coarse and are left out.
+	sample = time.Minute
+	// minSamples is the number of samples a fit needs at least.
+	minSamples = 120
+	// DefaultMaxDeadTime is the longest dead time Identify tries.
+	DefaultMaxDeadTime = 2 * time.Hour
+)
+
+// Model is a first order plus dead time model of the fermenter: the
+// temperature settles Gain ºC per % of power above Ambient with the time
+// constant TimeConstant, DeadTime after the power changes.
+type Model struct {
+	Gain         float64
+	TimeConstant time.Duration
+	DeadTime     time.Duration
+	Ambient      float64
+}
+
+// DefaultModel is a fermenter of about 20 litres in a room at 21ºC, as
+// simulated without a model of the real one.
+var DefaultModel = Model{Gain: 0.15, TimeConstant: 5 * time.Hour, DeadTime: 10 * time.Minute, Ambient: 21}
+
+// Fit is a Model identified from a brew.
+type Fit struct {
+	Model
+	// Samples is the number of one minute samples fitted.
+	Samples int
+	// RMSE is the root mean square error in ºC of the temperature the model
+	// predicts a minute ahead.
+	RMSE float64
+}
+
+// point is the average temperature and power over a sample.
+type point struct {
+	temp  float64
+	power float64
+	n     int
+}
+
+// resample averages the data points up to minute buckets into samples,
+// indexed by minute. Missing samples have no readings.
+func resample(dps []*hub.DataPoint) []point {
+	var samples []point
+	for _, dp := range dps {
+		if dp.Span > int(sample/time.Second) || math.IsNaN(dp.CurrentTemp) || math.IsNaN(dp.Power) {
+			continue
+		}
+		// minute bucket m holds steps 60m+1 to 60(m+1)
+		m := (dp.Step - 1) / int(sample/time.Second)
+		for len(samples) <= m {
+			samples = append(samples, point{})
+		}
+		s := &samples[m]
+		s.temp += dp.CurrentTemp * conv.DsToC(1) * float64(dp.Span)
+		s.power += dp.Power * float64(dp.Span)
+		s.n += dp.Span
+	}
+	for i := range samples {
+		if s := &samples[i]; s.n > 0 {
+			s.temp /= float64(s.n)
+			s.power /= float64(s.n)
+		}
+	}
+	return samples
+}
+
+// Identify fits a Model to the fermenter temperature and power of the data
+// points of a brew, trying dead times up to maxDeadTime. The temperature
+// change over a sample is regressed on the temperature and the power the
+// dead time before by least squares, for every dead time, and the one that
+// fits best is kept. The power has to have changed for the gain to show.
+func Identify(dps []*hub.DataPoint, maxDeadTime time.Duration) (Fit, error) {
+	samples := resample(dps)
+	var mean float64
+	var n int
+	for _, s := range samples {
+		if s.n > 0 {
+			mean += s.temp
+			n++
+		}
+	}
+	if n < minSamples {
+		return Fit{}, fmt.Errorf("%d minutes of temperature and power recorded, %d needed", n, minSamples)
+	}
+	mean /= float64(n)
+
+	var best Fit
+	var bestX [3]float64
+	bestErr := math.Inf(1)
+	var err error
+	for d := 0; d <= int(maxDeadTime/sample); d++ {
+		var a [3][4]float64
+		rows := 0
+		for k := d; k+1 < len(samples); k++ {
+			if samples[k].n == 0 || samples[k+1].n == 0 || samples[k-d].n == 0 {
+				continue
+			}
+			x := [3]float64{samples[k].temp - mean, samples[k-d].power, 1}
+			y := samples[k+1].temp - samples[k].temp
+			for i := range x {
+				for j := range x {
+					a[i][j] += x[i] * x[j]
+				}
+				a[i][3] += x[i] * y
+			}
+			rows++
+		}
+		if rows < minSamples {
+			break
+		}
+		var x [3]float64
+		if x, err = solve(a); err != nil {
+			continue
+		}
+		var sse float64
+		for k := d; k+1 < len(samples); k++ {
+			if samples[k].n == 0 || samples[k+1].n == 0 || samples[k-d].n == 0 {
+				continue
+			}
+			e := samples[k+1].temp - samples[k].temp - x[0]*(samples[k].temp-mean) - x[1]*samples[k-d].power - x[2]
+			sse += e * e
+		}
+		if sse/float64(rows) < bestErr {
+			bestErr, bestX = sse/float64(rows), x
+			// the power is held over a sample, which delays it by half a
+			// sample on average
+			best = Fit{Model: Model{DeadTime: time.Duration(d)*sample + sample/2}, Samples: rows, RMSE: math.Sqrt(sse / float64(rows))}
+		}
+	}
+	if math.IsInf(bestErr, 1) {
+		if err != nil {
+			return Fit{}, err
+		}
+		return Fit{}, errors.New("not enough consecutive minutes of temperature and power recorded")
+	}
+
+	// ΔT = (a-1)(T-mean) + b·u + c for T[k+1] = a·T[k] + b·u[k-d] + c'
+	decay, b, c := -bestX[0], bestX[1], bestX[2]
+	if decay <= 0 || decay >= 1 {
+		return Fit{}, errors.New("the fermenter temperature doesn't settle, the model doesn't fit")
+	}
+	if b <= 0 {
+		return Fit{}, errors.New("the fermenter temperature doesn't follow the power, the model doesn't fit")
+	}
+	best.TimeConstant = time.Duration(-float64(sample) / math.Log(1-decay))
+	best.Gain = b / decay
+	best.Ambient = mean + c/decay
+	return best, nil
+}
+
+func solve(a [3][4]float64) ([3]float64, error) {
+	for col := 0; col < 3; col++ {
+		pivot := col
+		for r := col + 1; r < 3; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return [3]float64{}, errors.New("the temperature and power never changed, there is nothing to identify")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for r := col + 1; r < 3; r++ {
+			f := a[r][col] / a[col][col]
+			for k := col; k <= 3; k++ {
+				a[r][k] -= f * a[col][k]
+			}
+		}
+	}
+	var x [3]float64
+	for r := 2; r >= 0; r-- {
+		x[r] = a[r][3]
+		for k := r + 1; k < 3; k++ {
+			x[r] -= a[r][k] * x[k]
+		}
+		x[r] /= a[r][r]
+	}
+	return x, nil
+}
+
+// Tuning is a set of PID gains in the units of the pid package: output
+// (-255 to 255) per ºC, per ºC·s and s per ºC.
+type Tuning struct {
+	Rule string
+	KP   float64
+	KI   float64
+	KD   float64
+}
+
+// Tune suggests PID gains for the model after the Ziegler-Nichols and
+// Cohen-Coon process reaction curve rules and the SIMC rule, a PI
+// controller that trades speed for robustness.
+func (m Model) Tune() []Tuning {
+	// the gain per unit of output rather than per % of power
+	k := m.Gain / 2.55
+	tau, theta := m.TimeConstant.Seconds(), m.DeadTime.Seconds()
+	pid := func(rule string, kp, ti, td float64) Tuning {
+		return Tuning{Rule: rule, KP: kp, KI: kp / ti, KD: kp * td}
+	}
+	r := theta / tau
+	return []Tuning{
+		pid("Ziegler-Nichols", 1.2*tau/(k*theta), 2*theta, theta/2),
+		pid("Cohen-Coon", tau/(k*theta)*(4./3+r/4), theta*(32+6*r)/(13+8*r), 4*theta/(11+2*r)),
+		// the closed loop time constant is the dead time
+		pid("SIMC", tau/(k*2*theta), math.Min(tau, 8*theta), 0),
+	}
+}
@@ -0,0 +1,98 @@
+package plant
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+)
+
+// record simulates m at 1 Hz for the given powers, each held for hold, as
+// the flight recorder stores it.
+func record(m Model, hold time.Duration, powers ...float64) []*hub.DataPoint {
+	s := NewSimulator(m)
+	var dps []*hub.DataPoint
+	for _, p := range powers {
+		for i := 0; i < int(hold/time.Second); i++ {
+			temp := float64(conv.CtoDs(s.Temperature()))
+			s.Step(p, time.Second)
+			dps = append(dps, &hub.DataPoint{Id: 1, Step: len(dps) + 1, TargetTemp: 20, CurrentTemp: temp, SG: math.NaN(), PID: p, Power: p, Span: 1})
+		}
+	}
+	return dps
+}
+
+func TestIdentify(t *testing.T) {
+	dps := record(DefaultModel, 3*time.Hour, 0, -40, -40, 20, -60, 0, 30, -30, -10, -50, 10, -20)
+	fit, err := Identify(dps, DefaultMaxDeadTime)
+	assert.NoError(t, err)
+	assert.InDelta(t, DefaultModel.Gain, fit.Gain, 0.01)
+	assert.InDelta(t, DefaultModel.TimeConstant.Hours(), fit.TimeConstant.Hours(), 0.25)
+	assert.InDelta(t, DefaultModel.DeadTime.Minutes(), fit.DeadTime.Minutes(), 1.5)
+	assert.InDelta(t, DefaultModel.Ambient, fit.Ambient, 0.1)
+	// about the resolution of the DS18B20 over a minute
+	assert.Less(t, fit.RMSE, 0.03)
+	assert.Equal(t, 12*3*60-11, fit.Samples)
+
+	// rolled up minutes fit the same, hours are left out
+	var rolled []*hub.DataPoint
+	for i := 0; i < len(dps); i += 60 {
+		m := *dps[i+59]
+		m.CurrentTemp, m.Span = 0, 60
+		for _, dp := range dps[i : i+60] {
+			m.CurrentTemp += dp.CurrentTemp / 60
+		}
+		rolled = append(rolled, &m)
+	}
+	rolled = append([]*hub.DataPoint{{Id: 1, Step: 0, CurrentTemp: 0, Power: 100, Span: 3600}}, rolled...)
+	minutes, err := Identify(rolled, DefaultMaxDeadTime)
+	assert.NoError(t, err)
+	assert.InDelta(t, fit.Gain, minutes.Gain, 1e-9)
+	assert.Equal(t, fit.DeadTime, minutes.DeadTime)
+}
+
+func TestIdentifyNeedsExcitation(t *testing.T) {
+	_, err := Identify(record(DefaultModel, time.Hour, 0), DefaultMaxDeadTime)
+	assert.Error(t, err, "too short")
+	_, err = Identify(record(DefaultModel, 12*time.Hour, 0), DefaultMaxDeadTime)
+	assert.Error(t, err, "the power never changed")
+	_, err = Identify(nil, DefaultMaxDeadTime)
+	assert.Error(t, err)
+}
+
+func TestTune(t *testing.T) {
+	m := Model{Gain: 0.255, TimeConstant: 100 * time.Minute, DeadTime: 10 * time.Minute}
+	tunings := m.Tune()
+	assert.Len(t, tunings, 3)
+
+	zn := tunings[0]
+	assert.Equal(t, "Ziegler-Nichols", zn.Rule)
+	assert.InDelta(t, 120, zn.KP, 1e-9)
+	assert.InDelta(t, 120./1200, zn.KI, 1e-9)
+	assert.InDelta(t, 120.*300, zn.KD, 1e-9)
+
+	simc := tunings[2]
+	assert.InDelta(t, 50, simc.KP, 1e-9)
+	assert.InDelta(t, 50./4800, simc.KI, 1e-9)
+	assert.Zero(t, simc.KD)
+	for _, tuning := range tunings {
+		assert.Greater(t, tuning.KP, 0.)
+		assert.Greater(t, tuning.KI, 0.)
+	}
+}
+
+func TestSimulator(t *testing.T) {
+	s := NewSimulator(Model{Gain: 0.1, TimeConstant: time.Hour, DeadTime: time.Minute, Ambient: 20})
+	for i := 0; i < 60; i++ {
+		assert.Equal(t, 20., s.Step(50, time.Second), "dead time")
+	}
+	s.Step(50, time.Hour)
+	assert.InDelta(t, 20+5*(1-math.Exp(-1)), s.Temperature(), 1e-9)
+	for i := 0; i < 100; i++ {
+		s.Step(0, time.Hour)
+	}
+	assert.InDelta(t, 20, s.Temperature(), 1e-9)
+}
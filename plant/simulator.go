@@ -0,0 +1,69 @@
+package plant
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+)
+
+// timedPower is a power that starts to act at a time into the simulation.
+type timedPower struct {
+	at    time.Duration
+	power float64
+}
+
+// Simulator is the fermenter temperature a Model predicts for the power it
+// is driven with, starting at the ambient temperature.
+type Simulator struct {
+	model   Model
+	temp    float64
+	elapsed time.Duration
+	pending []timedPower
+	acting  float64
+}
+
+func NewSimulator(m Model) *Simulator {
+	return &Simulator{model: m, temp: m.Ambient}
+}
+
+// Step drives the fermenter with power, in % of the heat pump, for dt and
+// returns its temperature then.
+func (s *Simulator) Step(power float64, dt time.Duration) float64 {
+	s.pending = append(s.pending, timedPower{s.elapsed + s.model.DeadTime, power})
+	for len(s.pending) > 0 && s.pending[0].at <= s.elapsed {
+		s.acting = s.pending[0].power
+		s.pending = s.pending[1:]
+	}
+	s.elapsed += dt
+	settled := s.model.Ambient + s.model.Gain*s.acting
+	s.temp = settled + (s.temp-settled)*math.Exp(-dt.Seconds()/s.model.TimeConstant.Seconds())
+	return s.temp
+}
+
+func (s *Simulator) Temperature() float64 {
+	return s.temp
+}
+
+// Run steps the simulation every second with the power the heat pump
+// drives and passes the temperature to set as a DS18B20 reading until ctx
+// is done.
+func (s *Simulator) Run(ctx context.Context, h *hub.Hub, set func(raw int16)) {
+	powerCh := h.AdjustedPidOutput.JoinContext(ctx)
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	power := 0.
+	set(conv.CtoDs(s.temp))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case x := <-powerCh:
+			power = x / 2.55
+		case <-t.C:
+			set(conv.CtoDs(s.Step(power, time.Second)))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Package schedule follows the fermentation schedule of the current brew.
+package schedule
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/hub"
+)
+
+// checkInterval is how often the runner looks for the next step.
+const checkInterval = time.Minute
+
+type Runner struct {
+	hub     *hub.Hub
+	conf    *config.Configuration
+	steps   []hub.ScheduleStep
+	applied int
+}
+
+func New(h *hub.Hub) *Runner {
+	return &Runner{hub: h, applied: -1}
+}
+
+// Run sets the target temperature of every schedule step when fermentation
+// reaches it, counting from the pitch time, until ctx is done. The target
+// is only changed when a step starts, so adjusting it by hand holds until
+// the next step. A step reached while the bot was off is not applied.
+func (r *Runner) Run(ctx context.Context) {
+	configCh := r.hub.Configuration.JoinContext(ctx)
+	t := time.NewTicker(checkInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case x := <-configCh:
+			if r.conf == nil || r.conf.Id != x.Id {
+				r.applied = -1
+			}
+			// the schedule is reloaded on every change, as a step may
+			// have been ended early
+			steps, err := r.hub.Schedule(x.Id)
+			if err != nil {
+				log.Printf("Can't load the schedule of brew %d: %v\n", x.Id, err)
+			}
+			r.steps = steps
+			r.conf = x
+			r.check(time.Now())
+		case now := <-t.C:
+			r.check(now)
+		}
+	}
+}
+
+func (r *Runner) check(now time.Time) {
+	if r.conf == nil || r.conf.Stage != config.BREWING || len(r.steps) == 0 {
+		return
+	}
+	i := Current(r.steps, now.Sub(r.conf.PitchTime))
+	if i == r.applied {
+		return
+	}
+	if r.applied < 0 {
+		// the step in progress when the brew was loaded was applied before
+		r.applied = i
+		return
+	}
+	r.applied = i
+	step := r.steps[i]
+	log.Printf("Brew %d: schedule step %d %v, target %.1fºC\n", r.conf.Id, i+1, step.Name, step.Temperature)
+	conf := *r.conf
+	conf.TargetTemperature = step.Temperature
+	r.conf = &conf
+	r.hub.Configuration.Send(&conf)
+}
+
+// Current returns the index of the step in progress elapsed after the
+// start of the schedule, see hub.CurrentStep.
+func Current(steps []hub.ScheduleStep, elapsed time.Duration) int {
+	return hub.CurrentStep(steps, elapsed)
+}
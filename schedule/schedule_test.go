@@ -0,0 +1,53 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/bus"
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/hub"
+)
+
+const day = 24 * time.Hour
+
+var steps = []hub.ScheduleStep{
+	{Name: "Primary", Temperature: 10, Duration: 10 * day},
+	{Name: "Diacetyl rest", Temperature: 16, Duration: 2 * day},
+	{Name: "Lagering", Temperature: 1, Duration: 0},
+	{Name: "Never", Temperature: 20, Duration: day},
+}
+
+func TestCurrent(t *testing.T) {
+	assert.Equal(t, 0, Current(steps, 0))
+	assert.Equal(t, 0, Current(steps, 10*day-time.Second))
+	assert.Equal(t, 1, Current(steps, 10*day))
+	assert.Equal(t, 2, Current(steps, 12*day))
+	assert.Equal(t, 2, Current(steps, 100*day))
+	assert.Equal(t, 1, Current(steps[:2], 100*day))
+}
+
+func TestCheckAppliesNextStep(t *testing.T) {
+	h := &hub.Hub{Configuration: bus.NewTopic[*config.Configuration]("config")}
+	configs := h.Configuration.Subscribe()
+	defer configs.Close()
+	pitch := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := New(h)
+	r.steps = steps
+	r.conf = &config.Configuration{Id: 1, Stage: config.BREWING, PitchTime: pitch, TargetTemperature: 11}
+
+	// the step in progress at start keeps the current target
+	r.check(pitch.Add(day))
+	r.check(pitch.Add(2 * day))
+	assert.Equal(t, 0, configs.Pending())
+
+	r.check(pitch.Add(10 * day))
+	conf := <-configs.C
+	assert.Equal(t, 16., conf.TargetTemperature)
+	assert.Equal(t, 1, conf.Id)
+
+	r.conf.Stage = config.DONE
+	r.check(pitch.Add(13 * day))
+	assert.Equal(t, 0, configs.Pending())
+}
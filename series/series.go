@@ -1,6 +1,10 @@
 package series
 
-import "sync"
+import (
+	"math"
+	"sync"
+	"time"
+)
 
 type Series struct {
 	size         int
@@ -11,6 +15,12 @@ type Series struct {
 	current      float64
 	currentLevel int
 
+	// start, second, sum and count bucket the values of PushAt by second.
+	start  time.Time
+	second int
+	sum    float64
+	count  int
+
 	mutex sync.Mutex
 }
 
@@ -19,6 +29,14 @@ func NewSeries(size int) *Series {
 	return res
 }
 
+// NewTimeSeries is a Series of a point per second from start, for values
+// pushed with PushAt.
+func NewTimeSeries(size int, start time.Time) *Series {
+	res := NewSeries(size)
+	res.start = start
+	return res
+}
+
 func (s *Series) Size() int {
 	if len(s.data) < s.size {
 		return len(s.data)
@@ -31,6 +49,61 @@ func (s *Series) Push(value float64) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	s.push(value)
+}
+
+// PushN pushes value n times, e.g. for a point that stands for n seconds.
+func (s *Series) PushN(value float64, n int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for ; n > 0; n-- {
+		s.push(value)
+	}
+}
+
+// PushAt adds value sampled at t. The values of a second are averaged into
+// its point and seconds without values are NaN, so series sampled at
+// different rates line up in time. Values for seconds already pushed are
+// dropped.
+func (s *Series) PushAt(t time.Time, value float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.advance(t) {
+		s.sum += value
+		s.count++
+	}
+}
+
+// Advance pushes the seconds before t, so the series reaches t even while
+// no values arrive.
+func (s *Series) Advance(t time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.advance(t)
+}
+
+// advance pushes the seconds before the one of t and reports whether t is
+// in the current second.
+func (s *Series) advance(t time.Time) bool {
+	second := int(t.Sub(s.start) / time.Second)
+	if t.Before(s.start) || second < s.second {
+		return false
+	}
+	for ; s.second < second; s.second++ {
+		if s.count == 0 {
+			s.push(math.NaN())
+			continue
+		}
+		s.push(s.sum / float64(s.count))
+		s.sum, s.count = 0, 0
+	}
+	return true
+}
+
+func (s *Series) push(value float64) {
 	s.current += value
 	s.currentLevel++
 	s.last = value
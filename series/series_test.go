@@ -1,7 +1,9 @@
 package series
 
 import (
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -48,3 +50,35 @@ func TestUnderflow(t *testing.T) {
 	}
 	assert.Equal(t, []float64{0., 1.}, series.Get())
 }
+
+func TestPushN(t *testing.T) {
+	pushed := NewSeries(4)
+	repeated := NewSeries(4)
+	for i := 0; i < 5; i++ {
+		pushed.Push(float64(i))
+		pushed.Push(float64(i))
+		pushed.Push(float64(i))
+		repeated.PushN(float64(i), 3)
+	}
+	repeated.PushN(5, 0)
+	assert.Equal(t, pushed.Get(), repeated.Get())
+}
+
+func TestPushAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+	series := NewTimeSeries(10, start)
+	series.PushAt(start.Add(-time.Second), 9)
+	series.PushAt(start, 1)
+	series.PushAt(start.Add(time.Millisecond*500), 3)
+	series.PushAt(start.Add(time.Second*3), 4)
+	series.PushAt(start.Add(time.Second*2), 9)
+	assert.Equal(t, 3, series.Size())
+	series.Advance(start.Add(time.Second * 5))
+	data := series.Get()
+	assert.Equal(t, 5, len(data))
+	assert.Equal(t, 2., data[0])
+	assert.True(t, math.IsNaN(data[1]))
+	assert.True(t, math.IsNaN(data[2]))
+	assert.Equal(t, 4., data[3])
+	assert.True(t, math.IsNaN(data[4]))
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/zlowred/alcobot/hub"
+)
+
+type AlarmService struct {
+	hub *hub.Hub
+}
+
+// NewAlarmService lists the raised alarms as JSON on /api/alarms, oldest
+// first.
+func NewAlarmService(h *hub.Hub) *AlarmService {
+	s := &AlarmService{h}
+	http.HandleFunc("/api/alarms", s.alarms)
+	return s
+}
+
+func (s *AlarmService) alarms(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(s.hub.Alarms()); err != nil {
+		log.Printf("Can't write the alarms to http: %v\n", err)
+	}
+}
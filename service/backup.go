@@ -0,0 +1,96 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zlowred/alcobot/hub"
+)
+
+// maxBackupSize bounds an uploaded backup.
+const maxBackupSize = 1 << 30
+
+type BackupService struct {
+	hub *hub.Hub
+}
+
+type restoreResult struct {
+	SchemaVersion int
+	Message       string
+}
+
+// NewBackupService serves a fresh backup of the database on /api/backup
+// and stages a backup posted to /api/restore, either as the body or as the
+// file field of a form, to replace the database at the next start.
+func NewBackupService(h *hub.Hub) *BackupService {
+	s := &BackupService{h}
+	http.HandleFunc("/api/backup", s.backup)
+	http.HandleFunc("/api/restore", s.restore)
+	return s
+}
+
+func (s *BackupService) backup(writer http.ResponseWriter, request *http.Request) {
+	dir, err := os.MkdirTemp("", "alcobot-backup")
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "alcobot.db")
+	if err := s.hub.Backup(file); err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	name := fmt.Sprintf("alcobot-%v.db", time.Now().Format("20060102-150405"))
+	writer.Header().Set("Content-Type", "application/vnd.sqlite3")
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	if _, err := io.Copy(writer, f); err != nil {
+		log.Printf("Can't write the backup to http: %v\n", err)
+	}
+}
+
+func (s *BackupService) restore(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writer.Header().Set("Allow", "POST")
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	request.Body = http.MaxBytesReader(writer, request.Body, maxBackupSize)
+
+	var body io.Reader = request.Body
+	if strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := request.FormFile("file")
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+	version, err := s.hub.StageRestore(body)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusAccepted)
+	result := restoreResult{version, "the backup replaces the database when the bot is restarted"}
+	if err := json.NewEncoder(writer).Encode(result); err != nil {
+		log.Printf("Can't write the restore result to http: %v\n", err)
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zlowred/alcobot/gravity"
+	"github.com/zlowred/alcobot/hub"
+)
+
+type CalibrationService struct {
+	hub *hub.Hub
+}
+
+// NewCalibrationService serves the SG calibration of a brew, the current one
+// unless the id parameter selects another, on /api/calibration with the
+// residuals of its points. Posting sg and optionally source to
+// /api/calibration/points records the current pressure as a point of the
+// current brew, deleting with the time of a point removes it. Posting to
+// /api/calibration/fit fits a model of degree 1 (the default) or 2 to the
+// points, and with history=true corrects the recorded SG to it.
+func NewCalibrationService(h *hub.Hub) *CalibrationService {
+	s := &CalibrationService{h}
+	http.HandleFunc("/api/calibration", s.calibration)
+	http.HandleFunc("/api/calibration/points", s.points)
+	http.HandleFunc("/api/calibration/fit", s.fit)
+	return s
+}
+
+func (s *CalibrationService) calibration(writer http.ResponseWriter, request *http.Request) {
+	id, ok := s.brew(writer, request)
+	if !ok {
+		return
+	}
+	s.write(writer, id)
+}
+
+func (s *CalibrationService) points(writer http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case http.MethodPost:
+		sg, err := strconv.ParseFloat(request.FormValue("sg"), 64)
+		if err != nil {
+			http.Error(writer, fmt.Sprintf("invalid sg %q", request.FormValue("sg")), http.StatusBadRequest)
+			return
+		}
+		source := request.FormValue("source")
+		if source == "" {
+			source = "hydrometer"
+		}
+		if _, err := s.hub.AddCalibrationPoint(sg, source); err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.write(writer, 0)
+	case http.MethodDelete:
+		id, ok := s.brew(writer, request)
+		if !ok {
+			return
+		}
+		t, err := time.Parse(time.RFC3339Nano, request.FormValue("time"))
+		if err != nil {
+			http.Error(writer, fmt.Sprintf("invalid time %q", request.FormValue("time")), http.StatusBadRequest)
+			return
+		}
+		if err := s.hub.DeleteCalibrationPoint(id, t); err != nil {
+			http.Error(writer, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.write(writer, id)
+	default:
+		writer.Header().Set("Allow", "POST, DELETE")
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *CalibrationService) fit(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writer.Header().Set("Allow", "POST")
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, ok := s.brew(writer, request)
+	if !ok {
+		return
+	}
+	degree := 1
+	if x := request.FormValue("degree"); x != "" {
+		var err error
+		if degree, err = strconv.Atoi(x); err != nil {
+			http.Error(writer, fmt.Sprintf("invalid degree %q", x), http.StatusBadRequest)
+			return
+		}
+	}
+	history := request.FormValue("history") == "true"
+	if _, err := gravity.Refit(s.hub, id, degree, history); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.write(writer, id)
+}
+
+func (s *CalibrationService) write(writer http.ResponseWriter, id int) {
+	c, err := gravity.LoadCalibration(s.hub, id)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusNotFound)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(c); err != nil {
+		log.Printf("Can't write the calibration to http: %v\n", err)
+	}
+}
+
+// brew is the brew selected by the id parameter, the current one by
+// default.
+func (s *CalibrationService) brew(writer http.ResponseWriter, request *http.Request) (int, bool) {
+	x := request.FormValue("id")
+	if x == "" {
+		conf, err := s.hub.Config()
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return 0, false
+		}
+		return conf.Id, true
+	}
+	id, err := strconv.Atoi(x)
+	if err != nil {
+		http.Error(writer, fmt.Sprintf("invalid brew id %q", x), http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/zlowred/alcobot/bus"
+	"github.com/zlowred/alcobot/hub"
+)
+
+var debugTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>alcobot hub</title>
+<meta http-equiv="refresh" content="2">
+<style>
+body { font-family: monospace; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #999; padding: 2px 6px; text-align: right; }
+td:first-child, th:first-child { text-align: left; }
+.blocking { background: #f99; }
+</style>
+</head>
+<body>
+<p>{{.Time.Format "15:04:05"}}, rates over the last {{.Window}}s</p>
+{{range .Alarms}}<p class="blocking">{{.Rule}} alarm since {{.Since.Format "Jan 2 15:04:05"}}: {{.Message}}</p>{{end}}
+{{range .Topics}}
+<table>
+<tr><th colspan="8">{{.Name}} ({{.Type}}): {{.Sent}} sent, {{printf "%.1f" .Rate}}/s, send latency avg {{.SendLatency.Avg}} max {{.SendLatency.Max}}</th></tr>
+<tr><th>subscriber</th><th>policy</th><th>queue</th><th>delivered</th><th>dropped</th><th>delivery avg</th><th>delivery max</th><th>blocking</th></tr>
+{{range .Subscribers}}
+<tr{{if .Blocking}} class="blocking"{{end}}><td>{{.Name}}</td><td>{{.Policy}}</td><td>{{.Pending}}/{{.Buffer}}</td><td>{{.Delivered}}</td><td>{{.Dropped}}</td><td>{{.Delivery.Avg}}</td><td>{{.Delivery.Max}}</td><td>{{.Blocking}}</td></tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+type DebugPage struct {
+	hub *hub.Hub
+}
+
+// NewDebugPage serves hub statistics on /debug/hub, and as JSON on
+// /debug/hub.json.
+func NewDebugPage(h *hub.Hub) *DebugPage {
+	d := &DebugPage{h}
+	http.HandleFunc("/debug/hub", d.page)
+	http.HandleFunc("/debug/hub.json", d.json)
+	return d
+}
+
+func (d *DebugPage) page(writer http.ResponseWriter, request *http.Request) {
+	data := struct {
+		Time   time.Time
+		Window int
+		Topics []bus.TopicStats
+		Alarms []hub.Alarm
+	}{time.Now(), bus.RateWindow, d.hub.Bus.Snapshot(), d.hub.Alarms()}
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := debugTemplate.Execute(writer, data); err != nil {
+		log.Printf("Can't write hub statistics to http: %v", err)
+	}
+}
+
+func (d *DebugPage) json(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(d.hub.Bus.Snapshot()); err != nil {
+		log.Printf("Can't write hub statistics to http: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/gravity"
+	"github.com/zlowred/alcobot/hub"
+)
+
+// defaultDriftDuration is how long the drift is learned for by default.
+const defaultDriftDuration = time.Hour
+
+type DriftService struct {
+	hub *hub.Hub
+
+	lock  sync.Mutex
+	state driftState
+}
+
+type driftState struct {
+	Running bool
+	Started time.Time
+	Until   time.Time
+	// Drift is the learned drift in Pa/ºC, Err why there is none.
+	Drift float64
+	Err   string
+}
+
+// NewDriftService learns the drift of the NPA offset with its die
+// temperature for the duration posted to /api/calibration/drift, an hour by
+// default, and stores it in the configuration. A get reports how far it
+// got.
+func NewDriftService(h *hub.Hub) *DriftService {
+	s := &DriftService{hub: h}
+	http.HandleFunc("/api/calibration/drift", s.drift)
+	return s
+}
+
+func (s *DriftService) drift(writer http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case http.MethodGet:
+		writer.Header().Set("Content-Type", "application/json")
+	case http.MethodPost:
+		duration := defaultDriftDuration
+		if d := request.FormValue("duration"); d != "" {
+			var err error
+			if duration, err = time.ParseDuration(d); err != nil || duration <= 0 {
+				http.Error(writer, fmt.Sprintf("invalid duration %q", d), http.StatusBadRequest)
+				return
+			}
+		}
+		if err := s.start(duration); err != nil {
+			http.Error(writer, err.Error(), http.StatusConflict)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusAccepted)
+	default:
+		writer.Header().Set("Allow", "GET, POST")
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.lock.Lock()
+	state := s.state
+	s.lock.Unlock()
+	if err := json.NewEncoder(writer).Encode(state); err != nil {
+		log.Printf("Can't write the drift calibration to http: %v\n", err)
+	}
+}
+
+func (s *DriftService) start(duration time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.state.Running {
+		return fmt.Errorf("already learning the drift until %v", s.state.Until.Format("15:04:05"))
+	}
+	conf, err := s.hub.Config()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	s.state = driftState{Running: true, Started: now, Until: now.Add(duration)}
+	go s.learn(conf, duration)
+	return nil
+}
+
+func (s *DriftService) learn(conf *config.Configuration, duration time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	drift, err := gravity.LearnDrift(ctx, s.hub, conf)
+	if err == nil {
+		err = s.hub.SetNpaDrift(drift)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.state.Running = false
+	if err != nil {
+		log.Printf("Can't learn the NPA drift: %v\n", err)
+		s.state.Err = err.Error()
+		return
+	}
+	s.state.Drift = drift
+}
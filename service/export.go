@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	exporter "github.com/zlowred/alcobot/export"
+	"github.com/zlowred/alcobot/hub"
+)
+
+var contentTypes = map[string]string{
+	exporter.CSV:  "text/csv; charset=utf-8",
+	exporter.JSON: "application/json",
+}
+
+type ExportService struct {
+	hub *hub.Hub
+}
+
+// NewExportService serves the data of a brew on /api/export. The optional
+// id parameter selects the brew, the current one by default, and format is
+// csv (the default) or json.
+func NewExportService(h *hub.Hub) *ExportService {
+	s := &ExportService{h}
+	http.HandleFunc("/api/export", s.export)
+	return s
+}
+
+func (s *ExportService) export(writer http.ResponseWriter, request *http.Request) {
+	format := request.FormValue("format")
+	if format == "" {
+		format = exporter.CSV
+	}
+	contentType, ok := contentTypes[format]
+	if !ok {
+		http.Error(writer, fmt.Sprintf("unknown format %q", format), http.StatusBadRequest)
+		return
+	}
+	var id int
+	if x := request.FormValue("id"); x != "" {
+		var err error
+		if id, err = strconv.Atoi(x); err != nil {
+			http.Error(writer, fmt.Sprintf("invalid brew id %q", x), http.StatusBadRequest)
+			return
+		}
+	}
+
+	b, err := exporter.Load(s.hub, id)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusNotFound)
+		return
+	}
+	writer.Header().Set("Content-Type", contentType)
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", b.FileName(format)))
+	if err := b.Write(writer, format); err != nil {
+		log.Printf("Can't write the export of brew %d to http: %v\n", b.Config.Id, err)
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/zlowred/alcobot/hub"
+)
+
+type OGService struct {
+	hub *hub.Hub
+}
+
+// NewOGService overrides the OG of the current brew, captured at pitch
+// time, with the og posted to /api/og; og=0 clears it.
+func NewOGService(h *hub.Hub) *OGService {
+	s := &OGService{h}
+	http.HandleFunc("/api/og", s.og)
+	return s
+}
+
+func (s *OGService) og(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writer.Header().Set("Allow", "POST")
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	og, err := strconv.ParseFloat(request.FormValue("og"), 64)
+	if err != nil {
+		http.Error(writer, fmt.Sprintf("invalid og %q", request.FormValue("og")), http.StatusBadRequest)
+		return
+	}
+	if err := s.hub.SetOG(og); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(struct{ OG float64 }{og}); err != nil {
+		log.Printf("Can't write the OG to http: %v\n", err)
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/zlowred/alcobot/beerxml"
+	"github.com/zlowred/alcobot/hub"
+)
+
+// maxRecipeSize bounds an uploaded BeerXML file.
+const maxRecipeSize = 1 << 20
+
+type RecipeService struct {
+	hub *hub.Hub
+}
+
+// NewRecipeService imports BeerXML recipes posted to /api/recipe, either
+// as the body or as the file field of a form. The optional name parameter
+// picks the recipe, the first one by default. The brew has to be in setup.
+func NewRecipeService(h *hub.Hub) *RecipeService {
+	s := &RecipeService{h}
+	http.HandleFunc("/api/recipe", s.recipe)
+	return s
+}
+
+func (s *RecipeService) recipe(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writer.Header().Set("Allow", "POST")
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	request.Body = http.MaxBytesReader(writer, request.Body, maxRecipeSize)
+
+	var body io.Reader = request.Body
+	name := request.URL.Query().Get("name")
+	if strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := request.FormFile("file")
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body, name = file, request.FormValue("name")
+	}
+	recipes, err := beerxml.Parse(body)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r, err := beerxml.Find(recipes, name)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err := r.Plan(s.hub); errors.Is(err, hub.ErrTransition) {
+		http.Error(writer, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(r); err != nil {
+		log.Printf("Can't write the imported recipe to http: %v", err)
+	}
+}
@@ -17,7 +17,6 @@ type Screenshoter struct {
 func NewScreenshoter(widget *ui.QWidget) *Screenshoter {
 	s := &Screenshoter{widget}
 	http.HandleFunc("/", s.takeScreenshot)
-	http.ListenAndServe(":8080", nil)
 	return s
 }
 
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Server serves the handlers registered by the other services.
+type Server struct {
+	server *http.Server
+}
+
+// RequestHeader has to be set on every request that changes the bot, e.g.
+// with curl -H "X-Alcobot: 1". A browser only sends it cross-origin after a
+// CORS preflight, which the server never grants, so another site can't
+// post to the API on behalf of a browser on the same network.
+const RequestHeader = "X-Alcobot"
+
+func NewServer(addr string) *Server {
+	return &Server{&http.Server{Addr: addr, Handler: guard(http.DefaultServeMux)}}
+}
+
+// guard rejects the requests that aren't safe to serve to any page without
+// RequestHeader.
+func guard(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodGet, http.MethodHead:
+		default:
+			if request.Header.Get(RequestHeader) == "" {
+				http.Error(writer, RequestHeader+" header required", http.StatusForbidden)
+				return
+			}
+		}
+		h.ServeHTTP(writer, request)
+	})
+}
+
+// Run listens until ctx is done and then gives open requests a second to
+// finish.
+func (s *Server) Run(ctx context.Context) {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		log.Printf("HTTP server stopped: %v", err)
+		<-ctx.Done()
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := s.server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("HTTP server shutdown: %v", err)
+		}
+	}
+}
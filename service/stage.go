@@ -0,0 +1,95 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/zlowred/alcobot/hub"
+)
+
+type stageEvent struct {
+	Time       time.Time
+	Transition hub.Transition
+	From       string
+	To         string
+}
+
+type stageState struct {
+	Id     int
+	Name   string
+	Stage  string
+	Events []stageEvent
+}
+
+type StageService struct {
+	hub *hub.Hub
+}
+
+// NewStageService serves the stage of the current brew on /api/stage.
+// A POST with transition=<prepare|pitch|finish|abort|reset> and confirm=yes
+// applies a transition.
+func NewStageService(h *hub.Hub) *StageService {
+	s := &StageService{h}
+	http.HandleFunc("/api/stage", s.stage)
+	return s
+}
+
+func (s *StageService) stage(writer http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if request.FormValue("confirm") != "yes" {
+			http.Error(writer, "transitions have to be confirmed with confirm=yes", http.StatusBadRequest)
+			return
+		}
+		t := hub.Transition(request.FormValue("transition"))
+		if err := s.hub.Transition(t); errors.Is(err, hub.ErrUnknownTransition) {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		} else if errors.Is(err, hub.ErrTransition) {
+			http.Error(writer, err.Error(), http.StatusConflict)
+			return
+		} else if err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	default:
+		writer.Header().Set("Allow", "GET, POST")
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state, err := s.state()
+	if err != nil {
+		log.Printf("Can't load the brew stage: %v\n", err)
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(state); err != nil {
+		log.Printf("Can't write the brew stage to http: %v", err)
+	}
+}
+
+func (s *StageService) state() (*stageState, error) {
+	sessions, err := s.hub.Sessions()
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, errors.New("no brew")
+	}
+	current := sessions[0]
+	events, err := s.hub.StageEvents(current.Id)
+	if err != nil {
+		return nil, err
+	}
+	state := &stageState{Id: current.Id, Name: current.Name, Stage: current.Stage.String(), Events: []stageEvent{}}
+	for _, e := range events {
+		state.Events = append(state.Events, stageEvent{e.Time, e.Transition, e.From.String(), e.To.String()})
+	}
+	return state, nil
+}
@@ -0,0 +1 @@
+select name from pragma_table_info('config') where name = 'AdsValueFilter'
\ No newline at end of file
@@ -0,0 +1,4 @@
+create table if not exists schema_version(
+    version             integer primary key,
+    applied             date not null
+)
\ No newline at end of file
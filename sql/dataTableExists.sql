@@ -1 +0,0 @@
-SELECT name FROM sqlite_master WHERE type='table' AND name='data'
@@ -0,0 +1 @@
+delete from calibration where id = ? and Time = ?
\ No newline at end of file
@@ -0,0 +1 @@
+delete from control_setpoints where id = ?
\ No newline at end of file
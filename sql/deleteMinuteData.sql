@@ -0,0 +1 @@
+delete from data_minute where id = ? and Bucket < ?
\ No newline at end of file
@@ -0,0 +1 @@
+delete from data where id = ? and Step <= ?
\ No newline at end of file
@@ -0,0 +1 @@
+delete from schedule where id = ?
\ No newline at end of file
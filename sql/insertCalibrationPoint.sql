@@ -0,0 +1 @@
+insert into calibration(id, Time, Pressure, Temperature, SG, Source) values (?, ?, ?, ?, ?, ?)
\ No newline at end of file
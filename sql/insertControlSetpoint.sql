@@ -0,0 +1 @@
+insert into control_setpoints(id, Scope, Step, Target, Overshoot, SettlingTime) values (?, ?, ?, ?, ?, ?)
\ No newline at end of file
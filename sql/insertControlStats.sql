@@ -0,0 +1 @@
+insert or replace into control_stats(id, Scope, Duration, RMSDeviation, MaxDeviation, InBand, Setpoints, Overshoot, SettlingTime, Settled, Reversals, AveragePower) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
\ No newline at end of file
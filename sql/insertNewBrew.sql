@@ -0,0 +1,99 @@
+insert into config (
+    FermenterSensor     ,
+    PresenceZero        ,
+    PresenceCalibration ,
+    PresenceOnTimer     ,
+    PresenceEnabled     ,
+    PresenceTimeout     ,
+    TemperatureScale    ,
+    TargetTemperature   ,
+    PidSlope            ,
+    NpaZero             ,
+    NpaCalibration      ,
+    Tec1Threshold       ,
+    Tec1Min             ,
+    Tec1Max             ,
+    Tec2Threshold       ,
+    Tec2Min             ,
+    Tec2Max             ,
+    Fan1Threshold       ,
+    Fan1Min             ,
+    Fan1Max             ,
+    Fan2Threshold       ,
+    Fan2Min             ,
+    Fan2Max             ,
+    Pump1Threshold      ,
+    Pump1Min            ,
+    Pump1Max            ,
+    Pump2Threshold      ,
+    Pump2Min            ,
+    Pump2Max            ,
+    NpaMinValue         ,
+    NpaMaxValue         ,
+    NpaMinPressure      ,
+    NpaMaxPressure      ,
+    Stage               ,
+    OG                  ,
+    BrewingStartTime    ,
+    PitchTime           ,
+    NpaTemperatureFilter,
+    NpaPressureFilter   ,
+    DsTemperatureFilter ,
+    AdsValueFilter      ,
+    Name                ,
+    NpaCalibrationTemperature,
+    NpaReferenceTemperature,
+    NpaDrift            ,
+    SgModel0            ,
+    SgModel1            ,
+    SgModel2
+) select
+    FermenterSensor     ,
+    PresenceZero        ,
+    PresenceCalibration ,
+    PresenceOnTimer     ,
+    PresenceEnabled     ,
+    PresenceTimeout     ,
+    TemperatureScale    ,
+    TargetTemperature   ,
+    PidSlope            ,
+    NpaZero             ,
+    NpaCalibration      ,
+    Tec1Threshold       ,
+    Tec1Min             ,
+    Tec1Max             ,
+    Tec2Threshold       ,
+    Tec2Min             ,
+    Tec2Max             ,
+    Fan1Threshold       ,
+    Fan1Min             ,
+    Fan1Max             ,
+    Fan2Threshold       ,
+    Fan2Min             ,
+    Fan2Max             ,
+    Pump1Threshold      ,
+    Pump1Min            ,
+    Pump1Max            ,
+    Pump2Threshold      ,
+    Pump2Min            ,
+    Pump2Max            ,
+    NpaMinValue         ,
+    NpaMaxValue         ,
+    NpaMinPressure      ,
+    NpaMaxPressure      ,
+    0                   ,
+    0                   ,
+    0                   ,
+    0                   ,
+    NpaTemperatureFilter,
+    NpaPressureFilter   ,
+    DsTemperatureFilter ,
+    AdsValueFilter      ,
+    ?                   ,
+    NpaCalibrationTemperature,
+    NpaReferenceTemperature,
+    NpaDrift            ,
+    SgModel0            ,
+    SgModel1            ,
+    SgModel2
+from config where id = (select max(id) from config)
\ No newline at end of file
@@ -0,0 +1 @@
+insert into schedule(id, Position, Name, Temperature, Duration) values (?, ?, ?, ?, ?)
\ No newline at end of file
@@ -0,0 +1 @@
+insert into schema_version(version, applied) values (?, ?)
\ No newline at end of file
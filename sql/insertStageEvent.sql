@@ -0,0 +1 @@
+insert into stage_events(id, Time, Event, FromStage, ToStage) values (?, ?, ?, ?, ?)
\ No newline at end of file
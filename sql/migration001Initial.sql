@@ -0,0 +1,130 @@
+create table if not exists config(
+    id 		            integer primary key autoincrement,
+    FermenterSensor     text not null,
+    PresenceZero        integer not null,
+    PresenceCalibration real not null,
+    PresenceOnTimer     integer not null,
+    PresenceEnabled     integer not null,
+    PresenceTimeout     integer not null,
+    TemperatureScale    integer not null,
+    TargetTemperature   real not null,
+    PidSlope            real not null,
+    NpaZero             integer not null,
+    NpaCalibration      real not null,
+    Tec1Threshold       integer not null,
+    Tec1Min             integer not null,
+    Tec1Max             integer not null,
+    Tec2Threshold       integer not null,
+    Tec2Min             integer not null,
+    Tec2Max             integer not null,
+    Fan1Threshold       integer not null,
+    Fan1Min             integer not null,
+    Fan1Max             integer not null,
+    Fan2Threshold       integer not null,
+    Fan2Min             integer not null,
+    Fan2Max             integer not null,
+    Pump1Threshold      integer not null,
+    Pump1Min            integer not null,
+    Pump1Max            integer not null,
+    Pump2Threshold      integer not null,
+    Pump2Min            integer not null,
+    Pump2Max            integer not null,
+    NpaMinValue         real not null,
+    NpaMaxValue         real not null,
+    NpaMinPressure      real not null,
+    NpaMaxPressure      real not null,
+    Stage               integer not null,
+    OG 		            real not null,
+    BrewingStartTime    date not null,
+    PitchTime	        date not null
+);
+
+create table if not exists data(
+	id              integer not null,
+	Step            integer not null,
+	TargetTemp      real,
+	CurrentTemp     real,
+	SG              real,
+	PID             real,
+	Power           real,
+
+	foreign key (id) references config(id)
+);
+
+insert into config (
+    FermenterSensor     ,
+    PresenceZero        ,
+    PresenceCalibration ,
+    PresenceOnTimer     ,
+    PresenceEnabled     ,
+    PresenceTimeout     ,
+    TemperatureScale    ,
+    TargetTemperature   ,
+    PidSlope            ,
+    NpaZero             ,
+    NpaCalibration      ,
+    Tec1Threshold       ,
+    Tec1Min             ,
+    Tec1Max             ,
+    Tec2Threshold       ,
+    Tec2Min             ,
+    Tec2Max             ,
+    Fan1Threshold       ,
+    Fan1Min             ,
+    Fan1Max             ,
+    Fan2Threshold       ,
+    Fan2Min             ,
+    Fan2Max             ,
+    Pump1Threshold      ,
+    Pump1Min            ,
+    Pump1Max            ,
+    Pump2Threshold      ,
+    Pump2Min            ,
+    Pump2Max            ,
+    NpaMinValue         ,
+    NpaMaxValue         ,
+    NpaMinPressure      ,
+    NpaMaxPressure      ,
+    Stage		        ,
+    OG		            ,
+    BrewingStartTime    ,
+    PitchTime
+) select
+    '',
+    4100,
+    1000,
+    4,
+    1,
+    15,
+    0,
+    21.1,
+    2.55,
+    0,
+    0,
+    0,
+    0,
+    255,
+    0,
+    0,
+    255,
+    3,
+    50,
+    235,
+    3,
+    50,
+    235,
+    1,
+    30,
+    200,
+    1,
+    30,
+    230,
+    1638,
+    14745,
+    -6894.76,
+    6894.76,
+    0,
+    0,
+    0,
+    0
+where not exists (select 1 from config)
\ No newline at end of file
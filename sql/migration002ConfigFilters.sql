@@ -0,0 +1,4 @@
+alter table config add column NpaTemperatureFilter text not null default 'trimmed:100,20';
+alter table config add column NpaPressureFilter text not null default 'trimmed:100,20';
+alter table config add column DsTemperatureFilter text not null default 'trimmed:30,10';
+alter table config add column AdsValueFilter text not null default 'trimmed:100,20'
\ No newline at end of file
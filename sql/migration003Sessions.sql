@@ -0,0 +1,2 @@
+alter table config add column Name text not null default '';
+create index if not exists dataSession on data(id, Step)
\ No newline at end of file
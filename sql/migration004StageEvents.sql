@@ -0,0 +1,9 @@
+create table if not exists stage_events(
+    id                  integer not null,
+    Time                date not null,
+    Event               text not null,
+    FromStage           integer not null,
+    ToStage             integer not null,
+
+    foreign key (id) references config(id)
+)
\ No newline at end of file
@@ -0,0 +1,46 @@
+create table if not exists data_minute(
+    id                  integer not null,
+    Bucket              integer not null,
+    Samples             integer not null,
+    TargetTempMin       real,
+    TargetTempAvg       real,
+    TargetTempMax       real,
+    CurrentTempMin      real,
+    CurrentTempAvg      real,
+    CurrentTempMax      real,
+    SGMin               real,
+    SGAvg               real,
+    SGMax               real,
+    PIDMin              real,
+    PIDAvg              real,
+    PIDMax              real,
+    PowerMin            real,
+    PowerAvg            real,
+    PowerMax            real,
+
+    primary key (id, Bucket),
+    foreign key (id) references config(id)
+);
+create table if not exists data_hour(
+    id                  integer not null,
+    Bucket              integer not null,
+    Samples             integer not null,
+    TargetTempMin       real,
+    TargetTempAvg       real,
+    TargetTempMax       real,
+    CurrentTempMin      real,
+    CurrentTempAvg      real,
+    CurrentTempMax      real,
+    SGMin               real,
+    SGAvg               real,
+    SGMax               real,
+    PIDMin              real,
+    PIDAvg              real,
+    PIDMax              real,
+    PowerMin            real,
+    PowerAvg            real,
+    PowerMax            real,
+
+    primary key (id, Bucket),
+    foreign key (id) references config(id)
+)
\ No newline at end of file
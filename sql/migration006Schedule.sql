@@ -0,0 +1,10 @@
+create table if not exists schedule(
+    id                  integer not null,
+    Position            integer not null,
+    Name                text not null,
+    Temperature         real not null,
+    Duration            integer not null,
+
+    primary key (id, Position),
+    foreign key (id) references config(id)
+)
\ No newline at end of file
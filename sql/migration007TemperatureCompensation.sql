@@ -0,0 +1,3 @@
+alter table config add column NpaCalibrationTemperature real not null default 0;
+alter table config add column NpaReferenceTemperature real not null default 0;
+alter table config add column NpaDrift real not null default 0
\ No newline at end of file
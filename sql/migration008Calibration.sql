@@ -0,0 +1,14 @@
+alter table config add column SgModel0 real not null default 0;
+alter table config add column SgModel1 real not null default 0;
+alter table config add column SgModel2 real not null default 0;
+create table if not exists calibration(
+    id                  integer not null,
+    Time                date not null,
+    Pressure            real not null,
+    Temperature         real,
+    SG                  real not null,
+    Source              text not null,
+
+    primary key (id, Time),
+    foreign key (id) references config(id)
+)
\ No newline at end of file
@@ -0,0 +1,17 @@
+create table if not exists control_stats(
+    id                  integer not null,
+    Scope               text not null,
+    Duration            integer not null,
+    RMSDeviation        real not null,
+    MaxDeviation        real not null,
+    InBand              integer not null,
+    Setpoints           integer not null,
+    Overshoot           real not null,
+    SettlingTime        integer not null,
+    Settled             integer not null,
+    Reversals           integer not null,
+    AveragePower        real not null,
+
+    primary key (id, Scope),
+    foreign key (id) references config(id)
+)
\ No newline at end of file
@@ -0,0 +1,11 @@
+create table if not exists control_setpoints(
+    id                  integer not null,
+    Scope               text not null,
+    Step                integer not null,
+    Target              real not null,
+    Overshoot           real not null,
+    SettlingTime        integer,
+
+    primary key (id, Scope, Step),
+    foreign key (id) references config(id)
+)
\ No newline at end of file
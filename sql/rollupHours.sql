@@ -0,0 +1,36 @@
+insert into data_hour(id, Bucket, Samples,
+    TargetTempMin, TargetTempAvg, TargetTempMax,
+    CurrentTempMin, CurrentTempAvg, CurrentTempMax,
+    SGMin, SGAvg, SGMax,
+    PIDMin, PIDAvg, PIDMax,
+    PowerMin, PowerAvg, PowerMax)
+select id, Bucket / 60, sum(Samples),
+    min(TargetTempMin), sum(TargetTempAvg * Samples) / sum(case when TargetTempAvg is not null then Samples end), max(TargetTempMax),
+    min(CurrentTempMin), sum(CurrentTempAvg * Samples) / sum(case when CurrentTempAvg is not null then Samples end), max(CurrentTempMax),
+    min(SGMin), sum(SGAvg * Samples) / sum(case when SGAvg is not null then Samples end), max(SGMax),
+    min(PIDMin), sum(PIDAvg * Samples) / sum(case when PIDAvg is not null then Samples end), max(PIDMax),
+    min(PowerMin), sum(PowerAvg * Samples) / sum(case when PowerAvg is not null then Samples end), max(PowerMax)
+from data_minute where id = ?1 and Bucket < ?2
+group by id, Bucket / 60
+on conflict(id, Bucket) do update set
+    Samples = Samples + excluded.Samples,
+    TargetTempMin = min(coalesce(TargetTempMin, excluded.TargetTempMin), coalesce(excluded.TargetTempMin, TargetTempMin)),
+    TargetTempAvg = (coalesce(TargetTempAvg * Samples, 0) + coalesce(excluded.TargetTempAvg * excluded.Samples, 0))
+        / (case when TargetTempAvg is not null then Samples else 0 end + case when excluded.TargetTempAvg is not null then excluded.Samples else 0 end),
+    TargetTempMax = max(coalesce(TargetTempMax, excluded.TargetTempMax), coalesce(excluded.TargetTempMax, TargetTempMax)),
+    CurrentTempMin = min(coalesce(CurrentTempMin, excluded.CurrentTempMin), coalesce(excluded.CurrentTempMin, CurrentTempMin)),
+    CurrentTempAvg = (coalesce(CurrentTempAvg * Samples, 0) + coalesce(excluded.CurrentTempAvg * excluded.Samples, 0))
+        / (case when CurrentTempAvg is not null then Samples else 0 end + case when excluded.CurrentTempAvg is not null then excluded.Samples else 0 end),
+    CurrentTempMax = max(coalesce(CurrentTempMax, excluded.CurrentTempMax), coalesce(excluded.CurrentTempMax, CurrentTempMax)),
+    SGMin = min(coalesce(SGMin, excluded.SGMin), coalesce(excluded.SGMin, SGMin)),
+    SGAvg = (coalesce(SGAvg * Samples, 0) + coalesce(excluded.SGAvg * excluded.Samples, 0))
+        / (case when SGAvg is not null then Samples else 0 end + case when excluded.SGAvg is not null then excluded.Samples else 0 end),
+    SGMax = max(coalesce(SGMax, excluded.SGMax), coalesce(excluded.SGMax, SGMax)),
+    PIDMin = min(coalesce(PIDMin, excluded.PIDMin), coalesce(excluded.PIDMin, PIDMin)),
+    PIDAvg = (coalesce(PIDAvg * Samples, 0) + coalesce(excluded.PIDAvg * excluded.Samples, 0))
+        / (case when PIDAvg is not null then Samples else 0 end + case when excluded.PIDAvg is not null then excluded.Samples else 0 end),
+    PIDMax = max(coalesce(PIDMax, excluded.PIDMax), coalesce(excluded.PIDMax, PIDMax)),
+    PowerMin = min(coalesce(PowerMin, excluded.PowerMin), coalesce(excluded.PowerMin, PowerMin)),
+    PowerAvg = (coalesce(PowerAvg * Samples, 0) + coalesce(excluded.PowerAvg * excluded.Samples, 0))
+        / (case when PowerAvg is not null then Samples else 0 end + case when excluded.PowerAvg is not null then excluded.Samples else 0 end),
+    PowerMax = max(coalesce(PowerMax, excluded.PowerMax), coalesce(excluded.PowerMax, PowerMax))
\ No newline at end of file
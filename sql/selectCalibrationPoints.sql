@@ -0,0 +1 @@
+select Time, Pressure, Temperature, SG, Source from calibration where id = ? order by Time
\ No newline at end of file
@@ -0,0 +1 @@
+select Scope, Step, Target, Overshoot, SettlingTime from control_setpoints where id = ? order by Scope, Step
\ No newline at end of file
@@ -0,0 +1 @@
+select Scope, Duration, RMSDeviation, MaxDeviation, InBand, Setpoints, Overshoot, SettlingTime, Settled, Reversals, AveragePower from control_stats where id = ? order by rowid
\ No newline at end of file
@@ -1 +0,0 @@
-select * from data where id = (select max(id) from config) order by step
\ No newline at end of file
@@ -0,0 +1 @@
+select rowid, SG, SG, SG, CurrentTemp from data where id = ? and SG is not null
\ No newline at end of file
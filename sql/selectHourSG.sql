@@ -0,0 +1 @@
+select Bucket, SGMin, SGAvg, SGMax, CurrentTempAvg from data_hour where id = ? and SGAvg is not null
\ No newline at end of file
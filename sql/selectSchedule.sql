@@ -0,0 +1 @@
+select Name, Temperature, Duration from schedule where id = ? order by Position
\ No newline at end of file
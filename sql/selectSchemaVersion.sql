@@ -0,0 +1 @@
+select coalesce(max(version), 0) from schema_version
\ No newline at end of file
@@ -0,0 +1,11 @@
+select id, Step, TargetTemp, CurrentTemp, SG, PID, Power from (
+    select id, (Bucket + 1) * 3600 as Step, TargetTempAvg as TargetTemp, CurrentTempAvg as CurrentTemp,
+        SGAvg as SG, PIDAvg as PID, PowerAvg as Power
+    from data_hour where id = ?1
+    union all
+    select id, (Bucket + 1) * 60, TargetTempAvg, CurrentTempAvg, SGAvg, PIDAvg, PowerAvg
+    from data_minute where id = ?1
+    union all
+    select id, Step, TargetTemp, CurrentTemp, SG, PID, Power
+    from data where id = ?1
+) order by Step
\ No newline at end of file
@@ -0,0 +1,18 @@
+with points(id, Step, SG) as (
+    select id, (Bucket + 1) * 3600, SGAvg from data_hour
+    union all
+    select id, (Bucket + 1) * 60, SGAvg from data_minute
+    union all
+    select id, Step, SG from data
+)
+select
+    c.id,
+    c.Name,
+    c.Stage,
+    c.BrewingStartTime,
+    c.PitchTime,
+    c.OG,
+    (select max(p.Step) from points p where p.id = c.id),
+    (select p.SG from points p where p.id = c.id and p.SG is not null order by p.Step limit 1),
+    (select p.SG from points p where p.id = c.id and p.SG is not null order by p.Step desc limit 1)
+from config c order by c.id desc
\ No newline at end of file
@@ -0,0 +1 @@
+select id, Time, Event, FromStage, ToStage from stage_events where id = ? order by Time
\ No newline at end of file
@@ -35,5 +35,16 @@ update config set
 	Stage 		        = ?,
 	OG                  = ?,
 	BrewingStartTime    = ?,
-	PitchTime           = ?
-	where id = (select max(id) from config)
\ No newline at end of file
+	PitchTime           = ?,
+	NpaTemperatureFilter = ?,
+	NpaPressureFilter   = ?,
+	DsTemperatureFilter = ?,
+	AdsValueFilter      = ?,
+	Name                = ?,
+	NpaCalibrationTemperature = ?,
+	NpaReferenceTemperature = ?,
+	NpaDrift            = ?,
+	SgModel0            = ?,
+	SgModel1            = ?,
+	SgModel2            = ?
+	where id = ?
\ No newline at end of file
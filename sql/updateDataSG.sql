@@ -0,0 +1 @@
+update data set SG = ?2 where rowid = ?4 and id = ?5
\ No newline at end of file
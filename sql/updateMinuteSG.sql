@@ -0,0 +1 @@
+update data_minute set SGMin = ?1, SGAvg = ?2, SGMax = ?3 where Bucket = ?4 and id = ?5
\ No newline at end of file
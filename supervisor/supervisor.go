@@ -0,0 +1,149 @@
+// Package supervisor runs the long-lived components of the bot and stops
+// them phase by phase when shutdown is requested.
+package supervisor
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Phase orders shutdown: components in lower phases are stopped, and waited
+// for, before any component in a higher phase is told to stop.
+type Phase int
+
+const (
+	// Control components stop deciding and leave the outputs safe.
+	Control Phase = iota
+	// Hardware components apply the last outputs and release the buses.
+	Hardware
+	// Recording components flush what they have not written yet.
+	Recording
+	// Interface components are the screen, backlight and HTTP services.
+	Interface
+	// Storage components close the bus and the database.
+	Storage
+)
+
+func (p Phase) String() string {
+	switch p {
+	case Control:
+		return "control"
+	case Hardware:
+		return "hardware"
+	case Recording:
+		return "recording"
+	case Interface:
+		return "interface"
+	case Storage:
+		return "storage"
+	}
+	return "unknown"
+}
+
+type component struct {
+	name   string
+	phase  Phase
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type Supervisor struct {
+	ctx  context.Context
+	stop context.CancelFunc
+
+	mutex      sync.Mutex
+	components []*component
+}
+
+// New returns a supervisor that starts shutting down when parent is done or
+// Stop is called.
+func New(parent context.Context) *Supervisor {
+	ctx, stop := context.WithCancel(parent)
+	return &Supervisor{ctx: ctx, stop: stop}
+}
+
+// Go runs a component in its own goroutine. Its context is cancelled when
+// the component's phase is reached during shutdown, not before, so it keeps
+// working while earlier phases wind down.
+func (s *Supervisor) Go(phase Phase, name string, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{name: name, phase: phase, cancel: cancel, done: make(chan struct{})}
+
+	s.mutex.Lock()
+	s.components = append(s.components, c)
+	s.mutex.Unlock()
+
+	go func() {
+		defer close(c.done)
+		run(ctx)
+	}()
+}
+
+// Stop requests shutdown. It does not wait; see Wait.
+func (s *Supervisor) Stop() {
+	s.stop()
+}
+
+// Stopping is closed once shutdown has been requested.
+func (s *Supervisor) Stopping() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Wait blocks until shutdown is requested, then stops the components phase
+// by phase, giving each phase timeout to finish. It returns the names of the
+// components that did not stop in time; later phases are stopped anyway.
+func (s *Supervisor) Wait(timeout time.Duration) []string {
+	<-s.ctx.Done()
+
+	s.mutex.Lock()
+	components := append([]*component(nil), s.components...)
+	s.mutex.Unlock()
+
+	sort.SliceStable(components, func(i, j int) bool {
+		return components[i].phase < components[j].phase
+	})
+
+	var stuck []string
+	for len(components) > 0 {
+		phase := components[0].phase
+		n := 0
+		for n < len(components) && components[n].phase == phase {
+			n++
+		}
+		stuck = append(stuck, stopPhase(phase, components[:n], timeout)...)
+		components = components[n:]
+	}
+	return stuck
+}
+
+func stopPhase(phase Phase, components []*component, timeout time.Duration) []string {
+	log.Printf("Stopping %v components\n", phase)
+	for _, c := range components {
+		c.cancel()
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	var stuck []string
+	for _, c := range components {
+		select {
+		case <-c.done:
+		case <-deadline.C:
+			// the deadline has passed, so only collect who else is late
+			for _, late := range components {
+				select {
+				case <-late.done:
+				default:
+					log.Printf("Component %v did not stop within %v\n", late.name, timeout)
+					stuck = append(stuck, late.name)
+				}
+			}
+			return stuck
+		}
+	}
+	return stuck
+}
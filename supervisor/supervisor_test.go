@@ -0,0 +1,78 @@
+package supervisor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopsPhasesInOrder(t *testing.T) {
+	s := New(context.Background())
+	var mutex sync.Mutex
+	var order []string
+	component := func(name string) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			<-ctx.Done()
+			mutex.Lock()
+			order = append(order, name)
+			mutex.Unlock()
+		}
+	}
+	s.Go(Storage, "hub", component("hub"))
+	s.Go(Hardware, "hal", component("hal"))
+	s.Go(Control, "pid", component("pid"))
+	s.Go(Recording, "recorder", component("recorder"))
+
+	s.Stop()
+	assert.Empty(t, s.Wait(time.Second))
+	assert.Equal(t, []string{"pid", "hal", "recorder", "hub"}, order)
+}
+
+func TestComponentsRunUntilTheirPhase(t *testing.T) {
+	s := New(context.Background())
+	hubStopped := make(chan struct{})
+	s.Go(Storage, "hub", func(ctx context.Context) {
+		<-ctx.Done()
+		close(hubStopped)
+	})
+	s.Go(Control, "heatpump", func(ctx context.Context) {
+		<-ctx.Done()
+		select {
+		case <-hubStopped:
+			t.Error("hub stopped before heatpump")
+		case <-time.After(time.Millisecond * 10):
+		}
+	})
+	s.Stop()
+	assert.Empty(t, s.Wait(time.Second))
+}
+
+func TestReportsStuckComponents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := New(ctx)
+	block := make(chan struct{})
+	defer close(block)
+	stopped := false
+	s.Go(Interface, "gui", func(ctx context.Context) {
+		<-block
+	})
+	s.Go(Interface, "backlight", func(ctx context.Context) {
+		<-ctx.Done()
+	})
+	s.Go(Storage, "hub", func(ctx context.Context) {
+		<-ctx.Done()
+		stopped = true
+	})
+
+	cancel()
+	select {
+	case <-s.Stopping():
+	case <-time.After(time.Second):
+		t.Fatal("parent context did not start shutdown")
+	}
+	assert.Equal(t, []string{"gui"}, s.Wait(time.Millisecond*20))
+	assert.True(t, stopped)
+}
@@ -0,0 +1,328 @@
+// Package uploader posts the state of the brew to a telemetry service such
+// as a Brewfather custom stream.
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/zlowred/alcobot/bus"
+	"github.com/zlowred/alcobot/config"
+	"github.com/zlowred/alcobot/conv"
+	"github.com/zlowred/alcobot/hub"
+)
+
+const (
+	// BrewfatherMinInterval is the shortest interval Brewfather accepts
+	// between custom stream posts.
+	BrewfatherMinInterval = time.Minute * 15
+	// DefaultInterval is the upload interval when none is configured.
+	DefaultInterval = time.Minute * 15
+	// maxPending bounds the readings kept while the service can't be
+	// reached; older ones are dropped first.
+	maxPending = 1000
+	// retryDelay is the delay before the first retry of a failed post,
+	// doubled for every next one up to the upload interval.
+	retryDelay = time.Second * 30
+	// postTimeout bounds a single post.
+	postTimeout = time.Second * 10
+)
+
+// Value is a reading that may be missing (NaN); it is stored as null.
+type Value float64
+
+func (v Value) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(v)) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(float64(v))
+}
+
+func (v *Value) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*v = Value(math.NaN())
+		return nil
+	}
+	return json.Unmarshal(b, (*float64)(v))
+}
+
+// Reading is the state of the brew at Time. Temperatures are in ºC; SG is
+// missing until the gravity estimate is ready.
+type Reading struct {
+	Name        string
+	Brew        string
+	Stage       string
+	Time        time.Time
+	SG          Value
+	Temperature Value
+	Target      float64
+}
+
+// Config configures an uploader. Without a Template readings are posted in
+// the Brewfather custom stream format; Brewfather logs them at arrival, so
+// only the latest unsent one is kept. A Template renders the body of each
+// post from a Reading instead, and every reading is kept until it is sent.
+type Config struct {
+	URL         string
+	Name        string
+	Template    *template.Template
+	ContentType string
+	Interval    time.Duration
+	MinInterval time.Duration
+	// Pending is the file the unsent readings are kept in over restarts.
+	Pending string
+}
+
+type Uploader struct {
+	hub    *hub.Hub
+	conf   Config
+	client *http.Client
+
+	brew    *config.Configuration
+	gravity hub.Gravity
+	temp    float64
+
+	pending  []Reading
+	lastPost time.Time
+	delay    time.Duration
+	failures int
+}
+
+// New creates an uploader, loading the readings left unsent by the last
+// run.
+func New(h *hub.Hub, conf Config) *Uploader {
+	if conf.Name == "" {
+		conf.Name = "alcobot"
+	}
+	if conf.ContentType == "" {
+		conf.ContentType = "application/json"
+	}
+	if conf.Interval == 0 {
+		conf.Interval = DefaultInterval
+	}
+	if conf.Interval < conf.MinInterval {
+		conf.Interval = conf.MinInterval
+	}
+	u := &Uploader{hub: h, conf: conf, client: &http.Client{Timeout: postTimeout}, temp: math.NaN()}
+	u.gravity.SG = math.NaN()
+	u.load()
+	return u
+}
+
+// Run takes a reading every Interval while the brew is in preparation or
+// fermentation and posts the pending readings, oldest first and no more
+// often than MinInterval, until ctx is done.
+func (u *Uploader) Run(ctx context.Context) {
+	configCh := u.hub.Configuration.JoinContext(ctx)
+	gravityCh := u.hub.Gravity.JoinContext(ctx, bus.Buffer(1), bus.Drop(bus.DropOldest), bus.Name("uploader"))
+	tempCh := u.hub.DsTemperatureFiltered.JoinContext(ctx, bus.Buffer(1), bus.Drop(bus.DropOldest), bus.Name("uploader"))
+
+	sample := time.NewTicker(u.conf.Interval)
+	defer sample.Stop()
+	retry := time.NewTimer(0)
+	defer retry.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case x := <-configCh:
+			u.brew = x
+		case x := <-gravityCh:
+			u.gravity = x
+		case x := <-tempCh:
+			u.temp = conv.DsToC(x.Value)
+		case now := <-sample.C:
+			if r, ok := u.reading(now); ok {
+				u.queue(r)
+				u.send(ctx, now)
+				u.rearm(retry)
+			}
+		case now := <-retry.C:
+			u.send(ctx, now)
+			u.rearm(retry)
+		}
+	}
+}
+
+// rearm sets the retry timer to the delay the last send asked for.
+func (u *Uploader) rearm(retry *time.Timer) {
+	if !retry.Stop() {
+		select {
+		case <-retry.C:
+		default:
+		}
+	}
+	if len(u.pending) > 0 && u.delay > 0 {
+		retry.Reset(u.delay)
+	}
+}
+
+func (u *Uploader) reading(now time.Time) (Reading, bool) {
+	if u.brew == nil || (u.brew.Stage != config.PREPARATION && u.brew.Stage != config.BREWING) {
+		return Reading{}, false
+	}
+	return Reading{Name: u.conf.Name, Brew: u.brew.Name, Stage: u.brew.Stage.String(), Time: now,
+		SG: Value(u.gravity.SG), Temperature: Value(u.temp), Target: u.brew.TargetTemperature}, true
+}
+
+func (u *Uploader) queue(r Reading) {
+	limit := maxPending
+	if u.conf.Template == nil {
+		limit = 1
+	}
+	u.pending = append(u.pending, r)
+	if len(u.pending) > limit {
+		u.pending = append([]Reading(nil), u.pending[len(u.pending)-limit:]...)
+	}
+	u.save()
+}
+
+// send posts the pending readings while the service accepts them and
+// MinInterval allows. On failure it sets the delay of the next retry.
+func (u *Uploader) send(ctx context.Context, now time.Time) {
+	u.delay = 0
+	for len(u.pending) > 0 {
+		if wait := u.conf.MinInterval - now.Sub(u.lastPost); !u.lastPost.IsZero() && wait > 0 {
+			u.delay = wait
+			return
+		}
+		if err := u.post(ctx, u.pending[0]); err != nil {
+			u.failures++
+			u.delay = u.backoff()
+			log.Printf("Can't upload reading to %v, %d pending, retrying in %v: %v\n", u.conf.URL, len(u.pending), u.delay, err)
+			return
+		}
+		u.pending = u.pending[1:]
+		u.lastPost, u.failures = now, 0
+		u.save()
+	}
+}
+
+func (u *Uploader) backoff() time.Duration {
+	delay := retryDelay
+	for i := 1; i < u.failures && delay < u.conf.Interval; i++ {
+		delay *= 2
+	}
+	if delay > u.conf.Interval {
+		delay = u.conf.Interval
+	}
+	return delay
+}
+
+func (u *Uploader) post(ctx context.Context, r Reading) error {
+	body, err := u.body(r)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, u.conf.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", u.conf.ContentType)
+	response, err := u.client.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("%v", response.Status)
+	}
+	return nil
+}
+
+func (u *Uploader) body(r Reading) ([]byte, error) {
+	if u.conf.Template != nil {
+		var b bytes.Buffer
+		err := u.conf.Template.Execute(&b, r)
+		return b.Bytes(), err
+	}
+	return json.Marshal(brewfather(r))
+}
+
+// brewfatherStream is the Brewfather custom stream format.
+type brewfatherStream struct {
+	Name        string   `json:"name"`
+	Temp        *float64 `json:"temp,omitempty"`
+	TempUnit    string   `json:"temp_unit"`
+	Gravity     *float64 `json:"gravity,omitempty"`
+	GravityUnit string   `json:"gravity_unit"`
+	Beer        string   `json:"beer,omitempty"`
+	Comment     string   `json:"comment"`
+}
+
+func brewfather(r Reading) brewfatherStream {
+	return brewfatherStream{Name: r.Name, Temp: value(r.Temperature), TempUnit: "C", Gravity: value(r.SG), GravityUnit: "G",
+		Beer: r.Brew, Comment: fmt.Sprintf("%v, target %.1fºC", r.Stage, r.Target)}
+}
+
+func value(x Value) *float64 {
+	if math.IsNaN(float64(x)) {
+		return nil
+	}
+	f := float64(x)
+	return &f
+}
+
+// Functions are the extra functions available to templates: json renders
+// a value as JSON, e.g. {{json .SG}} is null while SG is missing.
+var Functions = template.FuncMap{
+	"json": func(x interface{}) (string, error) {
+		b, err := json.Marshal(x)
+		return string(b), err
+	},
+}
+
+// ParseTemplate reads a body template from file.
+func ParseTemplate(file string) (*template.Template, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return template.New(file).Funcs(Functions).Parse(string(data))
+}
+
+func (u *Uploader) load() {
+	if u.conf.Pending == "" {
+		return
+	}
+	data, err := os.ReadFile(u.conf.Pending)
+	if os.IsNotExist(err) {
+		return
+	} else if err == nil {
+		err = json.Unmarshal(data, &u.pending)
+	}
+	if err != nil {
+		log.Printf("Can't load pending uploads from %v: %v\n", u.conf.Pending, err)
+		return
+	}
+	if len(u.pending) > 0 {
+		log.Printf("Loaded %d pending uploads\n", len(u.pending))
+	}
+}
+
+// save writes the pending readings next to the file and renames it over,
+// so a crash leaves the old or the new list.
+func (u *Uploader) save() {
+	if u.conf.Pending == "" {
+		return
+	}
+	data, err := json.Marshal(u.pending)
+	if err == nil {
+		tmp := u.conf.Pending + ".tmp"
+		if err = os.WriteFile(tmp, data, 0644); err == nil {
+			err = os.Rename(tmp, u.conf.Pending)
+		}
+	}
+	if err != nil {
+		log.Printf("Can't save pending uploads to %v: %v\n", u.conf.Pending, err)
+	}
+}
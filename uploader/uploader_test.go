@@ -0,0 +1,176 @@
+package uploader
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zlowred/alcobot/config"
+)
+
+// standIn is a local telemetry service recording the bodies it accepts.
+type standIn struct {
+	sync.Mutex
+	status int
+	bodies []string
+	types  []string
+}
+
+func newStandIn(t *testing.T) (*standIn, string) {
+	s := &standIn{status: http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		defer s.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		if s.status == http.StatusOK {
+			s.bodies = append(s.bodies, string(body))
+			s.types = append(s.types, r.Header.Get("Content-Type"))
+		}
+		w.WriteHeader(s.status)
+	}))
+	t.Cleanup(server.Close)
+	return s, server.URL
+}
+
+func newUploader(conf Config) *Uploader {
+	u := New(nil, conf)
+	u.brew = &config.Configuration{Name: "Pils", Stage: config.BREWING, TargetTemperature: 10}
+	u.gravity.SG = 1.0421
+	u.temp = 10.25
+	return u
+}
+
+var start = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func (u *Uploader) sample(now time.Time) {
+	r, _ := u.reading(now)
+	u.queue(r)
+	u.send(context.Background(), now)
+}
+
+func TestBrewfather(t *testing.T) {
+	s, url := newStandIn(t)
+	u := newUploader(Config{URL: url, Name: "fermenter", MinInterval: BrewfatherMinInterval})
+	u.sample(start)
+
+	assert.Equal(t, []string{"application/json"}, s.types)
+	var stream map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(s.bodies[0]), &stream))
+	assert.Equal(t, map[string]interface{}{"name": "fermenter", "temp": 10.25, "temp_unit": "C", "gravity": 1.0421,
+		"gravity_unit": "G", "beer": "Pils", "comment": "brewing, target 10.0ºC"}, stream)
+
+	u.gravity.SG = math.NaN()
+	u.sample(start.Add(BrewfatherMinInterval))
+	assert.NotContains(t, s.bodies[1], "gravity\"")
+}
+
+func TestNoReadingOutsideFermentation(t *testing.T) {
+	u := newUploader(Config{})
+	u.brew.Stage = config.SETUP
+	_, ok := u.reading(start)
+	assert.False(t, ok)
+	u.brew = nil
+	_, ok = u.reading(start)
+	assert.False(t, ok)
+}
+
+func TestTemplate(t *testing.T) {
+	s, url := newStandIn(t)
+	tmpl := template.Must(template.New("body").Funcs(Functions).Parse(`sg={{json .SG}}&t={{printf "%.1f" .Temperature}}&stage={{.Stage}}`))
+	u := newUploader(Config{URL: url, Template: tmpl, ContentType: "text/plain"})
+	u.sample(start)
+	u.gravity.SG = math.NaN()
+	u.sample(start.Add(time.Minute))
+
+	assert.Equal(t, []string{"sg=1.0421&t=10.2&stage=brewing", "sg=null&t=10.2&stage=brewing"}, s.bodies)
+	assert.Equal(t, []string{"text/plain", "text/plain"}, s.types)
+}
+
+func TestRetry(t *testing.T) {
+	s, url := newStandIn(t)
+	tmpl := template.Must(template.New("body").Parse(`{{.Time.Unix}}`))
+	u := newUploader(Config{URL: url, Template: tmpl, Interval: time.Hour})
+
+	s.status = http.StatusServiceUnavailable
+	u.sample(start)
+	assert.Equal(t, retryDelay, u.delay)
+	u.sample(start.Add(time.Minute))
+	assert.Equal(t, 2*retryDelay, u.delay)
+	assert.Len(t, u.pending, 2)
+	assert.Empty(t, s.bodies)
+
+	s.status = http.StatusOK
+	u.send(context.Background(), start.Add(2*time.Minute))
+	assert.Empty(t, u.pending)
+	assert.Equal(t, time.Duration(0), u.delay)
+	assert.Equal(t, []string{"1772366400", "1772366460"}, s.bodies)
+}
+
+func TestBackoffIsBoundedByInterval(t *testing.T) {
+	u := New(nil, Config{Interval: 3 * time.Minute})
+	for u.failures = 1; u.failures < 10; u.failures++ {
+		assert.LessOrEqual(t, u.backoff(), 3*time.Minute)
+	}
+	assert.Equal(t, 3*time.Minute, u.backoff())
+}
+
+func TestBrewfatherKeepsLatestReading(t *testing.T) {
+	s, url := newStandIn(t)
+	s.status = http.StatusBadGateway
+	u := newUploader(Config{URL: url})
+	u.sample(start)
+	u.temp = 11
+	u.sample(start.Add(time.Minute))
+	assert.Len(t, u.pending, 1)
+	assert.Equal(t, Value(11), u.pending[0].Temperature)
+}
+
+func TestMinInterval(t *testing.T) {
+	s, url := newStandIn(t)
+	tmpl := template.Must(template.New("body").Parse(`{{.Time.Unix}}`))
+	u := newUploader(Config{URL: url, Template: tmpl, MinInterval: 15 * time.Minute})
+	assert.Equal(t, 15*time.Minute, u.conf.Interval)
+
+	u.sample(start)
+	u.sample(start.Add(5 * time.Minute))
+	assert.Len(t, s.bodies, 1)
+	assert.Len(t, u.pending, 1)
+	assert.Equal(t, 10*time.Minute, u.delay)
+
+	u.send(context.Background(), start.Add(15*time.Minute))
+	assert.Len(t, s.bodies, 2)
+	assert.Empty(t, u.pending)
+}
+
+func TestPendingSurvivesRestart(t *testing.T) {
+	s, url := newStandIn(t)
+	file := filepath.Join(t.TempDir(), "uploads.json")
+	tmpl := template.Must(template.New("body").Funcs(Functions).Parse(`{{json .SG}}`))
+	conf := Config{URL: url, Template: tmpl, Pending: file}
+
+	s.status = http.StatusInternalServerError
+	u := newUploader(conf)
+	u.gravity.SG = math.NaN()
+	u.sample(start)
+	u.gravity.SG = 1.010
+	u.sample(start.Add(time.Minute))
+
+	u = New(nil, conf)
+	assert.Len(t, u.pending, 2)
+	assert.True(t, math.IsNaN(float64(u.pending[0].SG)))
+	assert.True(t, start.Equal(u.pending[0].Time))
+
+	s.status = http.StatusOK
+	u.send(context.Background(), start.Add(2*time.Minute))
+	assert.Equal(t, []string{"null", "1.01"}, s.bodies)
+	assert.Empty(t, New(nil, conf).pending)
+}